@@ -1,10 +1,11 @@
 module github.com/daggerpov/slack-recurring-messages-scheduler
 
-go 1.20
+go 1.21
 
 require (
 	github.com/slack-go/slack v0.12.3
 	github.com/spf13/cobra v1.8.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,107 @@
+// Package ci adapts the commands to running in GitHub Actions: prompts are
+// declined instead of waiting on stdin, failures become workflow annotations and
+// what was scheduled is summarised on the job's page
+package ci
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/logging"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+)
+
+// Detect reports whether the process runs in GitHub Actions, which sets
+// GITHUB_ACTIONS=true for every step
+func Detect() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// NoInput is what prompts read in CI mode: nothing, so they see end of input
+// straight away and never block
+func NoInput() io.Reader {
+	return strings.NewReader("")
+}
+
+// Confirm is a scheduler.Scheduler confirm prompt for CI mode. It declines,
+// explaining how to go ahead without a prompt.
+func Confirm(prompt string) bool {
+	slog.Error(fmt.Sprintf("%s (no prompts in CI mode; pass --yes to go ahead)", prompt))
+	return false
+}
+
+// titleEscaper escapes a workflow command property, which additionally can't
+// contain its separators
+var titleEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// WriteErrorAnnotation writes err as an ::error:: workflow command, titled with
+// its class (see scheduler.ErrorCode) so failures are told apart on the run page
+func WriteErrorAnnotation(w io.Writer, err error) {
+	if err == nil {
+		return
+	}
+	title := "slack-scheduler: " + scheduler.ErrorCode(err)
+	fmt.Fprintf(w, "::error title=%s::%s\n", titleEscaper.Replace(title), logging.EscapeWorkflowData(err.Error()))
+}
+
+// WriteSummary writes a report as a Markdown heading and table of occurrences,
+// the form GitHub renders on the job's summary page
+func WriteSummary(w io.Writer, title string, report scheduler.Report) {
+	scheduled, failed := 0, 0
+	for _, r := range report.Results {
+		if r.Status == "failed" {
+			failed++
+		} else {
+			scheduled++
+		}
+	}
+
+	fmt.Fprintf(w, "### %s: %s\n\n", title, report.Status)
+	if report.Error != "" {
+		fmt.Fprintf(w, "**Error (%s):** %s\n\n", report.ErrorCode, markdownCell(report.Error))
+	}
+	if len(report.Results) > 0 {
+		fmt.Fprintln(w, "| Series | Channel | When | Status | ID / error |")
+		fmt.Fprintln(w, "|--------|---------|------|--------|------------|")
+		for _, r := range report.Results {
+			detail := r.ScheduledID
+			status := "✅ scheduled"
+			if r.Status == "failed" {
+				detail = r.Error
+				status = "❌ failed"
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", markdownCell(r.Series), markdownCell(r.Channel),
+				r.PostAt.Format("2006-01-02 15:04 MST"), status, markdownCell(detail))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d scheduled, %d failed\n", scheduled, failed)
+}
+
+// AppendStepSummary adds a report's summary to the file GitHub Actions shows on
+// the job's page (GITHUB_STEP_SUMMARY). Outside Actions it does nothing.
+func AppendStepSummary(title string, report scheduler.Report) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	WriteSummary(f, title, report)
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return nil
+}
+
+// markdownCell keeps text on one table row: pipes are escaped and line breaks
+// become spaces
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,98 @@
+package ci
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+)
+
+func TestDetect(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	if !Detect() {
+		t.Error("Detect() = false with GITHUB_ACTIONS=true")
+	}
+	t.Setenv("GITHUB_ACTIONS", "")
+	if Detect() {
+		t.Error("Detect() = true outside Actions")
+	}
+}
+
+func TestNoInput(t *testing.T) {
+	n, err := NoInput().Read(make([]byte, 1))
+	if n != 0 || err != io.EOF {
+		t.Errorf("Read() = %d, %v, want end of input", n, err)
+	}
+	if Confirm("Schedule 80 messages to #general?") {
+		t.Error("Confirm() should decline in CI mode")
+	}
+}
+
+func TestWriteErrorAnnotation(t *testing.T) {
+	var buf bytes.Buffer
+	WriteErrorAnnotation(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("annotation for nil error: %q", buf.String())
+	}
+
+	WriteErrorAnnotation(&buf, scheduler.Invalid(errors.New("invalid date: 2025-13-01\nuse YYYY-MM-DD")))
+	want := "::error title=slack-scheduler%3A invalid::invalid date: 2025-13-01%0Ause YYYY-MM-DD\n"
+	if buf.String() != want {
+		t.Errorf("annotation = %q, want %q", buf.String(), want)
+	}
+}
+
+func testReport() scheduler.Report {
+	at := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	return scheduler.NewReport([]scheduler.OccurrenceResult{
+		{Series: "standup", Channel: "C0000001", PostAt: at, Status: "scheduled", ScheduledID: "Q1"},
+		{Series: "standup", Channel: "C0000001", PostAt: at.AddDate(0, 0, 1), Status: "failed", Error: "restricted | action"},
+	}, &scheduler.PartialError{Scheduled: 1, Failed: 1, Err: errors.New("restricted | action")})
+}
+
+func TestWriteSummary(t *testing.T) {
+	var buf bytes.Buffer
+	WriteSummary(&buf, "Slack scheduler", testReport())
+	got := buf.String()
+
+	for _, want := range []string{
+		"### Slack scheduler: partial\n",
+		"**Error (partial):** restricted \\| action\n",
+		"| standup | C0000001 | 2025-01-06 09:00 UTC | ✅ scheduled | Q1 |\n",
+		"| standup | C0000001 | 2025-01-07 09:00 UTC | ❌ failed | restricted \\| action |\n",
+		"1 scheduled, 1 failed\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestAppendStepSummary(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	if err := AppendStepSummary("Slack scheduler", testReport()); err != nil {
+		t.Errorf("AppendStepSummary() outside Actions error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(path, []byte("# Earlier step\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+	if err := AppendStepSummary("Slack scheduler", testReport()); err != nil {
+		t.Fatalf("AppendStepSummary() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# Earlier step\n### Slack scheduler: partial") {
+		t.Errorf("summary file = %q, want the report appended", data)
+	}
+}
@@ -0,0 +1,164 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/notify"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// DefaultInterval is how often the daemon wakes to extend managed series
+const DefaultInterval = 6 * time.Hour
+
+// Daemon keeps managed series in the local store scheduled indefinitely, topping
+// each one up as new occurrences enter Slack's 120-day scheduling window
+type Daemon struct {
+	client    *slack.Client
+	storePath string
+	interval  time.Duration
+
+	// Clock handed to every scheduler (the system clock if nil)
+	clock scheduler.Clock
+
+	// Told when a series is extended or fails to be (nil for none)
+	notifier notify.Notifier
+}
+
+// New creates a daemon for the store at storePath, waking every interval
+// (DefaultInterval if interval <= 0)
+func New(client *slack.Client, storePath string, interval time.Duration) *Daemon {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Daemon{
+		client:    client,
+		storePath: storePath,
+		interval:  interval,
+	}
+}
+
+// SetClock replaces the wall clock the daemon schedules against, e.g. with a
+// scheduler.FakeClock to simulate several days of passes in a test
+func (d *Daemon) SetClock(clock scheduler.Clock) {
+	d.clock = clock
+}
+
+// SetNotifier reports each series the daemon extends or fails to extend
+func (d *Daemon) SetNotifier(n notify.Notifier) {
+	d.notifier = n
+}
+
+// RunOnce extends every managed series and saves the store, returning the number
+// of messages scheduled. A failing series is reported and skipped so one bad series
+// doesn't stall the rest.
+func (d *Daemon) RunOnce() (int, error) {
+	// Reopen each pass to pick up series created since the last one
+	st, err := store.Open(d.storePath)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, series := range st.List() {
+		if !series.Managed || series.Paused {
+			continue
+		}
+		n, err := d.extendSeries(series.Name)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// extendSeries extends one series with the store locked, re-reading it first so
+// changes made since the pass started (a pause, a skip) aren't overwritten. The
+// series is saved whenever extending changed it, even if it then failed, since
+// Slack may already hold the messages. Failures to extend are reported rather
+// than returned; the error is for reading or writing the store.
+func (d *Daemon) extendSeries(name string) (int, error) {
+	unlock, err := store.Lock(d.storePath)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	st, err := store.Open(d.storePath)
+	if err != nil {
+		return 0, err
+	}
+	series, ok := st.Get(name)
+	if !ok || !series.Managed || series.Paused {
+		return 0, nil
+	}
+	before, _ := json.Marshal(series)
+
+	s := scheduler.New(d.client, &series.Config)
+	if d.clock != nil {
+		s.SetClock(d.clock)
+	}
+	n, err := s.Extend(&series, 0)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Warning: failed to extend series %s: %v", series.Name, err), "series", series.Name, "error", err)
+	}
+	d.notify(series, n, err)
+
+	if after, _ := json.Marshal(series); bytes.Equal(before, after) {
+		return n, nil
+	}
+	if err := st.Put(series); err != nil {
+		return n, err
+	}
+	return n, st.Save()
+}
+
+// notify reports one series' extension, if anything happened
+func (d *Daemon) notify(series types.Series, n int, err error) {
+	if d.notifier == nil || (err == nil && n == 0) {
+		return
+	}
+	event := notify.Event{
+		Type:    notify.EventExtended,
+		Series:  series.Name,
+		Channel: series.Config.Channel,
+		Count:   n,
+	}
+	if d.clock != nil {
+		event.Time = d.clock.Now()
+	}
+	if err != nil {
+		event.Type = notify.EventExtendFailed
+		event.Error = err.Error()
+	}
+	d.notifier.Notify(event)
+}
+
+// Run calls RunOnce immediately and then every interval until ctx is cancelled
+func (d *Daemon) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := d.RunOnce()
+		if err != nil {
+			slog.Warn(fmt.Sprintf("Warning: daemon pass failed: %v", err), "error", err)
+		} else {
+			slog.Info(fmt.Sprintf("Daemon pass complete: %d message(s) scheduled", n), "scheduled", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
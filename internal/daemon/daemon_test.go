@@ -0,0 +1,206 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/notify"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+func TestNew_DefaultInterval(t *testing.T) {
+	if d := New(nil, "", 0); d.interval != DefaultInterval {
+		t.Errorf("interval = %v, want %v", d.interval, DefaultInterval)
+	}
+}
+
+func TestDaemon_RunOnce(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/chat.scheduleMessage" {
+			atomic.AddInt32(&calls, 1)
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q123","post_at":"1736931600"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), store.FileName)
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	weekly := types.ScheduleConfig{Message: "Sprint review", Channel: "C123", StartDate: start, SendTime: "09:00", Interval: types.IntervalWeekly}
+	st.Put(types.Series{Name: "managed", Config: weekly, Managed: true})
+	st.Put(types.Series{Name: "unmanaged", Config: weekly})
+	if err := st.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := New(slack.NewClientWithAPIURL("fake-token", server.URL+"/"), path, time.Hour)
+
+	n, err := d.RunOnce()
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	// 120 days hold 17 or 18 weekly occurrences depending on the weekday
+	if n < 17 || n > 18 || int(calls) != n {
+		t.Fatalf("RunOnce() scheduled %d messages (%d API calls), want 17-18", n, calls)
+	}
+
+	reopened, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if managed, _ := reopened.Get("managed"); len(managed.Occurrences) != n {
+		t.Errorf("managed series has %d occurrences, want %d", len(managed.Occurrences), n)
+	}
+	if unmanaged, _ := reopened.Get("unmanaged"); len(unmanaged.Occurrences) != 0 {
+		t.Errorf("unmanaged series has %d occurrences, want 0", len(unmanaged.Occurrences))
+	}
+
+	// Nothing new has entered the window on an immediate second pass
+	if n, err := d.RunOnce(); err != nil || n != 0 {
+		t.Errorf("second RunOnce() = %d, %v, want 0", n, err)
+	}
+}
+
+func TestDaemon_RunOnce_SimulatedDays(t *testing.T) {
+	api := slack.NewFakeAPI()
+	channel := api.AddChannel("reviews")
+
+	path := filepath.Join(t.TempDir(), store.FileName)
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weekly := types.ScheduleConfig{Message: "Sprint review", Channel: channel, StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalWeekly}
+	st.Put(types.Series{Name: "managed", Config: weekly, Managed: true})
+	if err := st.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := scheduler.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, scheduler.LocalTZ))
+	d := New(slack.NewFakeClient(api), path, time.Hour)
+	d.SetClock(clock)
+
+	first, err := d.RunOnce()
+	if err != nil || first == 0 {
+		t.Fatalf("RunOnce() = %d, %v", first, err)
+	}
+
+	// A week later exactly one more Monday has entered the window
+	clock.Advance(7 * 24 * time.Hour)
+	if n, err := d.RunOnce(); err != nil || n != 1 {
+		t.Errorf("RunOnce() a week later = %d, %v, want 1", n, err)
+	}
+	if len(api.Scheduled) != first+1 {
+		t.Errorf("%d messages scheduled in Slack, want %d", len(api.Scheduled), first+1)
+	}
+}
+
+// recordingNotifier keeps the events it is told about
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (r *recordingNotifier) Notify(event notify.Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestDaemon_RunOnce_Notifies(t *testing.T) {
+	api := slack.NewFakeAPI()
+	channel := api.AddChannel("reviews")
+
+	path := filepath.Join(t.TempDir(), store.FileName)
+	st, _ := store.Open(path)
+	weekly := types.ScheduleConfig{Message: "Sprint review", Channel: channel, StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalWeekly}
+	st.Put(types.Series{Name: "reviews", Config: weekly, Managed: true})
+	if err := st.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := scheduler.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, scheduler.LocalTZ))
+	d := New(slack.NewFakeClient(api), path, time.Hour)
+	d.SetClock(clock)
+	var notifier recordingNotifier
+	d.SetNotifier(&notifier)
+
+	n, _ := d.RunOnce()
+	if len(notifier.events) != 1 || notifier.events[0].Type != notify.EventExtended || notifier.events[0].Series != "reviews" || notifier.events[0].Count != n {
+		t.Errorf("events = %+v, want one extended event for %d messages", notifier.events, n)
+	}
+
+	// Nothing new to schedule is not worth a notification
+	d.RunOnce()
+	if len(notifier.events) != 1 {
+		t.Errorf("events after an idle pass = %+v, want no more", notifier.events)
+	}
+
+	clock.Advance(7 * 24 * time.Hour)
+	api.Errors = map[string]error{"chat.scheduleMessage": fmt.Errorf("channel_not_found")}
+	d.RunOnce()
+	if len(notifier.events) != 2 || notifier.events[1].Type != notify.EventExtendFailed || notifier.events[1].Error == "" {
+		t.Errorf("events after a failed pass = %+v, want an extend_failed event", notifier.events)
+	}
+}
+
+func TestDaemon_RunOnce_SavesPartialExtend(t *testing.T) {
+	api := slack.NewFakeAPI()
+	channel := api.AddChannel("reviews")
+
+	path := filepath.Join(t.TempDir(), store.FileName)
+	st, _ := store.Open(path)
+	// Long enough to go out as two messages
+	long := strings.Repeat("Sprint review notes. ", 300)
+	weekly := types.ScheduleConfig{Message: long, Split: true, Channel: channel, StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalWeekly}
+	st.Put(types.Series{Name: "reviews", Config: weekly, Managed: true})
+	if err := st.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Slack takes the first part of the first occurrence and then refuses, so no
+	// occurrence completes but one message is already scheduled
+	d := New(slack.NewClientWithAPI(&failAfter{FakeAPI: api, limit: 1}), path, time.Hour)
+	d.SetClock(scheduler.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, scheduler.LocalTZ)))
+
+	if n, err := d.RunOnce(); err != nil || n != 0 {
+		t.Fatalf("RunOnce() = %d, %v, want 0 and no error", n, err)
+	}
+
+	// The message Slack holds is recorded, so it can be listed and deleted
+	reopened, _ := store.Open(path)
+	series, _ := reopened.Get("reviews")
+	if len(series.Occurrences) != 1 || series.Occurrences[0].ScheduledID != api.Scheduled[0].ID {
+		t.Errorf("saved occurrences = %+v, want the one message in Slack", series.Occurrences)
+	}
+}
+
+// failAfter fails every schedule call after the first limit
+type failAfter struct {
+	*slack.FakeAPI
+	limit, calls int
+}
+
+func (f *failAfter) ScheduleMessageIDContext(ctx context.Context, channelID, postAt string, options ...goslack.MsgOption) (string, string, error) {
+	f.calls++
+	if f.calls > f.limit {
+		return "", "", goslack.SlackErrorResponse{Err: "is_archived"}
+	}
+	return f.FakeAPI.ScheduleMessageIDContext(ctx, channelID, postAt, options...)
+}
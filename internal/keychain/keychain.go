@@ -0,0 +1,186 @@
+// Package keychain stores secrets in the operating system's credential store:
+// the macOS Keychain, the Windows Credential Manager or, elsewhere, the Secret
+// Service (GNOME Keyring, KWallet) through libsecret's secret-tool
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned for a secret the keychain doesn't have
+	ErrNotFound = errors.New("not found in the keychain")
+
+	// ErrUnavailable is returned when there is no keychain to use, e.g. on a
+	// server without a Secret Service or the tools to reach it
+	ErrUnavailable = errors.New("no system keychain available")
+)
+
+// Keychain stores secrets by service and account
+type Keychain interface {
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// System returns the keychain of the operating system the program runs on
+func System() Keychain {
+	return platformKeychain()
+}
+
+// runner runs a command with stdin and returns its standard output
+type runner func(stdin, name string, args ...string) ([]byte, error)
+
+func runCommand(stdin, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = &commandError{err: err, stderr: strings.TrimSpace(stderr.String())}
+	}
+	return out, err
+}
+
+// commandError keeps what a failed tool printed, which says more than its exit status
+type commandError struct {
+	err    error
+	stderr string
+}
+
+func (e *commandError) Error() string { return fmt.Sprintf("%v: %s", e.err, e.stderr) }
+
+func (e *commandError) Unwrap() error { return e.err }
+
+// exitCode returns the exit status of a command that ran and failed, or -1
+func exitCode(err error) int {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode()
+	}
+	return -1
+}
+
+// toolKeychain is a keychain reached through a command-line tool
+type toolKeychain struct {
+	tool string
+	run  runner
+
+	// Arguments (and standard input) for each operation
+	getArgs    func(service, account string) []string
+	setArgs    func(service, account, secret string) ([]string, string)
+	deleteArgs func(service, account string) []string
+
+	// Reports whether a failed get or delete means there was no such secret
+	notFound func(exitCode int, output []byte) bool
+
+	// Set reads the secret back, for tools that don't report a failed store in
+	// their exit status
+	readBack bool
+}
+
+func (k *toolKeychain) Get(service, account string) (string, error) {
+	out, err := k.exec("", k.getArgs(service, account))
+	if err != nil && k.notFound(exitCode(err), out) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimSuffix(string(out), "\n")
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k *toolKeychain) Set(service, account, secret string) error {
+	args, stdin := k.setArgs(service, account, secret)
+	if _, err := k.exec(stdin, args); err != nil || !k.readBack {
+		return err
+	}
+	stored, err := k.Get(service, account)
+	if err == nil && stored != secret {
+		err = fmt.Errorf("%s did not store the secret", k.tool)
+	}
+	return err
+}
+
+func (k *toolKeychain) Delete(service, account string) error {
+	out, err := k.exec("", k.deleteArgs(service, account))
+	if err != nil && k.notFound(exitCode(err), out) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// exec runs the tool, reporting a missing tool as ErrUnavailable
+func (k *toolKeychain) exec(stdin string, args []string) ([]byte, error) {
+	out, err := k.run(stdin, k.tool, args...)
+	if errors.Is(err, exec.ErrNotFound) {
+		return out, fmt.Errorf("%w (%s not installed)", ErrUnavailable, k.tool)
+	}
+	if err != nil {
+		return out, fmt.Errorf("%s failed: %w", k.tool, err)
+	}
+	return out, nil
+}
+
+// macOSKeychain uses the security tool that ships with macOS. The secret is
+// stored through its interactive mode, which reads the command from standard
+// input, since arguments show up in the process list.
+func macOSKeychain(run runner) *toolKeychain {
+	return &toolKeychain{
+		tool: "security",
+		run:  run,
+		getArgs: func(service, account string) []string {
+			return []string{"find-generic-password", "-s", service, "-a", account, "-w"}
+		},
+		setArgs: func(service, account, secret string) ([]string, string) {
+			// -U updates an existing item instead of failing
+			command := []string{"add-generic-password", "-U", "-s", service, "-a", account, "-w", secret}
+			for i, arg := range command {
+				command[i] = securityQuote(arg)
+			}
+			return []string{"-i"}, strings.Join(command, " ") + "\n"
+		},
+		deleteArgs: func(service, account string) []string {
+			return []string{"delete-generic-password", "-s", service, "-a", account}
+		},
+		// errSecItemNotFound
+		notFound: func(code int, _ []byte) bool { return code == 44 },
+		// Interactive mode exits 0 even if a command in it failed
+		readBack: true,
+	}
+}
+
+// securityQuote quotes an argument for a command line read by security -i
+func securityQuote(arg string) string {
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(arg)
+	return `"` + arg + `"`
+}
+
+// secretServiceKeychain uses libsecret's secret-tool, which reads the secret
+// from standard input so it never appears in the process list
+func secretServiceKeychain(run runner) *toolKeychain {
+	return &toolKeychain{
+		tool: "secret-tool",
+		run:  run,
+		getArgs: func(service, account string) []string {
+			return []string{"lookup", "service", service, "account", account}
+		},
+		setArgs: func(service, account, secret string) ([]string, string) {
+			return []string{"store", "--label", service + " (" + account + ")", "service", service, "account", account}, secret
+		},
+		deleteArgs: func(service, account string) []string {
+			return []string{"clear", "service", service, "account", account}
+		},
+		// lookup fails silently when there is no match
+		notFound: func(code int, output []byte) bool { return code == 1 && len(output) == 0 },
+	}
+}
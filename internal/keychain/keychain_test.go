@@ -0,0 +1,99 @@
+package keychain
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// fakeTool records invocations and plays a tool's responses
+type fakeTool struct {
+	calls  [][]string
+	stdins []string
+	out    []byte
+	err    error
+}
+
+func (f *fakeTool) run(stdin, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, append([]string{name}, args...))
+	f.stdins = append(f.stdins, stdin)
+	return f.out, f.err
+}
+
+// exitError runs a command that exits with code, for the *exec.ExitError it returns
+func exitError(t *testing.T, code string) error {
+	err := exec.Command("sh", "-c", "exit "+code).Run()
+	if err == nil {
+		t.Skip("no shell to produce an exit status")
+	}
+	return err
+}
+
+func TestSecretServiceKeychain(t *testing.T) {
+	tool := &fakeTool{out: []byte("xoxp-secret\n")}
+	k := secretServiceKeychain(tool.run)
+
+	if got, err := k.Get("svc", "/home/me/creds.json"); err != nil || got != "xoxp-secret" {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+	if err := k.Set("svc", "/home/me/creds.json", "xoxp-new"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	want := []string{"secret-tool", "store", "--label", "svc (/home/me/creds.json)", "service", "svc", "account", "/home/me/creds.json"}
+	if !reflect.DeepEqual(tool.calls[1], want) {
+		t.Errorf("store call = %v, want %v", tool.calls[1], want)
+	}
+	if tool.stdins[1] != "xoxp-new" {
+		t.Errorf("secret passed as %q, want it on stdin", tool.stdins[1])
+	}
+
+	tool.out, tool.err = nil, exitError(t, "1")
+	if _, err := k.Get("svc", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMacOSKeychain(t *testing.T) {
+	tool := &fakeTool{out: []byte("xoxp-new\n")}
+	k := macOSKeychain(tool.run)
+	if err := k.Set("svc", `my "acct"`, "xoxp-new"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	// The secret goes through standard input, never the process list
+	if want := []string{"security", "-i"}; !reflect.DeepEqual(tool.calls[0], want) {
+		t.Errorf("add call = %v, want %v", tool.calls[0], want)
+	}
+	if want := `"add-generic-password" "-U" "-s" "svc" "-a" "my \"acct\"" "-w" "xoxp-new"` + "\n"; tool.stdins[0] != want {
+		t.Errorf("add command = %q, want %q", tool.stdins[0], want)
+	}
+	// ...and is read back, since security -i doesn't fail when the command does
+	if want := []string{"security", "find-generic-password", "-s", "svc", "-a", `my "acct"`, "-w"}; len(tool.calls) != 2 || !reflect.DeepEqual(tool.calls[1], want) {
+		t.Errorf("calls = %v, want the secret read back", tool.calls)
+	}
+	tool.out = []byte("xoxp-old\n")
+	if err := k.Set("svc", "acct", "xoxp-new"); err == nil {
+		t.Error("Set() should fail when the secret wasn't stored")
+	}
+
+	tool.err = exitError(t, "44")
+	if _, err := k.Get("svc", "acct"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if err := k.Delete("svc", "acct"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() error = %v, want ErrNotFound", err)
+	}
+
+	tool.err = exitError(t, "51")
+	if _, err := k.Get("svc", "acct"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want a failure other than not found", err)
+	}
+}
+
+func TestToolKeychain_MissingTool(t *testing.T) {
+	tool := &fakeTool{err: &exec.Error{Name: "secret-tool", Err: exec.ErrNotFound}}
+	k := secretServiceKeychain(tool.run)
+	if err := k.Set("svc", "acct", "xoxp"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Set() error = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package keychain
+
+import "runtime"
+
+func platformKeychain() Keychain {
+	if runtime.GOOS == "darwin" {
+		return macOSKeychain(runCommand)
+	}
+	return secretServiceKeychain(runCommand)
+}
@@ -0,0 +1,139 @@
+// Package logging configures the structured logger (log/slog) the other packages
+// write their progress and warnings to
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Format is how log records are written
+type Format string
+
+const (
+	FormatHuman Format = "human" // just the message, as the CLI has always printed it
+	FormatText  Format = "text"  // logfmt key=value lines
+	FormatJSON  Format = "json"  // one JSON object per line
+
+	// Like human, but warnings and errors become GitHub Actions annotations
+	FormatGitHub Format = "github"
+)
+
+// ValidFormats for validation
+var ValidFormats = []Format{FormatHuman, FormatText, FormatJSON, FormatGitHub}
+
+// Options configure the logger
+type Options struct {
+	// Least severe level written (info if zero)
+	Level slog.Level
+
+	Format Format
+
+	// Only write warnings and errors, whatever Level says
+	Quiet bool
+}
+
+// ParseLevel parses a --log-level value: debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level: %s (use: debug, info, warn, error)", s)
+	}
+	return level, nil
+}
+
+// ParseFormat parses a --log-format value, defaulting to human
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatHuman, nil
+	}
+	for _, f := range ValidFormats {
+		if Format(strings.ToLower(s)) == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid log format: %s (use: human, text, json, github)", s)
+}
+
+// New returns a logger writing to w
+func New(w io.Writer, opts Options) *slog.Logger {
+	level := opts.Level
+	if opts.Quiet && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch opts.Format {
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, handlerOpts))
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, handlerOpts))
+	case FormatGitHub:
+		return slog.New(&humanHandler{w: w, level: level, mu: &sync.Mutex{}, github: true})
+	default:
+		return slog.New(&humanHandler{w: w, level: level, mu: &sync.Mutex{}})
+	}
+}
+
+// Setup makes a logger writing to w the default for the whole process
+func Setup(w io.Writer, opts Options) {
+	slog.SetDefault(New(w, opts))
+}
+
+// humanHandler writes only each record's message, marking warnings and errors,
+// so interactive output reads like plain prints while automation can still switch
+// to text or JSON and get the attributes
+type humanHandler struct {
+	w     io.Writer
+	level slog.Level
+	mu    *sync.Mutex
+
+	// Write warnings and errors as GitHub Actions workflow commands
+	github bool
+}
+
+func (h *humanHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *humanHandler) Handle(_ context.Context, r slog.Record) error {
+	if h.github && r.Level >= slog.LevelWarn {
+		command := "warning"
+		if r.Level >= slog.LevelError {
+			command = "error"
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		_, err := fmt.Fprintf(h.w, "::%s::%s\n", command, EscapeWorkflowData(r.Message))
+		return err
+	}
+
+	prefix := ""
+	switch {
+	case r.Level >= slog.LevelError:
+		prefix = "❌ "
+	case r.Level >= slog.LevelWarn:
+		prefix = "⚠️  "
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintf(h.w, "%s%s\n", prefix, r.Message)
+	return err
+}
+
+// Attributes and groups only matter to the structured formats
+func (h *humanHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *humanHandler) WithGroup(string) slog.Handler      { return h }
+
+// workflowEscaper escapes the characters a workflow command's message can't
+// contain as is
+var workflowEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// EscapeWorkflowData escapes a message for a GitHub Actions workflow command
+// such as ::error::, keeping multi-line messages in one annotation
+func EscapeWorkflowData(s string) string {
+	return workflowEscaper.Replace(s)
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew_Human(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, Options{})
+	log.Debug("hidden")
+	log.Info("Scheduling message for: 2025-01-06 09:00 UTC", "post_at", "2025-01-06T09:00:00Z")
+	log.Warn("Warning: not a member of #general", "channel", "C123")
+
+	want := "Scheduling message for: 2025-01-06 09:00 UTC\n⚠️  Warning: not a member of #general\n"
+	if buf.String() != want {
+		t.Errorf("human output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNew_Quiet(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, Options{Level: slog.LevelDebug, Quiet: true})
+	log.Info("progress")
+	log.Error("failed")
+	if buf.String() != "❌ failed\n" {
+		t.Errorf("quiet output = %q, want only the error", buf.String())
+	}
+}
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, Options{Format: FormatJSON, Level: slog.LevelDebug}).Debug("Scheduled message", "channel", "C123", "attempts", 2)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("JSON output %q: %v", buf.String(), err)
+	}
+	if record["level"] != "DEBUG" || record["msg"] != "Scheduled message" || record["channel"] != "C123" || record["attempts"] != float64(2) {
+		t.Errorf("JSON record = %v", record)
+	}
+
+	buf.Reset()
+	New(&buf, Options{Format: FormatText}).Info("done", "scheduled", 3)
+	if !strings.Contains(buf.String(), `level=INFO msg=done scheduled=3`) {
+		t.Errorf("text output = %q", buf.String())
+	}
+}
+
+func TestNew_GitHub(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, Options{Format: FormatGitHub})
+	log.Info("Scheduling message for: 2025-01-06 09:00 UTC")
+	log.Warn("No scheduled messages found!\n  Check the 100% obvious")
+	log.Error("failed to schedule message: not_in_channel")
+
+	want := "Scheduling message for: 2025-01-06 09:00 UTC\n" +
+		"::warning::No scheduled messages found!%0A  Check the 100%25 obvious\n" +
+		"::error::failed to schedule message: not_in_channel\n"
+	if buf.String() != want {
+		t.Errorf("github output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseLevelAndFormat(t *testing.T) {
+	if level, err := ParseLevel("warn"); err != nil || level != slog.LevelWarn {
+		t.Errorf("ParseLevel(warn) = %v, %v", level, err)
+	}
+	if level, err := ParseLevel("DEBUG"); err != nil || level != slog.LevelDebug {
+		t.Errorf("ParseLevel(DEBUG) = %v, %v", level, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("ParseLevel(loud) expected an error")
+	}
+
+	if format, err := ParseFormat(""); err != nil || format != FormatHuman {
+		t.Errorf("ParseFormat(\"\") = %v, %v, want human", format, err)
+	}
+	if format, err := ParseFormat("JSON"); err != nil || format != FormatJSON {
+		t.Errorf("ParseFormat(JSON) = %v, %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) expected an error")
+	}
+}
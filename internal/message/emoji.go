@@ -0,0 +1,254 @@
+package message
+
+import (
+	"sort"
+	"strings"
+)
+
+// StandardEmoji maps common Slack emoji shortcodes (without colons) to their unicode form.
+// It is used for terminal previews and to spot misspelled shortcodes, and is not an
+// exhaustive list of Slack's emoji.
+var StandardEmoji = map[string]string{
+	"+1":                         "👍",
+	"-1":                         "👎",
+	"thumbsup":                   "👍",
+	"thumbsdown":                 "👎",
+	"wave":                       "👋",
+	"clap":                       "👏",
+	"raised_hands":               "🙌",
+	"pray":                       "🙏",
+	"muscle":                     "💪",
+	"point_right":                "👉",
+	"point_left":                 "👈",
+	"point_up":                   "☝️",
+	"point_down":                 "👇",
+	"ok_hand":                    "👌",
+	"eyes":                       "👀",
+	"smile":                      "😄",
+	"smiley":                     "😃",
+	"grinning":                   "😀",
+	"laughing":                   "😆",
+	"joy":                        "😂",
+	"wink":                       "😉",
+	"blush":                      "😊",
+	"slightly_smiling_face":      "🙂",
+	"upside_down_face":           "🙃",
+	"thinking_face":              "🤔",
+	"sweat_smile":                "😅",
+	"sunglasses":                 "😎",
+	"heart_eyes":                 "😍",
+	"cry":                        "😢",
+	"sob":                        "😭",
+	"scream":                     "😱",
+	"sleeping":                   "😴",
+	"partying_face":              "🥳",
+	"heart":                      "❤️",
+	"blue_heart":                 "💙",
+	"green_heart":                "💚",
+	"yellow_heart":               "💛",
+	"purple_heart":               "💜",
+	"broken_heart":               "💔",
+	"fire":                       "🔥",
+	"star":                       "⭐",
+	"sparkles":                   "✨",
+	"zap":                        "⚡",
+	"boom":                       "💥",
+	"tada":                       "🎉",
+	"confetti_ball":              "🎊",
+	"balloon":                    "🎈",
+	"gift":                       "🎁",
+	"trophy":                     "🏆",
+	"medal":                      "🏅",
+	"rocket":                     "🚀",
+	"coffee":                     "☕",
+	"tea":                        "🍵",
+	"beer":                       "🍺",
+	"beers":                      "🍻",
+	"pizza":                      "🍕",
+	"cake":                       "🍰",
+	"birthday":                   "🎂",
+	"doughnut":                   "🍩",
+	"taco":                       "🌮",
+	"sunny":                      "☀️",
+	"cloud":                      "☁️",
+	"umbrella":                   "☔",
+	"snowflake":                  "❄️",
+	"rainbow":                    "🌈",
+	"earth_americas":             "🌎",
+	"calendar":                   "📆",
+	"date":                       "📅",
+	"spiral_calendar_pad":        "🗓️",
+	"alarm_clock":                "⏰",
+	"clock9":                     "🕘",
+	"hourglass":                  "⌛",
+	"stopwatch":                  "⏱️",
+	"bell":                       "🔔",
+	"mega":                       "📣",
+	"loudspeaker":                "📢",
+	"memo":                       "📝",
+	"pencil":                     "📝",
+	"pencil2":                    "✏️",
+	"clipboard":                  "📋",
+	"pushpin":                    "📌",
+	"round_pushpin":              "📍",
+	"paperclip":                  "📎",
+	"link":                       "🔗",
+	"bookmark":                   "🔖",
+	"books":                      "📚",
+	"book":                       "📖",
+	"email":                      "📧",
+	"envelope":                   "✉️",
+	"inbox_tray":                 "📥",
+	"outbox_tray":                "📤",
+	"package":                    "📦",
+	"chart_with_upwards_trend":   "📈",
+	"chart_with_downwards_trend": "📉",
+	"bar_chart":                  "📊",
+	"computer":                   "💻",
+	"keyboard":                   "⌨️",
+	"iphone":                     "📱",
+	"phone":                      "☎️",
+	"bulb":                       "💡",
+	"wrench":                     "🔧",
+	"hammer":                     "🔨",
+	"gear":                       "⚙️",
+	"lock":                       "🔒",
+	"unlock":                     "🔓",
+	"key":                        "🔑",
+	"mag":                        "🔍",
+	"bug":                        "🐛",
+	"dog":                        "🐶",
+	"cat":                        "🐱",
+	"unicorn_face":               "🦄",
+	"white_check_mark":           "✅",
+	"heavy_check_mark":           "✔️",
+	"ballot_box_with_check":      "☑️",
+	"x":                          "❌",
+	"heavy_multiplication_x":     "✖️",
+	"warning":                    "⚠️",
+	"no_entry":                   "⛔",
+	"no_entry_sign":              "🚫",
+	"rotating_light":             "🚨",
+	"exclamation":                "❗",
+	"question":                   "❓",
+	"grey_question":              "❔",
+	"information_source":         "ℹ️",
+	"red_circle":                 "🔴",
+	"large_blue_circle":          "🔵",
+	"large_green_circle":         "🟢",
+	"white_circle":               "⚪",
+	"black_circle":               "⚫",
+	"arrow_right":                "➡️",
+	"arrow_left":                 "⬅️",
+	"arrow_up":                   "⬆️",
+	"arrow_down":                 "⬇️",
+	"repeat":                     "🔁",
+	"hourglass_flowing_sand":     "⏳",
+	"100":                        "💯",
+	"new":                        "🆕",
+	"sos":                        "🆘",
+	"speech_balloon":             "💬",
+	"thought_balloon":            "💭",
+	"handshake":                  "🤝",
+	"brain":                      "🧠",
+	"seedling":                   "🌱",
+	"palm_tree":                  "🌴",
+	"sun_with_face":              "🌞",
+	"crescent_moon":              "🌙",
+	"ghost":                      "👻",
+	"robot_face":                 "🤖",
+	"shipit":                     "🐿️",
+	"squirrel":                   "🐿️",
+}
+
+// EmojiTypo is a :shortcode: that isn't a known emoji but is close to one
+type EmojiTypo struct {
+	Name       string
+	Suggestion string
+}
+
+// MisspelledEmoji returns the :shortcodes: in text that are not emoji but are
+// one or two edits away from a standard or workspace custom emoji, which Slack
+// would show as literal text. Since StandardEmoji is not exhaustive, other
+// unknown names are assumed to be emoji it doesn't list and aren't reported.
+// Shortcodes inside code spans are ignored.
+func MisspelledEmoji(text string, custom map[string]string) []EmojiTypo {
+	seen := make(map[string]bool)
+	var typos []EmojiTypo
+
+	for _, segment := range codeSpanPattern.Split(text, -1) {
+		for _, m := range emojiPattern.FindAllStringSubmatch(segment, -1) {
+			name := m[1]
+			// Purely numeric matches are almost always times like 10:30:00
+			if seen[name] || isKnownEmoji(name, custom) || strings.Trim(name, "0123456789") == "" {
+				continue
+			}
+			seen[name] = true
+			if suggestion := closestEmoji(name, custom); suggestion != "" {
+				typos = append(typos, EmojiTypo{Name: name, Suggestion: suggestion})
+			}
+		}
+	}
+
+	sort.Slice(typos, func(i, j int) bool { return typos[i].Name < typos[j].Name })
+	return typos
+}
+
+func isKnownEmoji(name string, custom map[string]string) bool {
+	if _, ok := StandardEmoji[name]; ok {
+		return true
+	}
+	if _, ok := custom[name]; ok {
+		return true
+	}
+	// Skin tone modifiers, e.g. :wave::skin-tone-3:
+	return strings.HasPrefix(name, "skin-tone-")
+}
+
+// closestEmoji returns the known emoji name nearest to name, if one is within a
+// single edit (two for names of eight characters or more), or ""
+func closestEmoji(name string, custom map[string]string) string {
+	limit := 1
+	if len(name) >= 8 {
+		limit = 2
+	}
+	best, bestDist := "", limit+1
+	consider := func(known string) {
+		if d := editDistance(name, known); d < bestDist || (d == bestDist && known < best) {
+			best, bestDist = known, d
+		}
+	}
+	for known := range StandardEmoji {
+		consider(known)
+	}
+	for known := range custom {
+		consider(known)
+	}
+	return best
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent characters that turn a into b
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,36 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMisspelledEmoji(t *testing.T) {
+	custom := map[string]string{"shipit-parrot": "https://emoji.slack-edge.com/parrot.gif"}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []EmojiTypo
+	}{
+		{"standard emoji", "ship it :rocket: :tada:", nil},
+		{"custom emoji", "ship it :shipit-parrot:", nil},
+		{"typo in custom emoji", "ship it :shipit-parot:", []EmojiTypo{{"shipit-parot", "shipit-parrot"}}},
+		{"swapped letters", ":rokcet:", []EmojiTypo{{"rokcet", "rocket"}}},
+		{"duplicates reported once", ":tadaa: and :tadaa:", []EmojiTypo{{"tadaa", "tada"}}},
+		{"sorted output", ":tadaa: :rockt:", []EmojiTypo{{"rockt", "rocket"}, {"tadaa", "tada"}}},
+		{"unlisted emoji not reported", ":flag-ca: :man-biking: :nope:", nil},
+		{"skin tone modifier", ":wave::skin-tone-3:", nil},
+		{"ignored in code", "`:rockt:`", nil},
+		{"times are not emoji", "meet at 10:30:00", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MisspelledEmoji(tt.input, custom)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MisspelledEmoji(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package message
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var markdownLinkPattern = regexp.MustCompile(`\[([^\[\]\n]+)\]\((https?://[^\s()]+|mailto:[^\s()]+)\)`)
+
+// ConvertMarkdownLinks rewrites markdown [Title](https://url) links into Slack's
+// <https://url|Title> syntax, leaving code spans untouched
+func ConvertMarkdownLinks(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range codeSpanPattern.FindAllStringIndex(text, -1) {
+		out.WriteString(markdownLinkPattern.ReplaceAllString(text[last:loc[0]], "<$2|$1>"))
+		out.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	out.WriteString(markdownLinkPattern.ReplaceAllString(text[last:], "<$2|$1>"))
+	return out.String()
+}
+
+// EscapeControl escapes &, < and > so Slack shows them literally instead of
+// reading <...> as a link, mention or broadcast
+func EscapeControl(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// FormatLink turns a "Title|https://url" spec (or a bare URL) into Slack link syntax
+func FormatLink(spec string) (string, error) {
+	title, rawURL, hasTitle := strings.Cut(spec, "|")
+	if !hasTitle {
+		rawURL, title = spec, ""
+	}
+	title = strings.TrimSpace(title)
+	rawURL = strings.TrimSpace(rawURL)
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Scheme != "mailto") {
+		return "", fmt.Errorf("invalid link URL: %q (use \"Title|https://example.com\")", rawURL)
+	}
+
+	if title == "" {
+		return "<" + rawURL + ">", nil
+	}
+	// Slack uses | as the separator and <> as delimiters, so they can't appear in titles
+	title = strings.NewReplacer("|", "-", "<", "&lt;", ">", "&gt;").Replace(title)
+	return "<" + rawURL + "|" + title + ">", nil
+}
+
+// AppendLinks appends each link spec to the message on its own line
+func AppendLinks(text string, specs []string) (string, error) {
+	for _, spec := range specs {
+		link, err := FormatLink(spec)
+		if err != nil {
+			return "", err
+		}
+		text += "\n" + link
+	}
+	return text, nil
+}
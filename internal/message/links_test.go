@@ -0,0 +1,77 @@
+package message
+
+import (
+	"testing"
+)
+
+func TestConvertMarkdownLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single link", "see [the docs](https://example.com/docs)", "see <https://example.com/docs|the docs>"},
+		{"multiple links", "[a](https://a.com) and [b](http://b.com)", "<https://a.com|a> and <http://b.com|b>"},
+		{"mailto", "[email us](mailto:team@example.com)", "<mailto:team@example.com|email us>"},
+		{"non-url target untouched", "[x](not a url)", "[x](not a url)"},
+		{"code span untouched", "`[a](https://a.com)`", "`[a](https://a.com)`"},
+		{"no links", "plain text", "plain text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertMarkdownLinks(tt.input); got != tt.want {
+				t.Errorf("ConvertMarkdownLinks(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{"title and url", "Docs|https://example.com", "<https://example.com|Docs>", false},
+		{"bare url", "https://example.com", "<https://example.com>", false},
+		{"spaces trimmed", " Docs | https://example.com ", "<https://example.com|Docs>", false},
+		{"angle brackets escaped", "a<b>|https://example.com", "<https://example.com|a&lt;b&gt;>", false},
+		{"missing scheme", "Docs|example.com", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatLink(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatLink(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatLink(%q) = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendLinks(t *testing.T) {
+	got, err := AppendLinks("Release notes:", []string{"v1.2|https://example.com/v1.2"})
+	if err != nil {
+		t.Fatalf("AppendLinks() error = %v", err)
+	}
+	if want := "Release notes:\n<https://example.com/v1.2|v1.2>"; got != want {
+		t.Errorf("AppendLinks() = %q, want %q", got, want)
+	}
+
+	if _, err := AppendLinks("x", []string{"bad"}); err == nil {
+		t.Error("AppendLinks() expected error for invalid link")
+	}
+}
+
+func TestEscapeControl(t *testing.T) {
+	got := EscapeControl("if a < b && c > d { *x* <!here> }")
+	if want := "if a &lt; b &amp;&amp; c &gt; d { *x* &lt;!here&gt; }"; got != want {
+		t.Errorf("EscapeControl() = %q, want %q", got, want)
+	}
+}
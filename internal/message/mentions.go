@@ -0,0 +1,93 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+)
+
+// plainMentionPattern matches @name and @name@example.com written as plain text.
+// The character before the @ rules out emails (bob@example.com) and mentions
+// already in Slack syntax (<@U123>).
+var plainMentionPattern = regexp.MustCompile(`(^|[^\w<@!|/.])@([A-Za-z0-9][\w.\-]*(?:@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)+)?)`)
+
+// broadcastPattern matches broadcasts in Slack syntax, e.g. <!here> or <!channel|channel>
+var broadcastPattern = regexp.MustCompile(`<!(here|channel|everyone)(\|[^<>]*)?>`)
+
+// broadcasts are the special mentions that notify a whole channel
+var broadcasts = map[string]string{
+	"here":     "<!here>",
+	"channel":  "<!channel>",
+	"everyone": "<!everyone>",
+}
+
+// ResolveMentions rewrites plain-text @name and @name@example.com mentions into
+// the Slack syntax returned by lookup (e.g. "<@U123>"), since Slack only notifies
+// people for the latter. @here, @channel and @everyone become broadcasts. Code
+// spans are left untouched, and the first lookup error is returned.
+func ResolveMentions(text string, lookup func(name string) (string, error)) (string, error) {
+	var out strings.Builder
+	last := 0
+	for _, loc := range codeSpanPattern.FindAllStringIndex(text, -1) {
+		resolved, err := resolveSegment(text[last:loc[0]], lookup)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(resolved)
+		out.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	resolved, err := resolveSegment(text[last:], lookup)
+	if err != nil {
+		return "", err
+	}
+	out.WriteString(resolved)
+	return out.String(), nil
+}
+
+func resolveSegment(segment string, lookup func(name string) (string, error)) (string, error) {
+	var out strings.Builder
+	last := 0
+	for _, m := range plainMentionPattern.FindAllStringSubmatchIndex(segment, -1) {
+		// Sentence punctuation isn't part of the name, e.g. "thanks @alice."
+		name := strings.TrimRight(segment[m[4]:m[5]], ".-")
+		end := m[4] + len(name)
+
+		mention, ok := broadcasts[strings.ToLower(name)]
+		if !ok {
+			var err error
+			if mention, err = lookup(name); err != nil {
+				return "", err
+			}
+		}
+
+		out.WriteString(segment[last : m[4]-1])
+		out.WriteString(mention)
+		last = end
+	}
+	out.WriteString(segment[last:])
+	return out.String(), nil
+}
+
+// EscapeBroadcasts turns <!here>, <!channel> and <!everyone> into plain text that
+// reads the same but notifies nobody, returning the escaped broadcast names.
+// Code spans are left untouched.
+func EscapeBroadcasts(text string) (string, []string) {
+	var escaped []string
+	escape := func(segment string) string {
+		return broadcastPattern.ReplaceAllStringFunc(segment, func(m string) string {
+			name := broadcastPattern.FindStringSubmatch(m)[1]
+			escaped = append(escaped, name)
+			return "@" + name
+		})
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, loc := range codeSpanPattern.FindAllStringIndex(text, -1) {
+		out.WriteString(escape(text[last:loc[0]]))
+		out.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	out.WriteString(escape(text[last:]))
+	return out.String(), escaped
+}
@@ -0,0 +1,72 @@
+package message
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestResolveMentions(t *testing.T) {
+	users := map[string]string{"alice": "U1", "bob@example.com": "U2", "carol.smith": "U3"}
+	lookup := func(name string) (string, error) {
+		id, ok := users[name]
+		if !ok {
+			return "", fmt.Errorf("user not found: @%s", name)
+		}
+		return "<@" + id + ">", nil
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "username", input: "@alice please review", want: "<@U1> please review"},
+		{name: "email", input: "cc @bob@example.com", want: "cc <@U2>"},
+		{name: "dotted name with punctuation", input: "thanks @carol.smith.", want: "thanks <@U3>."},
+		{name: "several", input: "@alice,@carol.smith", want: "<@U1>,<@U3>"},
+		{name: "broadcast", input: "@here standup!", want: "<!here> standup!"},
+		{name: "plain email untouched", input: "mail bob@example.com", want: "mail bob@example.com"},
+		{name: "existing mention untouched", input: "hi <@U9>", want: "hi <@U9>"},
+		{name: "code untouched", input: "run `git blame @alice`", want: "run `git blame @alice`"},
+		{name: "unknown user", input: "@dave", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveMentions(tt.input, lookup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveMentions(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveMentions(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeBroadcasts(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        string
+		wantEscaped []string
+	}{
+		{name: "here", input: "<!here> standup", want: "@here standup", wantEscaped: []string{"here"}},
+		{name: "labeled", input: "hey <!channel|channel> and <!everyone>", want: "hey @channel and @everyone", wantEscaped: []string{"channel", "everyone"}},
+		{name: "user groups kept", input: "<!subteam^S1> ship it", want: "<!subteam^S1> ship it"},
+		{name: "code untouched", input: "use `<!here>` to ping", want: "use `<!here>` to ping"},
+		{name: "plain text", input: "nothing to see", want: "nothing to see"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, escaped := EscapeBroadcasts(tt.input)
+			if got != tt.want {
+				t.Errorf("EscapeBroadcasts(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if !reflect.DeepEqual(escaped, tt.wantEscaped) {
+				t.Errorf("EscapeBroadcasts(%q) escaped %v, want %v", tt.input, escaped, tt.wantEscaped)
+			}
+		})
+	}
+}
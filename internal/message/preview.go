@@ -0,0 +1,155 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ANSI escape sequences used for terminal rendering
+const (
+	ansiBold      = "\033[1m"
+	ansiBoldOff   = "\033[22m"
+	ansiItalic    = "\033[3m"
+	ansiItalicOff = "\033[23m"
+	ansiStrike    = "\033[9m"
+	ansiStrikeOff = "\033[29m"
+	ansiUnder     = "\033[4m"
+	ansiUnderOff  = "\033[24m"
+	ansiCode      = "\033[7m"
+	ansiCodeOff   = "\033[27m"
+	ansiDim       = "\033[2m"
+	ansiDimOff    = "\033[22m"
+)
+
+// PreviewOptions controls how a message is rendered for the terminal
+type PreviewOptions struct {
+	// User ID to display name map for resolving <@U123> mentions
+	Users map[string]string
+
+	// Channel ID to name map for resolving <#C123> references
+	Channels map[string]string
+
+	// User group ID to handle map for resolving <!subteam^S123> mentions
+	Groups map[string]string
+
+	// Color enables ANSI styling; without it only mentions, links and emoji are resolved
+	Color bool
+}
+
+var (
+	codeSpanPattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]+`")
+	specialPattern  = regexp.MustCompile(`<([^<>\s][^<>]*)>`)
+	boldPattern     = regexp.MustCompile(`(^|[\s(])\*([^*\n]+)\*`)
+	italicPattern   = regexp.MustCompile(`(^|[\s(])_([^_\n]+)_`)
+	strikePattern   = regexp.MustCompile(`(^|[\s(])~([^~\n]+)~`)
+	emojiPattern    = regexp.MustCompile(`:([a-z0-9_+\-']+):`)
+)
+
+// RenderPreview approximates how Slack will display a mrkdwn message in a terminal
+func RenderPreview(text string, opts PreviewOptions) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range codeSpanPattern.FindAllStringIndex(text, -1) {
+		out.WriteString(renderInline(text[last:loc[0]], opts))
+		out.WriteString(renderCode(text[loc[0]:loc[1]], opts))
+		last = loc[1]
+	}
+	out.WriteString(renderInline(text[last:], opts))
+
+	return renderQuotes(out.String(), opts)
+}
+
+func renderCode(span string, opts PreviewOptions) string {
+	if !opts.Color {
+		return span
+	}
+	if strings.HasPrefix(span, "```") {
+		return ansiDim + strings.Trim(span, "`") + ansiDimOff
+	}
+	return ansiCode + strings.Trim(span, "`") + ansiCodeOff
+}
+
+func renderInline(text string, opts PreviewOptions) string {
+	text = specialPattern.ReplaceAllStringFunc(text, func(m string) string {
+		return renderSpecial(m[1:len(m)-1], opts)
+	})
+
+	text = emojiPattern.ReplaceAllStringFunc(text, func(m string) string {
+		if e, ok := StandardEmoji[m[1:len(m)-1]]; ok {
+			return e
+		}
+		return m
+	})
+
+	if opts.Color {
+		text = boldPattern.ReplaceAllString(text, "$1"+ansiBold+"$2"+ansiBoldOff)
+		text = italicPattern.ReplaceAllString(text, "$1"+ansiItalic+"$2"+ansiItalicOff)
+		text = strikePattern.ReplaceAllString(text, "$1"+ansiStrike+"$2"+ansiStrikeOff)
+	}
+
+	return text
+}
+
+// renderSpecial resolves the inside of a <...> sequence: mentions, channels, broadcasts and links
+func renderSpecial(inner string, opts PreviewOptions) string {
+	target, label, hasLabel := strings.Cut(inner, "|")
+
+	switch {
+	case strings.HasPrefix(target, "@"):
+		if hasLabel {
+			return "@" + strings.TrimPrefix(label, "@")
+		}
+		if name, ok := opts.Users[target[1:]]; ok {
+			return "@" + name
+		}
+		return target
+
+	case strings.HasPrefix(target, "#"):
+		if hasLabel {
+			return "#" + label
+		}
+		if name, ok := opts.Channels[target[1:]]; ok {
+			return "#" + name
+		}
+		return target
+
+	case strings.HasPrefix(target, "!"):
+		if hasLabel {
+			return label
+		}
+		if handle, ok := opts.Groups[strings.TrimPrefix(target, "!subteam^")]; ok {
+			return "@" + handle
+		}
+		// <!here>, <!channel>, <!everyone>, <!subteam^ID>
+		return "@" + strings.TrimPrefix(target, "!")
+	}
+
+	if !hasLabel {
+		return underline(target, opts)
+	}
+	return underline(label, opts) + " (" + target + ")"
+}
+
+func underline(s string, opts PreviewOptions) string {
+	if !opts.Color {
+		return s
+	}
+	return ansiUnder + s + ansiUnderOff
+}
+
+func renderQuotes(text string, opts PreviewOptions) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "&gt;") {
+			line = ">" + strings.TrimPrefix(line, "&gt;")
+		}
+		if strings.HasPrefix(line, ">") {
+			quoted := strings.TrimPrefix(strings.TrimPrefix(line, ">"), " ")
+			if opts.Color {
+				quoted = ansiDim + quoted + ansiDimOff
+			}
+			lines[i] = "│ " + quoted
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPreview_Plain(t *testing.T) {
+	opts := PreviewOptions{
+		Users:    map[string]string{"U123": "alice"},
+		Channels: map[string]string{"C456": "general"},
+		Groups:   map[string]string{"S789": "engineering-team"},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"user mention", "hi <@U123>", "hi @alice"},
+		{"unknown user mention", "hi <@U999>", "hi @U999"},
+		{"labeled user mention", "hi <@U999|bob>", "hi @bob"},
+		{"channel reference", "see <#C456>", "see #general"},
+		{"labeled channel reference", "see <#C789|random>", "see #random"},
+		{"broadcast", "<!here> standup", "@here standup"},
+		{"user group mention", "<!subteam^S789> ship it", "@engineering-team ship it"},
+		{"unknown user group mention", "<!subteam^S000> ship it", "@subteam^S000 ship it"},
+		{"labeled link", "<https://example.com|docs>", "docs (https://example.com)"},
+		{"bare link", "<https://example.com>", "https://example.com"},
+		{"known emoji", "ship it :rocket:", "ship it 🚀"},
+		{"unknown emoji left as-is", "hi :shipit-parrot:", "hi :shipit-parrot:"},
+		{"formatting untouched without color", "*bold* _it_", "*bold* _it_"},
+		{"emoji inside code untouched", "`:rocket:`", "`:rocket:`"},
+		{"quote", "> quoted\nnot", "│ quoted\nnot"},
+		{"escaped quote", "&gt; quoted", "│ quoted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderPreview(tt.input, opts); got != tt.want {
+				t.Errorf("RenderPreview(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPreview_Color(t *testing.T) {
+	opts := PreviewOptions{Color: true}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"bold", "a *b* c", "a " + ansiBold + "b" + ansiBoldOff + " c"},
+		{"italic", "_i_", ansiItalic + "i" + ansiItalicOff},
+		{"strike", "~s~", ansiStrike + "s" + ansiStrikeOff},
+		{"inline code", "run `make`", "run " + ansiCode + "make" + ansiCodeOff},
+		{"code block keeps asterisks", "```*x*```", ansiDim + "*x*" + ansiDimOff},
+		{"mid-word asterisks untouched", "2*3*4", "2*3*4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderPreview(tt.input, opts); got != tt.want {
+				t.Errorf("RenderPreview(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPreview_ColorLink(t *testing.T) {
+	got := RenderPreview("<https://example.com|docs>", PreviewOptions{Color: true})
+	if !strings.Contains(got, ansiUnder+"docs"+ansiUnderOff) {
+		t.Errorf("expected underlined link label, got %q", got)
+	}
+}
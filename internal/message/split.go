@@ -0,0 +1,30 @@
+package message
+
+import "strings"
+
+// MaxLength is the longest message text, in characters, Slack accepts in one post
+const MaxLength = 4000
+
+// Split breaks text into parts of at most limit characters, preferring to break
+// between paragraphs, then lines, then words. Text within the limit is returned
+// as the only part.
+func Split(text string, limit int) []string {
+	runes := []rune(text)
+	var parts []string
+	for len(runes) > limit {
+		window := string(runes[:limit])
+		cut := len(runes[:limit])
+		for _, sep := range []string{"\n\n", "\n", " "} {
+			if i := strings.LastIndex(window, sep); i > 0 {
+				cut = len([]rune(window[:i]))
+				break
+			}
+		}
+		parts = append(parts, strings.TrimRight(string(runes[:cut]), " \n"))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " \n"))
+	}
+	if len(runes) > 0 || len(parts) == 0 {
+		parts = append(parts, string(runes))
+	}
+	return parts
+}
@@ -0,0 +1,39 @@
+package message
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		limit int
+		want  []string
+	}{
+		{name: "within limit", input: "short", limit: 10, want: []string{"short"}},
+		{name: "empty", input: "", limit: 10, want: []string{""}},
+		{name: "paragraphs", input: "first para\n\nsecond\nline", limit: 16, want: []string{"first para", "second\nline"}},
+		{name: "lines", input: "one line\ntwo line\nthree", limit: 12, want: []string{"one line", "two line", "three"}},
+		{name: "words", input: "alpha beta gamma delta", limit: 11, want: []string{"alpha beta", "gamma delta"}},
+		{name: "hard cut", input: "abcdefghij", limit: 4, want: []string{"abcd", "efgh", "ij"}},
+		{name: "counts characters not bytes", input: "ééé ééé", limit: 3, want: []string{"ééé", "ééé"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.input, tt.limit)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q, %d) = %q, want %q", tt.input, tt.limit, got, tt.want)
+			}
+		})
+	}
+
+	long := strings.Repeat("word ", 2000)
+	for i, part := range Split(long, MaxLength) {
+		if n := len([]rune(part)); n > MaxLength {
+			t.Errorf("part %d is %d characters, over %d", i, n, MaxLength)
+		}
+	}
+}
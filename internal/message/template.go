@@ -0,0 +1,87 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateVars are the built-in values a message template can use for one
+// occurrence
+type TemplateVars struct {
+	// When the occurrence is posted
+	PostAt time.Time
+
+	// 1-based position of the occurrence in its schedule, and the schedule's length
+	// (0 when not known, e.g. for open-ended or one-off occurrences)
+	Occurrence int
+	Total      int
+
+	// Channel as given in the schedule
+	Channel string
+
+	// Mention of the person whose turn it is (e.g. "<@U123>"), for assignee rotations
+	Assignee string
+}
+
+// templateFuncs returns the built-in template functions for one occurrence
+func templateFuncs(vars TemplateVars) template.FuncMap {
+	return template.FuncMap{
+		"date":       func() string { return vars.PostAt.Format("2006-01-02") },
+		"time":       func() string { return vars.PostAt.Format("15:04") },
+		"weekday":    func() string { return vars.PostAt.Weekday().String() },
+		"occurrence": func() int { return vars.Occurrence },
+		"total":      func() int { return vars.Total },
+		"channel":    func() string { return vars.Channel },
+		"assignee":   func() string { return vars.Assignee },
+		"days_until": func(date string) (int, error) { return daysUntil(vars.PostAt, date) },
+	}
+}
+
+// daysUntil counts the calendar days from the occurrence's post date to a
+// YYYY-MM-DD date (negative once it has passed)
+func daysUntil(postAt time.Time, date string) (int, error) {
+	target, err := time.ParseInLocation("2006-01-02", date, postAt.Location())
+	if err != nil {
+		return 0, fmt.Errorf("invalid days_until date %q (use YYYY-MM-DD)", date)
+	}
+	// Compare at UTC midnight so DST changes don't shorten a day
+	from := time.Date(postAt.Year(), postAt.Month(), postAt.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(target.Year(), target.Month(), target.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24), nil
+}
+
+// ParseTemplate checks a message template's syntax
+func ParseTemplate(text string) error {
+	_, err := parseTemplate(text, TemplateVars{})
+	return err
+}
+
+func parseTemplate(text string, vars TemplateVars) (*template.Template, error) {
+	tmpl, err := template.New("message").Funcs(templateFuncs(vars)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Render executes a text/template message for one occurrence. User data is the
+// template's dot ({{.product}}); built-in values are functions: {{date}}, {{time}},
+// {{weekday}}, {{occurrence}}, {{total}}, {{channel}}, {{assignee}} and
+// {{days_until "2025-12-01"}}.
+func Render(text string, data map[string]interface{}, vars TemplateVars) (string, error) {
+	tmpl, err := parseTemplate(text, vars)
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render message template: %w", err)
+	}
+	return out.String(), nil
+}
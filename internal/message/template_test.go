@@ -0,0 +1,57 @@
+package message
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	vars := TemplateVars{
+		PostAt:     time.Date(2025, 3, 7, 9, 30, 0, 0, time.UTC),
+		Occurrence: 2,
+		Total:      5,
+		Channel:    "general",
+		Assignee:   "<@U1>",
+	}
+	data := map[string]interface{}{"product": "Rocket", "owners": []interface{}{"ana", "bo"}}
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain text", tmpl: "Hello", want: "Hello"},
+		{name: "user data", tmpl: "{{.product}} ships soon", want: "Rocket ships soon"},
+		{name: "built-ins", tmpl: "{{weekday}} {{date}} {{time}} #{{channel}} ({{occurrence}}/{{total}})", want: "Friday 2025-03-07 09:30 #general (2/5)"},
+		{name: "range over data", tmpl: "{{range .owners}}<{{.}}>{{end}}", want: "<ana><bo>"},
+		{name: "missing key", tmpl: "{{.version}}", wantErr: true},
+		{name: "syntax error", tmpl: "{{.product", wantErr: true},
+		{name: "assignee", tmpl: "{{assignee}} runs standup", want: "<@U1> runs standup"},
+		{name: "countdown", tmpl: `{{days_until "2025-04-01"}} days until launch`, want: "25 days until launch"},
+		{name: "countdown across a year", tmpl: `{{days_until "2026-03-07"}}`, want: "365"},
+		{name: "countdown passed", tmpl: `{{days_until "2025-03-01"}}`, want: "-6"},
+		{name: "countdown invalid date", tmpl: `{{days_until "April 1"}}`, wantErr: true},
+		{name: "unknown function", tmpl: "{{owner}}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.tmpl, data, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	if err := ParseTemplate("{{date}}: {{.title}}"); err != nil {
+		t.Errorf("ParseTemplate() error = %v", err)
+	}
+	if err := ParseTemplate("{{if}}"); err == nil {
+		t.Error("ParseTemplate() expected error")
+	}
+}
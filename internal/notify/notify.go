@@ -0,0 +1,207 @@
+// Package notify reports scheduling outcomes to webhooks or a Slack DM, so
+// unattended runs (cron jobs, the daemon, serve mode) don't fail silently
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// EventType is what happened
+type EventType string
+
+const (
+	EventScheduled      EventType = "scheduled"       // messages were scheduled
+	EventScheduleFailed EventType = "schedule_failed" // scheduling failed, possibly part-way
+	EventExtended       EventType = "extended"        // the daemon topped up a series
+	EventExtendFailed   EventType = "extend_failed"   // the daemon failed to top up a series
+)
+
+// ValidEvents for validation
+var ValidEvents = []EventType{EventScheduled, EventScheduleFailed, EventExtended, EventExtendFailed}
+
+// Event is one scheduling outcome
+type Event struct {
+	Type EventType `json:"event"`
+
+	// Series name, when known
+	Series string `json:"series,omitempty"`
+
+	// Channel as given in the schedule
+	Channel string `json:"channel,omitempty"`
+
+	// Number of messages scheduled
+	Count int `json:"count"`
+
+	// Why it failed, for the failure events
+	Error string `json:"error,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+// Text describes the event in a line, e.g. for a Slack message
+func (e Event) Text() string {
+	what := "messages"
+	if e.Series != "" {
+		what = "series " + e.Series
+	}
+	where := ""
+	if e.Channel != "" {
+		where = " in " + e.Channel
+	}
+	switch e.Type {
+	case EventScheduled:
+		return fmt.Sprintf("✅ Scheduled %d message(s)%s (%s)", e.Count, where, what)
+	case EventExtended:
+		return fmt.Sprintf("🔁 Extended %s%s by %d message(s)", what, where, e.Count)
+	case EventExtendFailed:
+		return fmt.Sprintf("⚠️ Failed to extend %s%s: %s", what, where, e.Error)
+	default:
+		return fmt.Sprintf("⚠️ Failed to schedule %s%s after %d message(s): %s", what, where, e.Count, e.Error)
+	}
+}
+
+// Notifier is told about scheduling outcomes
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// Webhook POSTs events as JSON. The body has a text field as well, so a Slack
+// incoming webhook URL works without a relay.
+type Webhook struct {
+	URL string
+
+	// HTTP client to use (http.DefaultClient if nil)
+	Client *http.Client
+}
+
+func (w Webhook) Notify(event Event) error {
+	body, err := json.Marshal(struct {
+		Event
+		Text string `json:"text"`
+	}{event, event.Text()})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s failed: %w", redactURL(w.URL), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s failed: %s", redactURL(w.URL), resp.Status)
+	}
+	return nil
+}
+
+// DM sends events as Slack direct messages to a user
+type DM struct {
+	Client *slack.Client
+
+	// @name, email or user ID
+	User string
+}
+
+func (d DM) Notify(event Event) error {
+	channelID, err := d.Client.GetChannelID(d.User)
+	if err != nil {
+		return fmt.Errorf("failed to DM %s: %w", d.User, err)
+	}
+	if _, err := d.Client.SendMessage(channelID, event.Text()); err != nil {
+		return fmt.Errorf("failed to DM %s: %w", d.User, err)
+	}
+	return nil
+}
+
+// Hooks sends each event to every notifier interested in it. Failures are
+// logged rather than returned, since they shouldn't fail the work being reported.
+type Hooks struct {
+	notifiers []Notifier
+
+	// Event types to send (all if empty)
+	events map[EventType]bool
+}
+
+// New builds the notifiers a config asks for; client sends the DMs
+func New(config types.NotifyConfig, client *slack.Client) (*Hooks, error) {
+	h := &Hooks{}
+	for _, hook := range config.Webhooks {
+		if u, err := url.Parse(hook); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook URL: %s", redactURL(hook))
+		}
+		h.notifiers = append(h.notifiers, Webhook{URL: hook})
+	}
+	if config.DM != "" {
+		h.notifiers = append(h.notifiers, DM{Client: client, User: config.DM})
+	}
+	for _, name := range config.Events {
+		event := EventType(name)
+		if !event.IsValid() {
+			return nil, fmt.Errorf("invalid notify event: %s (use: scheduled, schedule_failed, extended, extend_failed)", name)
+		}
+		if h.events == nil {
+			h.events = make(map[EventType]bool)
+		}
+		h.events[event] = true
+	}
+	return h, nil
+}
+
+// Add sends events to another notifier too
+func (h *Hooks) Add(n Notifier) {
+	h.notifiers = append(h.notifiers, n)
+}
+
+func (h *Hooks) Notify(event Event) error {
+	if h == nil || (h.events != nil && !h.events[event.Type]) {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, n := range h.notifiers {
+		if err := n.Notify(event); err != nil {
+			slog.Warn(fmt.Sprintf("Could not send %s notification: %v", event.Type, err), "event", string(event.Type), "error", err)
+		}
+	}
+	return nil
+}
+
+func (e EventType) IsValid() bool {
+	for _, v := range ValidEvents {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL hides a webhook's path, which for Slack webhooks is the secret
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Scheme + "://" + u.Host + "/…"
+}
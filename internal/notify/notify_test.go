@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	event := Event{Type: EventScheduled, Series: "standup", Channel: "#general", Count: 3, Time: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)}
+	if err := (Webhook{URL: server.URL}).Notify(event); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got["event"] != "scheduled" || got["series"] != "standup" || got["count"] != float64(3) {
+		t.Errorf("webhook body = %v", got)
+	}
+	if text, _ := got["text"].(string); !strings.Contains(text, "Scheduled 3 message(s) in #general") {
+		t.Errorf("webhook text = %q", got["text"])
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer failing.Close()
+	err := (Webhook{URL: failing.URL + "/services/T000/B000/secret"}).Notify(event)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Notify() to a failing webhook error = %v, want one without the URL's path", err)
+	}
+}
+
+func TestDM_Notify(t *testing.T) {
+	api := slack.NewFakeAPI()
+	api.AddUser("alice")
+	dm := DM{Client: slack.NewFakeClient(api), User: "@alice"}
+
+	if err := dm.Notify(Event{Type: EventExtendFailed, Series: "standup", Error: "not_in_channel"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(api.Posted) != 1 || !strings.HasPrefix(api.Posted[0].Channel, "D") || !strings.Contains(api.Posted[0].Text, "Failed to extend series standup: not_in_channel") {
+		t.Errorf("posted = %+v", api.Posted)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	if _, err := New(types.NotifyConfig{Webhooks: []string{"not a url"}}, nil); err == nil {
+		t.Error("New() with an invalid URL expected an error")
+	}
+	if _, err := New(types.NotifyConfig{Events: []string{"deleted"}}, nil); err == nil {
+		t.Error("New() with an unknown event expected an error")
+	}
+
+	hooks, err := New(types.NotifyConfig{Events: []string{"schedule_failed", "extend_failed"}}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var sent []Event
+	hooks.Add(notifierFunc(func(e Event) error {
+		sent = append(sent, e)
+		return nil
+	}))
+	hooks.Notify(Event{Type: EventScheduled})
+	hooks.Notify(Event{Type: EventScheduleFailed})
+	if len(sent) != 1 || sent[0].Type != EventScheduleFailed || sent[0].Time.IsZero() {
+		t.Errorf("sent = %+v, want only the failure, timestamped", sent)
+	}
+
+	// A nil set of hooks is a valid notifier that does nothing
+	var none *Hooks
+	if err := none.Notify(Event{Type: EventScheduled}); err != nil {
+		t.Errorf("nil Hooks Notify() error = %v", err)
+	}
+}
+
+type notifierFunc func(Event) error
+
+func (f notifierFunc) Notify(e Event) error { return f(e) }
@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// WriteAudit prints audit log entries, one action per line
+func WriteAudit(w io.Writer, entries []types.AuditEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No recorded actions")
+		return
+	}
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %-8s  %-8s  %s  %s", formatListTime(e.Time), e.Command, e.Action, e.User, e.Channel)
+		if e.SlackID != "" {
+			line += "  " + e.SlackID
+		}
+		if !e.PostAt.IsZero() {
+			line += "  for " + formatListTime(e.PostAt)
+		}
+		if e.Message != "" {
+			line += fmt.Sprintf("  %q", oneLine(e.Message))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
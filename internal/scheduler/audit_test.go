@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestWriteAudit(t *testing.T) {
+	entries := []types.AuditEntry{
+		{Time: time.Date(2025, 1, 1, 9, 0, 0, 0, LocalTZ), User: "alex", Command: "schedule", Action: "schedule", Channel: "C1", SlackID: "Q1", PostAt: time.Date(2025, 1, 2, 9, 0, 0, 0, LocalTZ), Message: "Stand\nup"},
+		{Time: time.Date(2025, 1, 1, 10, 0, 0, 0, LocalTZ), User: "alex", Command: "delete", Action: "delete", Channel: "C1", SlackID: "Q1"},
+	}
+
+	var buf bytes.Buffer
+	WriteAudit(&buf, entries)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, want := range []string{"2025-01-01 09:00", "schedule", "alex", "Q1", "for 2025-01-02 09:00", `"Stand up"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("first line missing %q: %s", want, lines[0])
+		}
+	}
+	if strings.Contains(lines[1], "for ") || !strings.Contains(lines[1], "delete") {
+		t.Errorf("second line = %s", lines[1])
+	}
+
+	buf.Reset()
+	WriteAudit(&buf, nil)
+	if buf.String() != "No recorded actions\n" {
+		t.Errorf("WriteAudit(nil) = %q", buf.String())
+	}
+}
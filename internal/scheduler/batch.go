@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// BatchResult is the outcome of scheduling one entry of a batch
+type BatchResult struct {
+	// Entry name (the config's Name, or one derived from it)
+	Name string
+
+	// Series record for the local store (occurrences scheduled before any failure)
+	Series types.Series
+
+	// Outcome of each occurrence, for a Report
+	Results []OccurrenceResult
+
+	Err error
+}
+
+// ScheduleBatch schedules every config in turn, carrying on past failures so one
+// bad entry doesn't block the rest
+func ScheduleBatch(client *slack.Client, configs []types.ScheduleConfig) []BatchResult {
+	results := make([]BatchResult, 0, len(configs))
+	for i := range configs {
+		slog.Info(fmt.Sprintf("== %s ==", entryName(&configs[i])), "schedule", entryName(&configs[i]))
+		results = append(results, scheduleEntry(client, &configs[i]))
+	}
+	return results
+}
+
+// entryName is the config's Name, or one derived from it
+func entryName(config *types.ScheduleConfig) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	return store.DefaultName(config)
+}
+
+func scheduleEntry(client *slack.Client, config *types.ScheduleConfig) BatchResult {
+	name := entryName(config)
+	s := New(client, config)
+	_, err := s.Schedule()
+	return BatchResult{Name: name, Series: s.Series(name), Results: s.Results(name), Err: err}
+}
+
+// DefaultFanOutWorkers is how many channels FanOut schedules at once by default,
+// low enough to stay clear of Slack's rate limits
+const DefaultFanOutWorkers = 4
+
+// ScheduleConcurrently schedules every config like ScheduleBatch, but with up to
+// workers at a time. Results keep the configs' order; progress output from
+// different entries may interleave.
+func ScheduleConcurrently(client *slack.Client, configs []types.ScheduleConfig, workers int) []BatchResult {
+	if workers <= 1 {
+		return ScheduleBatch(client, configs)
+	}
+
+	results := make([]BatchResult, len(configs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = scheduleEntry(client, &configs[i])
+			}
+		}()
+	}
+	for i := range configs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// FanOut creates the same schedule in each channel, up to workers channels at a
+// time (one at a time if workers <= 1), reporting per channel like ScheduleBatch.
+// Each channel gets its own series, named after the schedule and the channel,
+// and listings group them together under the schedule's name.
+func FanOut(client *slack.Client, config types.ScheduleConfig, channels []string, workers int) []BatchResult {
+	return ScheduleConcurrently(client, FanOutConfigs(config, channels), workers)
+}
+
+// DMEach schedules the message as an individual DM to each user (username, display
+// name, email or user ID), up to workers at a time, like FanOut
+func DMEach(client *slack.Client, config types.ScheduleConfig, users []string, workers int) []BatchResult {
+	targets := make([]string, 0, len(users))
+	for _, user := range users {
+		if !slack.IsUserTarget(user) {
+			user = "@" + user
+		}
+		targets = append(targets, user)
+	}
+	return FanOut(client, config, targets, workers)
+}
+
+// FanOutConfigs copies a schedule into one config per channel, as FanOut schedules them
+func FanOutConfigs(config types.ScheduleConfig, channels []string) []types.ScheduleConfig {
+	group := config.Name
+	if group == "" {
+		unnamed := config
+		unnamed.Channel = ""
+		group = store.DefaultName(&unnamed)
+	}
+
+	configs := make([]types.ScheduleConfig, 0, len(channels))
+	for _, channel := range channels {
+		c := config
+		c.Channel = channel
+		c.Name = group + "-" + store.Slug(channel)
+		c.Group = group
+		configs = append(configs, c)
+	}
+	return configs
+}
+
+// WriteBatchReport writes a per-entry success/failure summary and returns the
+// number of failed entries
+func WriteBatchReport(w io.Writer, results []BatchResult) int {
+	failed := 0
+	fmt.Fprintf(w, "\nBatch summary:\n")
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(w, "  ✗ %s: %v\n", r.Name, r.Err)
+			continue
+		}
+		fmt.Fprintf(w, "  ✓ %s: %d message(s) scheduled\n", r.Name, len(r.Series.Occurrences))
+	}
+	fmt.Fprintf(w, "%d of %d schedule(s) succeeded\n", len(results)-failed, len(results))
+	return failed
+}
+
+// WriteFanOutTable writes a fan-out's results as a table, one row per channel,
+// and returns the number of failed channels
+func WriteFanOutTable(w io.Writer, results []BatchResult) int {
+	failed := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANNEL\tSERIES\tSTATUS\tMESSAGES\tERROR")
+	for _, r := range results {
+		status, errText := "ok", "-"
+		if r.Err != nil {
+			failed++
+			status, errText = "failed", r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", channelLabelFor(r.Series.Config.Channel), r.Name, status, len(r.Series.Occurrences), errText)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%d of %d channel(s) succeeded\n", len(results)-failed, len(results))
+	return failed
+}
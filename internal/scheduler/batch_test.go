@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestScheduleBatch(t *testing.T) {
+	_, client := newFakeWorkspace("C123")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	configs := []types.ScheduleConfig{
+		{Name: "standup", Message: "Standup", Channel: "C123", StartDate: tomorrow, SendTime: "09:00", Interval: types.IntervalDaily, RepeatCount: 2},
+		{Message: "Broken", Channel: "C123", StartDate: tomorrow, SendTime: "09:00", Interval: types.Interval("fortnightly")},
+		{Message: "Retro", Channel: "C123", StartDate: tomorrow, SendTime: "15:00", Interval: types.IntervalNone},
+	}
+
+	results := ScheduleBatch(client, configs)
+	if len(results) != 3 {
+		t.Fatalf("ScheduleBatch() returned %d results, want 3", len(results))
+	}
+	if results[0].Err != nil || results[0].Name != "standup" || len(results[0].Series.Occurrences) != 2 {
+		t.Errorf("results[0] = %+v, want standup with 2 occurrences", results[0])
+	}
+	if results[1].Err == nil {
+		t.Error("results[1] expected error for invalid interval")
+	}
+	if results[2].Err != nil || results[2].Name != "c123-"+tomorrow || len(results[2].Series.Occurrences) != 1 {
+		t.Errorf("results[2] = %+v, want derived name with 1 occurrence", results[2])
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	api, client := newFakeWorkspace("C123", "C456")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	config := types.ScheduleConfig{Message: "Release today", StartDate: tomorrow, SendTime: "09:00", Interval: types.IntervalDaily, RepeatCount: 2}
+
+	results := FanOut(client, config, []string{"C123", "C456", "#missing"}, 1)
+	if len(results) != 3 {
+		t.Fatalf("FanOut() returned %d results, want 3", len(results))
+	}
+	for i, channel := range []string{"C123", "C456"} {
+		r := results[i]
+		if r.Err != nil || len(r.Series.Occurrences) != 2 || r.Series.Config.Channel != channel {
+			t.Errorf("results[%d] = %+v, want 2 occurrences in %s", i, r, channel)
+		}
+	}
+	if results[2].Err == nil {
+		t.Error("results[2] expected error for unknown channel")
+	}
+	if scheduleCalls(api) != 4 {
+		t.Errorf("scheduled %d messages, want 4", scheduleCalls(api))
+	}
+
+	group := "daily-" + tomorrow
+	for i, want := range []string{group + "-c123", group + "-c456", group + "-missing"} {
+		if results[i].Name != want || results[i].Series.Config.Group != group {
+			t.Errorf("results[%d] name = %q, group = %q, want %q in group %q", i, results[i].Name, results[i].Series.Config.Group, want, group)
+		}
+	}
+}
+
+func TestFanOutConfigs_Named(t *testing.T) {
+	configs := FanOutConfigs(types.ScheduleConfig{Name: "launch", Message: "hi"}, []string{"#general", "@alice"})
+	if len(configs) != 2 || configs[0].Name != "launch-general" || configs[1].Name != "launch-alice" || configs[1].Channel != "@alice" || configs[0].Group != "launch" {
+		t.Errorf("FanOutConfigs() = %+v", configs)
+	}
+}
+
+func TestWriteBatchReport(t *testing.T) {
+	results := []BatchResult{
+		{Name: "standup", Series: types.Series{Occurrences: make([]types.Occurrence, 2)}},
+		{Name: "broken", Err: errors.New("invalid interval: fortnightly")},
+	}
+
+	var buf bytes.Buffer
+	if failed := WriteBatchReport(&buf, results); failed != 1 {
+		t.Errorf("WriteBatchReport() = %d failed, want 1", failed)
+	}
+	for _, want := range []string{"✓ standup: 2 message(s)", "✗ broken: invalid interval", "1 of 2 schedule(s) succeeded"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFanOut_Concurrent(t *testing.T) {
+	api, client := newFakeWorkspace("C1", "C2", "C3", "C4", "C5", "C6")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	config := types.ScheduleConfig{Name: "all-hands", Message: "All hands at 3", StartDate: tomorrow, SendTime: "09:00", Interval: types.IntervalNone}
+	channels := []string{"C1", "C2", "C3", "C4", "C5", "C6", "#missing"}
+
+	results := FanOut(client, config, channels, 3)
+	if len(results) != len(channels) {
+		t.Fatalf("FanOut() returned %d results, want %d", len(results), len(channels))
+	}
+	for i, r := range results[:6] {
+		if r.Err != nil || r.Series.Config.Channel != channels[i] || len(r.Series.Occurrences) != 1 {
+			t.Errorf("results[%d] = %+v, want 1 occurrence in %s", i, r, channels[i])
+		}
+	}
+	if scheduleCalls(api) != 6 {
+		t.Errorf("scheduled %d messages, want 6", scheduleCalls(api))
+	}
+
+	var buf bytes.Buffer
+	if failed := WriteFanOutTable(&buf, results); failed != 1 {
+		t.Errorf("WriteFanOutTable() = %d failed, want 1", failed)
+	}
+	for _, want := range []string{"CHANNEL", "all-hands-c1", "#missing", "failed", "6 of 7 channel(s) succeeded"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("table missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestDMEach(t *testing.T) {
+	var channels []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/users.list":
+			w.Write([]byte(`{"ok":true,"members":[{"id":"U1","name":"alice"},{"id":"U2","name":"bob","profile":{"email":"bob@example.com"}}],"response_metadata":{"next_cursor":""}}`))
+		case "/conversations.open":
+			w.Write([]byte(`{"ok":true,"channel":{"id":"D` + r.Form.Get("users") + `"}}`))
+		case "/chat.scheduleMessage":
+			channels = append(channels, r.Form.Get("channel"))
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	config := types.ScheduleConfig{Name: "timesheets", Message: "Submit your timesheet", StartDate: tomorrow, SendTime: "16:00", Interval: types.IntervalNone}
+
+	results := DMEach(client, config, []string{"alice", "bob@example.com", "U3", "carol"}, 1)
+	if len(results) != 4 {
+		t.Fatalf("DMEach() returned %d results, want 4", len(results))
+	}
+	for i, r := range results[:3] {
+		if r.Err != nil || r.Series.Config.Group != "timesheets" {
+			t.Errorf("results[%d] = %+v, want success in group timesheets", i, r)
+		}
+	}
+	if results[3].Err == nil {
+		t.Error("results[3] expected error for unknown user")
+	}
+	if want := "DU1|DU2|DU3"; strings.Join(channels, "|") != want {
+		t.Errorf("scheduled into %q, want %s", channels, want)
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// calendarCell is the width of one day in the month grid: a 2-digit day, up to
+// three markers and a separating space
+const calendarCell = 6
+
+// ParseMonth parses a --month value (YYYY-MM); empty means the month containing now
+func ParseMonth(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		now = now.In(LocalTZ)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, LocalTZ), nil
+	}
+	month, err := time.ParseInLocation("2006-01", s, LocalTZ)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month: %s (use YYYY-MM)", s)
+	}
+	return month, nil
+}
+
+// WriteCalendar renders a month grid (weeks starting Monday) marking the days
+// that have scheduled messages, followed by a legend mapping each marker to its
+// group: the message's series if it has one, otherwise its text
+func WriteCalendar(w io.Writer, messages []ListedMessage, month time.Time) {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, LocalTZ)
+	last := first.AddDate(0, 1, -1).Day()
+
+	// Assign markers in order of first appearance within the month
+	var keys []string
+	markers := make(map[string]byte)
+	counts := make(map[string]int)
+	days := make(map[int][]byte)
+	for _, msg := range messages {
+		t := msg.PostAt.In(LocalTZ)
+		if t.Year() != first.Year() || t.Month() != first.Month() {
+			continue
+		}
+		key := msg.Group
+		if key == "" {
+			key = oneLine(msg.Text)
+		}
+		marker, ok := markers[key]
+		if !ok {
+			marker = calendarMarker(len(keys))
+			markers[key] = marker
+			keys = append(keys, key)
+		}
+		counts[key]++
+		if !containsByte(days[t.Day()], marker) {
+			days[t.Day()] = append(days[t.Day()], marker)
+		}
+	}
+
+	title := first.Format("January 2006")
+	width := 7 * calendarCell
+	fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", (width-len(title))/2), title)
+	for _, name := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		fmt.Fprintf(w, "%-*s", calendarCell, name)
+	}
+	fmt.Fprintln(w)
+
+	// Monday-based column of the 1st
+	offset := (int(first.Weekday()) + 6) % 7
+	var row strings.Builder
+	row.WriteString(strings.Repeat(" ", offset*calendarCell))
+	for day := 1; day <= last; day++ {
+		marks := string(days[day])
+		if len(marks) > 3 {
+			marks = marks[:2] + "+"
+		}
+		fmt.Fprintf(&row, "%2d%-*s", day, calendarCell-2, marks)
+		if (offset+day)%7 == 0 || day == last {
+			fmt.Fprintln(w, strings.TrimRight(row.String(), " "))
+			row.Reset()
+		}
+	}
+
+	if len(keys) == 0 {
+		fmt.Fprintln(w, "\nNo scheduled messages this month")
+		return
+	}
+	fmt.Fprintln(w)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%c  %s (%d message(s))\n", markers[key], key, counts[key])
+	}
+}
+
+// calendarMarker returns the marker for the n-th group: A-Z, then a-z, then digits
+func calendarMarker(n int) byte {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	if n < len(alphabet) {
+		return alphabet[n]
+	}
+	return '?'
+}
+
+func containsByte(bs []byte, b byte) bool {
+	for _, x := range bs {
+		if x == b {
+			return true
+		}
+	}
+	return false
+}
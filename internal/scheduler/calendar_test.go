@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMonth(t *testing.T) {
+	now := time.Date(2025, 3, 17, 10, 0, 0, 0, LocalTZ)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Date(2025, 3, 1, 0, 0, 0, 0, LocalTZ), false},
+		{"2025-11", time.Date(2025, 11, 1, 0, 0, 0, 0, LocalTZ), false},
+		{"2025-13", time.Time{}, true},
+		{"March", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMonth(tt.input, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("ParseMonth(%q) = %v, %v; want %v (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteCalendar(t *testing.T) {
+	at := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 9, 0, 0, 0, LocalTZ) }
+	messages := []ListedMessage{
+		{Group: "standup", Text: "Standup", PostAt: at(3, 3)},
+		{Group: "standup", Text: "Standup", PostAt: at(3, 4)},
+		{Text: "Retro", PostAt: at(3, 4)},
+		{Text: "Retro", PostAt: at(3, 4)},
+		{Text: "Next month", PostAt: at(4, 1)},
+	}
+
+	var buf bytes.Buffer
+	WriteCalendar(&buf, messages, time.Date(2025, 3, 1, 0, 0, 0, 0, LocalTZ))
+	lines := strings.Split(buf.String(), "\n")
+
+	if !strings.Contains(lines[0], "March 2025") {
+		t.Errorf("title = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "Mo") {
+		t.Errorf("weekday header = %q", lines[1])
+	}
+	// March 1st 2025 is a Saturday, so the first week has two days
+	if got := lines[2]; got != strings.Repeat(" ", 5*calendarCell)+" 1     2" {
+		t.Errorf("first week = %q", got)
+	}
+	if got := lines[3]; !strings.HasPrefix(got, " 3A    4AB   5") {
+		t.Errorf("second week = %q", got)
+	}
+	// March 31st is a Monday on its own row
+	if got := lines[7]; got != "31" {
+		t.Errorf("last week = %q", got)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"A  standup (2 message(s))", "B  Retro (2 message(s))"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("legend missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Next month") {
+		t.Errorf("calendar includes another month's message:\n%s", out)
+	}
+}
+
+func TestWriteCalendar_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	WriteCalendar(&buf, nil, time.Date(2025, 2, 1, 0, 0, 0, 0, LocalTZ))
+	if !strings.Contains(buf.String(), "No scheduled messages this month") {
+		t.Errorf("empty calendar = %q", buf.String())
+	}
+}
@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// CleanupResult is the drift Cleanup found between the store and Slack
+type CleanupResult struct {
+	// Slack entries whose post time has passed: leftovers Slack failed to post
+	// or clean up, which can be deleted
+	Stale []types.Occurrence
+
+	// Upcoming Slack entries that no series in the store tracks
+	Orphaned []types.Occurrence
+
+	// Occurrences dropped from the store once their post time passed
+	Pruned []PrunedOccurrence
+
+	// Upcoming occurrences in the store that are no longer scheduled in Slack
+	// (e.g. deleted in Slack's UI), now marked skipped so the series isn't
+	// topped up with them again
+	Cancelled []PrunedOccurrence
+}
+
+// PrunedOccurrence is an occurrence Cleanup dropped from a series, or cancelled
+type PrunedOccurrence struct {
+	Series string
+	types.Occurrence
+}
+
+// Cleanup compares the store with the messages scheduled in a channel (all
+// channels if channelID is empty): it prunes occurrences whose post time has
+// passed and marks upcoming ones Slack no longer has as skipped, recording the
+// changes in st (the caller saves it, or doesn't for a dry run). Paused series
+// and occurrences never scheduled are kept out of Slack on purpose, so only
+// their past occurrences are pruned.
+func Cleanup(client *slack.Client, st *store.Store, channelID string, now time.Time) (CleanupResult, error) {
+	messages, err := client.ListScheduledMessages(channelID)
+	if err != nil {
+		return CleanupResult{}, err
+	}
+
+	var result CleanupResult
+	inSlack := make(map[string]bool, len(messages))
+	for _, occ := range slack.ToOccurrences(messages) {
+		inSlack[occ.ScheduledID] = true
+		if !occ.PostAt.After(now) {
+			result.Stale = append(result.Stale, occ)
+		} else if _, ok := st.FindByScheduledID(occ.ScheduledID); !ok {
+			result.Orphaned = append(result.Orphaned, occ)
+		}
+	}
+
+	for _, series := range st.List() {
+		var kept []types.Occurrence
+		changed := false
+		for _, occ := range series.Occurrences {
+			switch {
+			case channelID != "" && occ.Channel != channelID:
+			case !occ.PostAt.After(now):
+				result.Pruned = append(result.Pruned, PrunedOccurrence{Series: series.Name, Occurrence: occ})
+				if !occ.Extra && !occ.Continuation {
+					series.Pruned++
+				}
+				changed = true
+				continue
+			case occ.ScheduledID != "" && !inSlack[occ.ScheduledID] && !occ.Skipped && !series.Paused:
+				result.Cancelled = append(result.Cancelled, PrunedOccurrence{Series: series.Name, Occurrence: occ})
+				occ.Skipped = true
+				changed = true
+			}
+			kept = append(kept, occ)
+		}
+		if changed {
+			series.Occurrences = kept
+			st.Put(series)
+		}
+	}
+
+	return result, nil
+}
+
+// Write prints what Cleanup found and pruned
+func (r CleanupResult) Write(w io.Writer) {
+	for _, occ := range r.Pruned {
+		fmt.Fprintf(w, "  - %s: %s %s\n", occ.Series, formatListTime(occ.PostAt), oneLine(occ.Message))
+	}
+	for _, occ := range r.Cancelled {
+		fmt.Fprintf(w, "  x %s: %s %s (no longer in Slack)\n", occ.Series, formatListTime(occ.PostAt), oneLine(occ.Message))
+	}
+	for _, occ := range r.Stale {
+		fmt.Fprintf(w, "  ! %s past due in Slack: %s %s\n", occ.ScheduledID, formatListTime(occ.PostAt), oneLine(occ.Message))
+	}
+	for _, occ := range r.Orphaned {
+		fmt.Fprintf(w, "  ? %s not tracked locally: %s %s\n", occ.ScheduledID, formatListTime(occ.PostAt), oneLine(occ.Message))
+	}
+	fmt.Fprintf(w, "%d pruned from local state, %d no longer in Slack, %d past due in Slack, %d untracked in Slack\n",
+		len(r.Pruned), len(r.Cancelled), len(r.Stale), len(r.Orphaned))
+}
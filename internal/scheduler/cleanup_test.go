@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestCleanup(t *testing.T) {
+	api := slack.NewFakeAPI()
+	channelID := api.AddChannel("general")
+	client := slack.NewFakeClient(api)
+	now := time.Now().Truncate(time.Second)
+
+	tracked, err := client.ScheduleMessage(channelID, "standup", now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan, _ := client.ScheduleMessage(channelID, "made elsewhere", now.Add(48*time.Hour))
+	stale, _ := client.ScheduleMessage(channelID, "never posted", now.Add(-time.Hour))
+
+	st, err := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Put(types.Series{Name: "standup", Occurrences: []types.Occurrence{
+		{Channel: channelID, Message: "standup", PostAt: now.Add(-24 * time.Hour), ScheduledID: "QPOSTED"},
+		{Channel: channelID, Message: "standup", PostAt: now.Add(24 * time.Hour), ScheduledID: tracked},
+		{Channel: channelID, Message: "standup", PostAt: now.Add(72 * time.Hour), ScheduledID: "QDELETED"},
+		{Channel: channelID, Message: "standup", PostAt: now.Add(96 * time.Hour), ScheduledID: "QSKIPPED", Skipped: true},
+	}})
+	st.Put(types.Series{Name: "paused", Paused: true, Occurrences: []types.Occurrence{
+		{Channel: channelID, Message: "retro", PostAt: now.Add(72 * time.Hour), ScheduledID: "QPAUSED"},
+	}})
+
+	result, err := Cleanup(client, st, channelID, now)
+	if err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+
+	if len(result.Stale) != 1 || result.Stale[0].ScheduledID != stale {
+		t.Errorf("Stale = %+v, want %s", result.Stale, stale)
+	}
+	if len(result.Orphaned) != 1 || result.Orphaned[0].ScheduledID != orphan {
+		t.Errorf("Orphaned = %+v, want %s", result.Orphaned, orphan)
+	}
+	if len(result.Pruned) != 1 || result.Pruned[0].ScheduledID != "QPOSTED" || result.Pruned[0].Series != "standup" {
+		t.Errorf("Pruned = %+v, want QPOSTED", result.Pruned)
+	}
+	if len(result.Cancelled) != 1 || result.Cancelled[0].ScheduledID != "QDELETED" {
+		t.Errorf("Cancelled = %+v, want QDELETED", result.Cancelled)
+	}
+
+	series, _ := st.Get("standup")
+	if len(series.Occurrences) != 3 || series.Pruned != 1 {
+		t.Fatalf("standup after cleanup = %+v", series)
+	}
+	if occ := series.Occurrences[1]; occ.ScheduledID != "QDELETED" || !occ.Skipped {
+		t.Errorf("deleted occurrence = %+v, want it kept as skipped", occ)
+	}
+	if paused, _ := st.Get("paused"); len(paused.Occurrences) != 1 || paused.Occurrences[0].Skipped {
+		t.Errorf("paused series changed: %+v", paused)
+	}
+
+	var out bytes.Buffer
+	result.Write(&out)
+	if !strings.Contains(out.String(), "1 pruned from local state, 1 no longer in Slack, 1 past due in Slack, 1 untracked in Slack") {
+		t.Errorf("Write() = %s", out.String())
+	}
+
+	// Nothing left to do a second time
+	again, err := Cleanup(client, st, channelID, now)
+	if err != nil || len(again.Pruned)+len(again.Cancelled) != 0 {
+		t.Errorf("second Cleanup() = %+v, %v", again, err)
+	}
+}
+
+func TestExtend_NumbersAfterPruned(t *testing.T) {
+	api := slack.NewFakeAPI()
+	api.AddChannel("general")
+	now := time.Date(2030, 1, 1, 8, 0, 0, 0, LocalTZ)
+	config := &types.ScheduleConfig{
+		Channel: "general", Message: "Week {{occurrence}}", Template: true, StartDate: "2029-12-01", SendTime: "09:00", Interval: types.IntervalWeekly,
+	}
+	s := New(slack.NewFakeClient(api), config)
+	s.SetClock(NewFakeClock(now))
+
+	series := &types.Series{Name: "weekly", Config: *config, Pruned: 4}
+	if _, err := s.Extend(series, 1); err != nil {
+		t.Fatalf("Extend() error = %v", err)
+	}
+	if len(api.Scheduled) != 1 || api.Scheduled[0].Text != "Week 5" {
+		t.Errorf("scheduled = %+v, want Week 5", api.Scheduled)
+	}
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies a Scheduler's notion of now and the time zone schedules are
+// calculated in, so past-time skipping, the 120-day window and DST transitions
+// can be exercised deterministically
+type Clock interface {
+	Now() time.Time
+	Location() *time.Location
+}
+
+// SystemClock is the wall clock in LocalTZ
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time           { return time.Now() }
+func (SystemClock) Location() *time.Location { return LocalTZ }
+
+// FakeClock is a clock that only moves when told to, for tests and simulations
+// (e.g. running a daemon through several days in one go). It is safe for
+// concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+	loc *time.Location
+}
+
+// NewFakeClock returns a clock stopped at now, in LocalTZ
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Location returns the clock's time zone, LocalTZ unless SetLocation was called
+func (c *FakeClock) Location() *time.Location {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.loc == nil {
+		return LocalTZ
+	}
+	return c.loc
+}
+
+// Set moves the clock to t
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// SetLocation changes the time zone schedules are calculated in
+func (c *FakeClock) SetLocation(loc *time.Location) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loc = loc
+}
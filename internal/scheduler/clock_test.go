@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	clock.Advance(90 * time.Minute)
+	if got := clock.Now(); !got.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("Now() after Advance = %v", got)
+	}
+	if clock.Location() != LocalTZ {
+		t.Errorf("Location() = %v, want LocalTZ by default", clock.Location())
+	}
+}
+
+// TestScheduler_Clock_DST checks a daily send time stays at 09:00 local across a
+// DST change, in a zone taken from the clock rather than the machine
+func TestScheduler_Clock_DST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	clock := NewFakeClock(time.Date(2025, 3, 7, 12, 0, 0, 0, newYork))
+	clock.SetLocation(newYork)
+
+	s := New(nil, &types.ScheduleConfig{
+		Message: "standup", Channel: "C123", StartDate: "2025-03-07", SendTime: "09:00",
+		Interval: types.IntervalDaily, RepeatCount: 4,
+	})
+	s.SetClock(clock)
+	times, err := s.CalculateScheduleTimes()
+	if err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+	for _, tm := range times {
+		if tm.Location() != newYork || tm.Hour() != 9 {
+			t.Errorf("occurrence %v, want 09:00 New York time", tm)
+		}
+	}
+	// Clocks went forward on March 9, so that morning is 23 hours after the last
+	if gap := times[2].Sub(times[1]); gap != 23*time.Hour {
+		t.Errorf("gap across the DST change = %v, want 23h", gap)
+	}
+
+	// Moving the clock past the first send time makes it a past time
+	clock.Advance(24 * time.Hour)
+	if now := s.currentTime(); !now.After(times[1]) {
+		t.Errorf("currentTime() = %v, want after %v", now, times[1])
+	}
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSpec is a parsed standard 5-field cron expression:
+// minute hour day-of-month month day-of-week
+type CronSpec struct {
+	minutes  map[int]bool
+	hours    map[int]bool
+	days     map[int]bool
+	months   map[int]bool
+	weekdays map[int]bool
+
+	// Standard cron semantics: if both day fields are restricted, a day matches
+	// when EITHER matches
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// ParseCron parses a 5-field cron expression such as "0 9 * * MON-FRI".
+// Fields support *, lists (1,15), ranges (1-5), steps (*/15, 9-17/2) and
+// month/day names.
+func ParseCron(expr string) (*CronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields (minute hour day month weekday)", expr)
+	}
+
+	var spec CronSpec
+	var err error
+	if spec.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron minute field: %w", err)
+	}
+	if spec.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron hour field: %w", err)
+	}
+	if spec.days, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron day-of-month field: %w", err)
+	}
+	if spec.months, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid cron month field: %w", err)
+	}
+	if spec.weekdays, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("invalid cron day-of-week field: %w", err)
+	}
+	// Both 0 and 7 mean Sunday
+	if spec.weekdays[7] {
+		spec.weekdays[0] = true
+		delete(spec.weekdays, 7)
+	}
+
+	spec.daysRestricted = !strings.HasPrefix(fields[2], "*")
+	spec.weekdaysRestricted = !strings.HasPrefix(fields[4], "*")
+	return &spec, nil
+}
+
+func parseCronField(field string, min, max int, names map[string]int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loStr, hiStr, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseCronValue(loStr, names); err != nil {
+				return nil, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseCronValue(hiStr, names); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				// "5/15" means starting at 5, every 15
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("value out of range in %q (allowed %d-%d)", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// matchesDay reports whether the cron spec fires on the given date
+func (c *CronSpec) matchesDay(t time.Time) bool {
+	if !c.months[int(t.Month())] {
+		return false
+	}
+
+	dayMatch := c.days[t.Day()]
+	weekdayMatch := c.weekdays[int(t.Weekday())]
+	if c.daysRestricted && c.weekdaysRestricted {
+		return dayMatch || weekdayMatch
+	}
+	return dayMatch && weekdayMatch
+}
+
+// Times expands the cron spec into every firing time in [start, end], in order,
+// stopping early once limit times are found (limit <= 0 means no limit)
+func (c *CronSpec) Times(start, end time.Time, limit int) []time.Time {
+	var times []time.Time
+	loc := start.Location()
+
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !c.matchesDay(day) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if !c.hours[hour] {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !c.minutes[minute] {
+					continue
+				}
+				t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+				if t.Before(start) || t.After(end) {
+					continue
+				}
+				times = append(times, t)
+				if limit > 0 && len(times) >= limit {
+					return times
+				}
+			}
+		}
+	}
+
+	return times
+}
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"0 9 * *",
+		"0 9 * * * *",
+		"60 9 * * *",
+		"0 24 * * *",
+		"0 9 0 * *",
+		"0 9 * 13 *",
+		"0 9 * * 8",
+		"0 9 * * FOO",
+		"*/0 9 * * *",
+		"0 17-9 * * *",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseCron(expr); err == nil {
+				t.Errorf("ParseCron(%q) expected error, got nil", expr)
+			}
+		})
+	}
+}
+
+func TestCronSpec_Times(t *testing.T) {
+	start := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC) // Monday
+	end := time.Date(2025, 1, 19, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		expr  string
+		limit int
+		want  []string
+	}{
+		{
+			name: "weekdays at 9am",
+			expr: "0 9 * * MON-FRI",
+			want: []string{"2025-01-13 09:00", "2025-01-14 09:00", "2025-01-15 09:00", "2025-01-16 09:00", "2025-01-17 09:00"},
+		},
+		{
+			name:  "weekdays at 9am and 5pm with limit",
+			expr:  "0 9,17 * * 1-5",
+			limit: 3,
+			want:  []string{"2025-01-13 09:00", "2025-01-13 17:00", "2025-01-14 09:00"},
+		},
+		{
+			name: "sunday as 7",
+			expr: "30 10 * * 7",
+			want: []string{"2025-01-19 10:30"},
+		},
+		{
+			name: "step minutes",
+			expr: "*/20 9 15 jan *",
+			want: []string{"2025-01-15 09:00", "2025-01-15 09:20", "2025-01-15 09:40"},
+		},
+		{
+			name: "day-of-month OR day-of-week when both restricted",
+			expr: "0 8 18 * mon",
+			want: []string{"2025-01-13 08:00", "2025-01-18 08:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+
+			times := spec.Times(start, end, tt.limit)
+			if len(times) != len(tt.want) {
+				t.Fatalf("got %d times, want %d: %v", len(times), len(tt.want), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02 15:04"); got != tt.want[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_Cron(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate: "2025-01-13",
+		EndDate:   "2025-01-26",
+		Cron:      "0 9 * * MON,WED",
+		Interval:  types.IntervalNone, // ignored when Cron is set
+	}
+
+	times, err := newTestScheduler(config).CalculateScheduleTimes()
+	if err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+
+	want := []string{"2025-01-13", "2025-01-15", "2025-01-20", "2025-01-22"}
+	if len(times) != len(want) {
+		t.Fatalf("got %d times, want %d", len(times), len(want))
+	}
+	for i, tm := range times {
+		if got := tm.Format("2006-01-02"); got != want[i] || tm.Hour() != 9 {
+			t.Errorf("time[%d] = %s, want %s 09:00", i, tm.Format("2006-01-02 15:04"), want[i])
+		}
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_CronWindow(t *testing.T) {
+	// Without an end date, expansion stops at Slack's scheduling window
+	config := &types.ScheduleConfig{
+		StartDate: "2025-01-01",
+		Cron:      "0 12 * * *",
+	}
+
+	times, err := newTestScheduler(config).CalculateScheduleTimes()
+	if err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+	if len(times) != MaxScheduleDays {
+		t.Errorf("got %d daily times, want %d", len(times), MaxScheduleDays)
+	}
+}
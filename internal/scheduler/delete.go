@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// MatchMessages returns the scheduled messages matching every given filter: a
+// channel (name or ID; empty for all), message text (nil for any) and a time window
+func MatchMessages(client *slack.Client, st *store.Store, channel string, match TextMatcher, window ListFilter) ([]ListedMessage, error) {
+	channelID := ""
+	if channel != "" {
+		id, err := client.GetChannelID(channel)
+		if err != nil {
+			return nil, err
+		}
+		channelID = id
+	}
+
+	messages, err := ListMessages(client, st, channelID, match)
+	if err != nil {
+		return nil, err
+	}
+	return window.Filter(messages), nil
+}
+
+// WriteDeleteSummary lists the messages a delete matched
+func WriteDeleteSummary(w io.Writer, messages []ListedMessage) {
+	fmt.Fprintf(w, "Matched %d scheduled message(s):\n", len(messages))
+	for _, msg := range messages {
+		fmt.Fprintf(w, "  %s  %s  %s  %s\n", msg.SlackID, formatListTime(msg.PostAt), channelLabel(msg), oneLine(msg.Text))
+	}
+}
+
+// Confirm asks a yes/no question, defaulting to no
+func Confirm(in io.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// DeleteMessages cancels the messages in Slack, stopping at the first failure. It
+// returns the messages actually deleted.
+func DeleteMessages(client *slack.Client, messages []ListedMessage) ([]ListedMessage, error) {
+	var deleted []ListedMessage
+	for _, msg := range messages {
+		if err := client.DeleteScheduledMessage(msg.ChannelID, msg.SlackID); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, msg)
+	}
+	return deleted, nil
+}
+
+// DeleteOptions controls how a delete is confirmed
+type DeleteOptions struct {
+	// Print what would be deleted without calling the API
+	DryRun bool
+
+	// Skip the confirmation prompt
+	Yes bool
+
+	// Where the prompt reads answers and writes output
+	In  io.Reader
+	Out io.Writer
+
+	// When set, deleted messages are recorded here so Undo can restore them (the
+	// caller saves it)
+	History *store.History
+}
+
+// ConfirmedDelete summarises the messages, then deletes them unless this is a dry
+// run or the user declines. Deleting more than one message asks first unless
+// opts.Yes is set. It returns the messages deleted.
+func ConfirmedDelete(client *slack.Client, messages []ListedMessage, opts DeleteOptions) ([]ListedMessage, error) {
+	if len(messages) == 0 {
+		fmt.Fprintln(opts.Out, "No scheduled messages matched")
+		return nil, nil
+	}
+
+	WriteDeleteSummary(opts.Out, messages)
+	if opts.DryRun {
+		fmt.Fprintf(opts.Out, "Dry run: would delete %d message(s)\n", len(messages))
+		return nil, nil
+	}
+	if len(messages) > 1 && !opts.Yes {
+		if !Confirm(opts.In, opts.Out, fmt.Sprintf("Delete %d messages?", len(messages))) {
+			fmt.Fprintln(opts.Out, "Cancelled")
+			return nil, nil
+		}
+	}
+
+	deleted, err := DeleteMessages(client, messages)
+	fmt.Fprintf(opts.Out, "Deleted %d message(s)\n", len(deleted))
+	if opts.History != nil {
+		batch := types.DeletedBatch{DeletedAt: time.Now()}
+		for _, msg := range deleted {
+			batch.Messages = append(batch.Messages, types.Occurrence{
+				Channel: msg.ChannelID, Message: msg.Text, PostAt: msg.PostAt, ScheduledID: msg.SlackID,
+			})
+			if msg.Options != (types.SendOptions{}) {
+				if batch.Options == nil {
+					batch.Options = make(map[string]types.SendOptions)
+				}
+				batch.Options[msg.SlackID] = msg.Options
+			}
+		}
+		opts.History.Record(batch)
+	}
+	return deleted, err
+}
+
+// Undo re-schedules the most recently deleted batch from h with the options each
+// message was sent with, skipping messages whose time has passed. Messages leave
+// the batch as they're handled, so undoing again after a failure doesn't
+// re-create them twice; the caller saves h. It returns the re-created
+// occurrences.
+func Undo(client *slack.Client, h *store.History) ([]types.Occurrence, error) {
+	batch, ok := h.Last()
+	if !ok {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+
+	now := time.Now()
+	var restored []types.Occurrence
+	for _, occ := range batch.Messages {
+		if !occ.PostAt.After(now) {
+			slog.Info(fmt.Sprintf("Skipping past time: %s", occ.PostAt.In(LocalTZ).Format("2006-01-02 15:04 MST")), "post_at", occ.PostAt)
+			h.Remove(occ.ScheduledID)
+			continue
+		}
+
+		id, err := client.ScheduleMessage(occ.Channel, occ.Message, occ.PostAt, slack.WithSendOptions(batch.Options[occ.ScheduledID])...)
+		if err != nil {
+			return restored, err
+		}
+		h.Remove(occ.ScheduledID)
+		occ.ScheduledID = id
+		restored = append(restored, occ)
+	}
+	return restored, nil
+}
+
+// WriteTrash lists deleted messages that can be restored
+func WriteTrash(w io.Writer, trash []store.TrashedMessage) {
+	if len(trash) == 0 {
+		fmt.Fprintln(w, "Trash is empty")
+		return
+	}
+	for _, msg := range trash {
+		fmt.Fprintf(w, "%s  %s  %s  %s (deleted %s)\n", msg.ID, formatListTime(msg.PostAt), msg.Channel, oneLine(msg.Message), formatListTime(msg.DeletedAt))
+	}
+}
+
+// Restore re-schedules one deleted message from the trash, with the options it
+// was sent with, and removes it from h (the caller saves h). Messages whose time
+// has passed can't be restored.
+func Restore(client *slack.Client, h *store.History, id string) (types.Occurrence, error) {
+	msg, ok := h.Find(id)
+	if !ok {
+		return types.Occurrence{}, fmt.Errorf("no deleted message with ID %s", id)
+	}
+	if !msg.PostAt.After(time.Now()) {
+		return types.Occurrence{}, fmt.Errorf("message %s was due at %s, which has passed", id, formatListTime(msg.PostAt))
+	}
+
+	occ := msg.Occurrence
+	newID, err := client.ScheduleMessage(occ.Channel, occ.Message, occ.PostAt, slack.WithSendOptions(msg.Options)...)
+	if err != nil {
+		return types.Occurrence{}, err
+	}
+	occ.ScheduledID = newID
+	h.Remove(id)
+	return occ, nil
+}
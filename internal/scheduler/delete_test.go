@@ -0,0 +1,312 @@
+package scheduler
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+// newDeleteWorkspace returns a fake workspace with #general (C777) holding the
+// given scheduled messages
+func newDeleteWorkspace(scheduled ...ListedMessage) (*slack.FakeAPI, *slack.Client) {
+	api, client := newFakeWorkspace()
+	addChannel(api, "C777", "general")
+	for _, msg := range scheduled {
+		api.Scheduled = append(api.Scheduled, goslack.ScheduledMessage{
+			ID: msg.SlackID, Channel: msg.ChannelID, Text: msg.Text, PostAt: int(msg.PostAt.Unix()),
+		})
+	}
+	return api, client
+}
+
+// deletedIDs returns the IDs in messages that are no longer scheduled in api
+func deletedIDs(api *slack.FakeAPI, messages []ListedMessage) []string {
+	scheduled := make(map[string]bool)
+	for _, msg := range api.Scheduled {
+		scheduled[msg.ID] = true
+	}
+	var deleted []string
+	for _, msg := range messages {
+		if !scheduled[msg.SlackID] {
+			deleted = append(deleted, msg.SlackID)
+		}
+	}
+	return deleted
+}
+
+func TestMatchAndDeleteMessages(t *testing.T) {
+	at := func(m time.Month, d int) time.Time { return time.Date(2030, m, d, 9, 0, 0, 0, LocalTZ) }
+	scheduled := []ListedMessage{
+		{SlackID: "Q1", ChannelID: "C777", Text: "Standup", PostAt: at(5, 1)},
+		{SlackID: "Q2", ChannelID: "C777", Text: "Retro", PostAt: at(5, 15)},
+		{SlackID: "Q3", ChannelID: "C777", Text: "Standup", PostAt: at(6, 15)},
+	}
+	api, client := newDeleteWorkspace(scheduled...)
+
+	window, err := NewListFilter("", "2030-06-01", "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, _ := NewTextMatcher("standup", false)
+
+	matched, err := MatchMessages(client, nil, "general", match, window)
+	if err != nil {
+		t.Fatalf("MatchMessages() error = %v", err)
+	}
+	if len(matched) != 1 || matched[0].SlackID != "Q1" {
+		t.Fatalf("MatchMessages() = %+v, want only Q1", matched)
+	}
+
+	var buf bytes.Buffer
+	WriteDeleteSummary(&buf, matched)
+	if !strings.Contains(buf.String(), "Matched 1 scheduled message(s)") || !strings.Contains(buf.String(), "Q1") {
+		t.Errorf("summary = %q", buf.String())
+	}
+
+	got, err := DeleteMessages(client, matched)
+	if err != nil {
+		t.Fatalf("DeleteMessages() error = %v", err)
+	}
+	if deleted := deletedIDs(api, scheduled); len(got) != 1 || len(deleted) != 1 || deleted[0] != "Q1" {
+		t.Errorf("deleted %v (returned %d), want Q1", deleted, len(got))
+	}
+
+	if _, err := MatchMessages(client, nil, "random", nil, ListFilter{}); err == nil {
+		t.Error("MatchMessages() with unknown channel expected error")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{" yes \n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+		{"maybe\n", false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := Confirm(strings.NewReader(tt.input), &out, "Delete 2 messages?"); got != tt.want {
+			t.Errorf("Confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if out.String() != "Delete 2 messages? [y/N] " {
+			t.Errorf("prompt = %q", out.String())
+		}
+	}
+}
+
+func TestConfirmedDelete(t *testing.T) {
+	one := []ListedMessage{{SlackID: "Q1", ChannelID: "C777", Text: "Standup"}}
+	two := append(one, ListedMessage{SlackID: "Q2", ChannelID: "C777", Text: "Retro"})
+
+	tests := []struct {
+		name        string
+		messages    []ListedMessage
+		opts        DeleteOptions
+		input       string
+		wantDeleted []string
+		wantOut     []string
+	}{
+		{
+			name:     "dry run",
+			messages: two,
+			opts:     DeleteOptions{DryRun: true, Yes: true},
+			wantOut:  []string{"Matched 2", "Dry run: would delete 2 message(s)"},
+		},
+		{
+			name:        "single message needs no prompt",
+			messages:    one,
+			wantDeleted: []string{"Q1"},
+			wantOut:     []string{"Deleted 1 message(s)"},
+		},
+		{
+			name:        "confirmed",
+			messages:    two,
+			input:       "y\n",
+			wantDeleted: []string{"Q1", "Q2"},
+			wantOut:     []string{"Delete 2 messages? [y/N]", "Deleted 2 message(s)"},
+		},
+		{
+			name:     "declined",
+			messages: two,
+			input:    "\n",
+			wantOut:  []string{"Delete 2 messages? [y/N]", "Cancelled"},
+		},
+		{
+			name:        "yes skips the prompt",
+			messages:    two,
+			opts:        DeleteOptions{Yes: true},
+			wantDeleted: []string{"Q1", "Q2"},
+			wantOut:     []string{"Deleted 2 message(s)"},
+		},
+		{
+			name:    "nothing matched",
+			wantOut: []string{"No scheduled messages matched"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, client := newDeleteWorkspace(tt.messages...)
+
+			var out bytes.Buffer
+			opts := tt.opts
+			opts.In, opts.Out = strings.NewReader(tt.input), &out
+
+			got, err := ConfirmedDelete(client, tt.messages, opts)
+			if err != nil {
+				t.Fatalf("ConfirmedDelete() error = %v", err)
+			}
+			deleted := deletedIDs(api, tt.messages)
+			if len(got) != len(tt.wantDeleted) || strings.Join(deleted, ",") != strings.Join(tt.wantDeleted, ",") {
+				t.Errorf("deleted %v (returned %d), want %v", deleted, len(got), tt.wantDeleted)
+			}
+			for _, s := range tt.wantOut {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, out.String())
+				}
+			}
+			if tt.input == "" && strings.Contains(out.String(), "[y/N]") {
+				t.Errorf("unexpected prompt:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestUndo(t *testing.T) {
+	messages := []ListedMessage{
+		{SlackID: "Q1", ChannelID: "C777", Text: "Standup", PostAt: time.Now().Add(time.Hour)},
+		{SlackID: "Q2", ChannelID: "C777", Text: "Retro", PostAt: time.Now().Add(-time.Hour)},
+	}
+	api, client := newDeleteWorkspace(messages...)
+
+	h, err := store.OpenHistory(filepath.Join(t.TempDir(), store.HistoryFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Undo(client, h); err == nil {
+		t.Error("Undo() with empty history expected error")
+	}
+
+	var out bytes.Buffer
+	if _, err := ConfirmedDelete(client, messages, DeleteOptions{Yes: true, Out: &out, History: h}); err != nil {
+		t.Fatalf("ConfirmedDelete() error = %v", err)
+	}
+	if batch, ok := h.Last(); !ok || len(batch.Messages) != 2 || batch.Messages[0].Message != "Standup" || batch.Messages[0].Channel != "C777" {
+		t.Fatalf("history = %+v, want the deleted batch", batch)
+	}
+
+	restored, err := Undo(client, h)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(restored) != 1 || len(api.Scheduled) != 1 || restored[0].Message != "Standup" || restored[0].ScheduledID != api.Scheduled[0].ID {
+		t.Errorf("Undo() = %+v, want Standup re-scheduled (past Retro skipped)", restored)
+	}
+	if h.Len() != 0 {
+		t.Errorf("history has %d batch(es) after undo, want 0", h.Len())
+	}
+}
+
+func TestUndo_ThreadAfterFailure(t *testing.T) {
+	api, _ := newFakeWorkspace()
+	addChannel(api, "C777", "general")
+	failing := &failingAPI{FakeAPI: api, failAt: 4}
+	client := slack.NewClientWithAPI(failing)
+
+	// Two thread replies; the second one fails to come back the first time
+	s := New(client, &types.ScheduleConfig{
+		Name: "standup", Message: "Standup", Channel: "C777", Thread: "1736931600.000100",
+		StartDate: time.Now().AddDate(0, 0, 1).Format("2006-01-02"), SendTime: "09:00", Interval: types.IntervalDaily, RepeatCount: 2,
+	})
+	if _, err := s.Schedule(); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	st, _ := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	st.Put(s.Series(""))
+	listed, err := ListMessages(client, st, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, _ := store.OpenHistory(filepath.Join(t.TempDir(), store.HistoryFileName))
+	var out bytes.Buffer
+	if _, err := ConfirmedDelete(client, listed, DeleteOptions{Yes: true, Out: &out, History: h}); err != nil {
+		t.Fatalf("ConfirmedDelete() error = %v", err)
+	}
+
+	restored, err := Undo(client, h)
+	if err == nil || len(restored) != 1 {
+		t.Fatalf("Undo() = %d restored, %v; want 1 and an error", len(restored), err)
+	}
+	if batch, _ := h.Last(); h.Len() != 1 || len(batch.Messages) != 1 || batch.Messages[0].ScheduledID != listed[1].SlackID {
+		t.Fatalf("history after a failed undo = %+v, want only the message not restored", batch)
+	}
+
+	if restored, err = Undo(client, h); err != nil || len(restored) != 1 {
+		t.Fatalf("Undo() again = %d restored, %v", len(restored), err)
+	}
+	if len(api.Scheduled) != 2 || h.Len() != 0 {
+		t.Errorf("%d messages scheduled and %d batch(es) left, want 2 and 0", len(api.Scheduled), h.Len())
+	}
+	if len(failing.threads) != 4 {
+		t.Fatalf("%d messages scheduled in all, want 2 and 2 restored", len(failing.threads))
+	}
+	for i, thread := range failing.threads {
+		if thread != "1736931600.000100" {
+			t.Errorf("message %d scheduled in thread %q, want the original thread", i, thread)
+		}
+	}
+}
+
+func TestRestore(t *testing.T) {
+	api, client := newDeleteWorkspace()
+
+	h, _ := store.OpenHistory(filepath.Join(t.TempDir(), store.HistoryFileName))
+	h.Record(types.DeletedBatch{DeletedAt: time.Now(), Messages: []types.Occurrence{
+		{ScheduledID: "Q1", Channel: "C777", Message: "Standup", PostAt: time.Now().Add(time.Hour)},
+		{ScheduledID: "Q2", Channel: "C777", Message: "Retro", PostAt: time.Now().Add(-time.Hour)},
+	}})
+
+	var out bytes.Buffer
+	WriteTrash(&out, h.Trash())
+	if !strings.Contains(out.String(), "Q1") || !strings.Contains(out.String(), "Retro") {
+		t.Errorf("WriteTrash() = %q", out.String())
+	}
+
+	if _, err := Restore(client, h, "Q9"); err == nil {
+		t.Error("Restore() of unknown ID expected error")
+	}
+	if _, err := Restore(client, h, "Q2"); err == nil {
+		t.Error("Restore() of past message expected error")
+	}
+
+	occ, err := Restore(client, h, "Q1")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(api.Scheduled) != 1 || occ.Message != "Standup" || occ.ScheduledID != api.Scheduled[0].ID {
+		t.Errorf("Restore() = %+v, want Standup with a new ID", occ)
+	}
+	if trash := h.Trash(); len(trash) != 1 || trash[0].ID != "Q2" {
+		t.Errorf("trash after restore = %+v, want only Q2", trash)
+	}
+
+	out.Reset()
+	WriteTrash(&out, nil)
+	if out.String() != "Trash is empty\n" {
+		t.Errorf("WriteTrash(nil) = %q", out.String())
+	}
+}
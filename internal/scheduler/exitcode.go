@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"errors"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/config"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+)
+
+// Exit codes for the commands, so scripts and CI wrappers can branch on the kind
+// of failure instead of parsing output
+const (
+	ExitOK              = 0
+	ExitError           = 1 // any failure not covered below
+	ExitInvalid         = 2 // bad flags, dates, schedule files or messages
+	ExitAuth            = 3 // missing credentials, or a token Slack rejects
+	ExitChannelNotFound = 4 // the channel doesn't exist or the token can't see it
+	ExitPartial         = 5 // some messages were scheduled and others weren't
+)
+
+// InvalidError marks an error as a problem with what the user asked for rather
+// than with Slack, e.g. an unparseable date
+type InvalidError struct {
+	Err error
+}
+
+func (e *InvalidError) Error() string { return e.Err.Error() }
+
+func (e *InvalidError) Unwrap() error { return e.Err }
+
+// Invalid wraps err as an InvalidError (nil stays nil)
+func Invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &InvalidError{Err: err}
+}
+
+// PartialError is a schedule that failed part way: Scheduled messages are in
+// Slack, Failed weren't scheduled
+type PartialError struct {
+	Scheduled int
+	Failed    int
+	Err       error
+}
+
+func (e *PartialError) Error() string { return e.Err.Error() }
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+// ExitCode returns the exit code for a command that ended with err. A partial
+// failure is reported as such whatever caused it.
+func ExitCode(err error) int {
+	var partial *PartialError
+	var invalid *InvalidError
+	var fileErr ValidationError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &partial):
+		return ExitPartial
+	case errors.As(err, &invalid), errors.As(err, &fileErr):
+		return ExitInvalid
+	case errors.Is(err, config.ErrNoCredentials), slack.IsAuthError(err):
+		return ExitAuth
+	case slack.IsChannelNotFound(err):
+		return ExitChannelNotFound
+	}
+	return ExitError
+}
+
+// BatchExitCode returns the exit code for a batch or fan-out: ExitOK if every
+// entry succeeded, ExitPartial if only some did, and otherwise the code of the
+// first failure
+func BatchExitCode(results []BatchResult) int {
+	var first error
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			if first == nil {
+				first = r.Err
+			}
+		}
+	}
+	switch {
+	case failed == 0:
+		return ExitOK
+	case failed < len(results):
+		return ExitPartial
+	}
+	return ExitCode(first)
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/config"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"other", errors.New("boom"), ExitError},
+		{"invalid", Invalid(errors.New("bad date")), ExitInvalid},
+		{"schedule file", ValidationError{Path: "s.yaml", Entry: 1, Message: "no channel"}, ExitInvalid},
+		{"no credentials", fmt.Errorf("%w at /tmp", config.ErrNoCredentials), ExitAuth},
+		{"invalid token", fmt.Errorf("invalid credentials: %w", goslack.SlackErrorResponse{Err: "invalid_auth"}), ExitAuth},
+		{"missing scope", goslack.SlackErrorResponse{Err: "missing_scope"}, ExitAuth},
+		{"channel rejected", fmt.Errorf("failed: %w", goslack.SlackErrorResponse{Err: "channel_not_found"}), ExitChannelNotFound},
+		{"partial", &PartialError{Scheduled: 1, Failed: 1, Err: goslack.SlackErrorResponse{Err: "invalid_auth"}}, ExitPartial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// failingAfterAPI fails every chat.scheduleMessage call after the first n
+type failingAfterAPI struct {
+	*slack.FakeAPI
+	n int
+}
+
+func (f *failingAfterAPI) ScheduleMessageIDContext(ctx context.Context, channelID, postAt string, options ...goslack.MsgOption) (string, string, error) {
+	if f.n == 0 {
+		return "", "", goslack.SlackErrorResponse{Err: "restricted_action"}
+	}
+	f.n--
+	return f.FakeAPI.ScheduleMessageIDContext(ctx, channelID, postAt, options...)
+}
+
+func TestSchedule_ExitCodes(t *testing.T) {
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	config := func(channel, start string) *types.ScheduleConfig {
+		return &types.ScheduleConfig{
+			Message: "standup", Channel: channel, StartDate: start, SendTime: "09:00",
+			Interval: types.IntervalDaily, RepeatCount: 3,
+		}
+	}
+	run := func(api slack.API, config *types.ScheduleConfig) error {
+		s := New(slack.NewClientWithAPI(api), config)
+		s.SetClock(NewFakeClock(now))
+		_, err := s.Schedule()
+		return err
+	}
+
+	fake := slack.NewFakeAPI()
+	fake.AddChannel("general")
+	if code := ExitCode(run(fake, config("#general", "2025-01-06"))); code != ExitOK {
+		t.Errorf("success exit code = %d, want %d", code, ExitOK)
+	}
+	if code := ExitCode(run(fake, config("#general", "someday"))); code != ExitInvalid {
+		t.Errorf("bad start date exit code = %d, want %d", code, ExitInvalid)
+	}
+	if code := ExitCode(run(fake, config("#random", "2025-01-06"))); code != ExitChannelNotFound {
+		t.Errorf("unknown channel exit code = %d, want %d", code, ExitChannelNotFound)
+	}
+
+	partial := &failingAfterAPI{FakeAPI: slack.NewFakeAPI(), n: 1}
+	partial.AddChannel("general")
+	err := run(partial, config("#general", "2025-01-06"))
+	var p *PartialError
+	if !errors.As(err, &p) || p.Scheduled != 1 || p.Failed != 2 {
+		t.Fatalf("Schedule() error = %#v, want a partial failure of 1 scheduled and 2 failed", err)
+	}
+	if ExitCode(err) != ExitPartial {
+		t.Errorf("partial exit code = %d, want %d", ExitCode(err), ExitPartial)
+	}
+}
+
+func TestBatchExitCode(t *testing.T) {
+	ok := BatchResult{Name: "a"}
+	missing := BatchResult{Name: "b", Err: goslack.SlackErrorResponse{Err: "channel_not_found"}}
+	if got := BatchExitCode([]BatchResult{ok, ok}); got != ExitOK {
+		t.Errorf("all ok = %d, want %d", got, ExitOK)
+	}
+	if got := BatchExitCode([]BatchResult{ok, missing}); got != ExitPartial {
+		t.Errorf("some failed = %d, want %d", got, ExitPartial)
+	}
+	if got := BatchExitCode([]BatchResult{missing, missing}); got != ExitChannelNotFound {
+		t.Errorf("all failed = %d, want %d", got, ExitChannelNotFound)
+	}
+}
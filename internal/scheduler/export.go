@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// Export collects every message currently scheduled in Slack, with channel names and,
+// when st is non-nil, the series each one belongs to
+func Export(client *slack.Client, st *store.Store) (*types.Backup, error) {
+	messages, err := client.ListScheduledMessages("")
+	if err != nil {
+		return nil, err
+	}
+	annotated, err := annotate(client, st, slack.ToOccurrences(messages))
+	if err != nil {
+		return nil, err
+	}
+	return &types.Backup{ExportedAt: time.Now().In(LocalTZ), Messages: annotated}, nil
+}
+
+// annotate adds channel names and, when st is non-nil, owning series to scheduled
+// messages, returning them in time order
+func annotate(client *slack.Client, st *store.Store, occurrences []types.Occurrence) ([]types.BackupMessage, error) {
+	channelNames, err := client.GetChannelNameMap()
+	if err != nil {
+		return nil, err
+	}
+
+	var annotated []types.BackupMessage
+	for _, occ := range occurrences {
+		msg := types.BackupMessage{Occurrence: occ, ChannelName: channelNames[occ.Channel]}
+		if st != nil {
+			if series, ok := st.FindByScheduledID(occ.ScheduledID); ok {
+				msg.Group = series.Name
+				if series.Config.Group != "" {
+					msg.Group = series.Config.Group
+				}
+			}
+		}
+		annotated = append(annotated, msg)
+	}
+
+	sort.SliceStable(annotated, func(i, j int) bool {
+		return annotated[i].PostAt.Before(annotated[j].PostAt)
+	})
+	return annotated, nil
+}
+
+// WriteBackupJSON writes an export in the format LoadBackup reads
+func WriteBackupJSON(w io.Writer, backup *types.Backup) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(backup)
+}
+
+// ImportBackup re-creates the backup's messages that are still in the future. Channels
+// are looked up by name first so a backup can be restored into another workspace.
+// It returns the re-created messages with their new scheduled IDs.
+func ImportBackup(client *slack.Client, backup *types.Backup) ([]types.BackupMessage, error) {
+	now := time.Now()
+	var restored []types.BackupMessage
+
+	for _, msg := range backup.Messages {
+		if !msg.PostAt.After(now) {
+			slog.Info(fmt.Sprintf("Skipping past time: %s", msg.PostAt.In(LocalTZ).Format("2006-01-02 15:04 MST")), "post_at", msg.PostAt)
+			continue
+		}
+
+		channelID := msg.Channel
+		if msg.ChannelName != "" {
+			if id, err := client.GetChannelID(msg.ChannelName); err == nil {
+				channelID = id
+			}
+		}
+
+		id, err := client.ScheduleMessage(channelID, msg.Message, msg.PostAt)
+		if err != nil {
+			return restored, err
+		}
+		msg.Channel = channelID
+		msg.ScheduledID = id
+		restored = append(restored, msg)
+	}
+
+	return restored, nil
+}
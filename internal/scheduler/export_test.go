@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/config"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+func TestExport(t *testing.T) {
+	api := slack.NewFakeAPI()
+	general := api.AddChannel("general")
+	client := slack.NewFakeClient(api)
+	later, sooner := time.Now().Add(48*time.Hour).Unix(), time.Now().Add(24*time.Hour).Unix()
+	api.Scheduled = []goslack.ScheduledMessage{
+		{ID: "Q2", Channel: general, Text: "Retro", PostAt: int(later)},
+		{ID: "Q1", Channel: general, Text: "Standup", PostAt: int(sooner)},
+	}
+
+	st, _ := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	st.Put(types.Series{Name: "standup", Occurrences: []types.Occurrence{{ScheduledID: "Q1"}}})
+
+	backup, err := Export(client, st)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(backup.Messages) != 2 {
+		t.Fatalf("Export() returned %d messages, want 2", len(backup.Messages))
+	}
+	first := backup.Messages[0]
+	if first.ScheduledID != "Q1" || first.Message != "Standup" || first.ChannelName != "general" || first.Group != "standup" {
+		t.Errorf("first message = %+v, want Q1 in general, group standup", first)
+	}
+	if backup.Messages[1].Group != "" {
+		t.Errorf("untracked message has group %q", backup.Messages[1].Group)
+	}
+
+	// Round-trips through the JSON file format
+	var buf bytes.Buffer
+	if err := WriteBackupJSON(&buf, backup); err != nil {
+		t.Fatalf("WriteBackupJSON() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err := writeFile(path, buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := config.LoadBackup(path)
+	if err != nil {
+		t.Fatalf("LoadBackup() error = %v", err)
+	}
+	if len(loaded.Messages) != 2 || loaded.Messages[0].Group != "standup" || !loaded.Messages[0].PostAt.Equal(first.PostAt) {
+		t.Errorf("loaded backup = %+v, want %+v", loaded.Messages, backup.Messages)
+	}
+}
+
+func TestImportBackup(t *testing.T) {
+	api := slack.NewFakeAPI()
+	general := api.AddChannel("general")
+	client := slack.NewFakeClient(api)
+
+	backup := &types.Backup{Messages: []types.BackupMessage{
+		{Occurrence: types.Occurrence{Channel: "COLD", Message: "Past", PostAt: time.Now().Add(-time.Hour), ScheduledID: "Q0"}, ChannelName: "general"},
+		{Occurrence: types.Occurrence{Channel: "COLD", Message: "Standup", PostAt: time.Now().Add(time.Hour), ScheduledID: "Q1"}, ChannelName: "general", Group: "standup"},
+		{Occurrence: types.Occurrence{Channel: "C999", Message: "Elsewhere", PostAt: time.Now().Add(time.Hour), ScheduledID: "Q2"}},
+	}}
+
+	restored, err := ImportBackup(client, backup)
+	if err != nil {
+		t.Fatalf("ImportBackup() error = %v", err)
+	}
+	if len(restored) != 2 {
+		t.Fatalf("ImportBackup() restored %d messages, want 2 (past one skipped)", len(restored))
+	}
+	if len(api.Scheduled) != 2 || api.Scheduled[0].Channel != general || api.Scheduled[1].Channel != "C999" {
+		t.Fatalf("scheduled %+v, want one message in general and one in C999", api.Scheduled)
+	}
+	if restored[0].Channel != general || restored[0].ScheduledID != api.Scheduled[0].ID || restored[0].Group != "standup" {
+		t.Errorf("restored[0] = %+v, want channel resolved by name with new ID %s", restored[0], api.Scheduled[0].ID)
+	}
+}
+
+func writeFile(path string, data []byte) error {
+	return os.WriteFile(path, data, 0600)
+}
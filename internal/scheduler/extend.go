@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// Window returns the occurrences falling in [from, to]. A schedule with neither a
+// count nor an end date is treated as open-ended rather than as a single message.
+func (s *Scheduler) Window(from, to time.Time) ([]time.Time, error) {
+	config := *s.config
+	if config.EndDate == "" && config.RepeatCount <= 0 && config.Interval != types.IntervalNone {
+		config.EndDate = to.In(s.location()).Format("2006-01-02")
+	}
+
+	bounded := &Scheduler{client: s.client, config: &config, clock: s.clock, loc: s.loc}
+	times, err := bounded.CalculateScheduleTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	var window []time.Time
+	for _, t := range times {
+		if !t.Before(from) && !t.After(to) {
+			window = append(window, t)
+		}
+	}
+	return window, nil
+}
+
+// Extend schedules the series' occurrences after its last scheduled one, up to
+// Slack's scheduling horizon or limit new messages (limit <= 0 means no limit),
+// appending them to the series. It returns the number of messages scheduled.
+func (s *Scheduler) Extend(series *types.Series, limit int) (int, error) {
+	if series.Paused {
+		return 0, fmt.Errorf("series %s is paused (resume it first)", series.Name)
+	}
+
+	if s.config.RecipientLocal {
+		if err := s.useRecipientTimezone(); err != nil {
+			return 0, err
+		}
+	}
+
+	// Occurrences before the last scheduled one are left alone, so ones deliberately
+	// removed from Slack aren't brought back
+	now := s.currentTime()
+	from := now
+	if last, ok := lastOccurrence(series); ok && !last.Before(now) {
+		from = last.Add(time.Second)
+	}
+
+	times, err := s.Window(from, now.AddDate(0, 0, MaxScheduleDays))
+	if err != nil {
+		return 0, err
+	}
+	if limit > 0 && len(times) > limit {
+		times = times[:limit]
+	}
+
+	channelID, err := s.client.GetChannelID(s.config.Channel)
+	if err != nil {
+		return 0, err
+	}
+	// Occurrences continue the series' numbering; open-ended series have no total
+	seen := series.Pruned
+	for _, occ := range series.Occurrences {
+		if !occ.Extra && !occ.Continuation {
+			seen++
+		}
+	}
+
+	scheduled := 0
+	for i, t := range times {
+		text, err := s.textAt(t, seen+i+1, 0)
+		if err != nil {
+			return scheduled, err
+		}
+
+		slog.Info(fmt.Sprintf("[%s] Scheduling message for: %s", series.Name, t.Format("2006-01-02 15:04 MST")), "series", series.Name, "post_at", t)
+		occurrences, err := s.scheduleText(channelID, text, t, seen+i+1)
+		series.Occurrences = append(series.Occurrences, occurrences...)
+		if err != nil {
+			return scheduled, err
+		}
+		scheduled++
+	}
+
+	return scheduled, nil
+}
+
+// lastOccurrence returns the latest post time recorded for the series' recurrence
+// (one-off extras don't count)
+func lastOccurrence(series *types.Series) (time.Time, bool) {
+	var last time.Time
+	for _, occ := range series.Occurrences {
+		if !occ.Extra && !occ.Continuation && occ.PostAt.After(last) {
+			last = occ.PostAt
+		}
+	}
+	return last, !last.IsZero()
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestScheduler_Window(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, LocalTZ)
+	to := time.Date(2025, 3, 31, 23, 59, 0, 0, LocalTZ)
+
+	tests := []struct {
+		name   string
+		config *types.ScheduleConfig
+		want   int
+	}{
+		{
+			name: "open-ended weekly series",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-03", SendTime: "09:00", Interval: types.IntervalWeekly,
+			},
+			want: 4, // Fridays in March 2025
+		},
+		{
+			name: "counted series ends before the window",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-03", SendTime: "09:00", Interval: types.IntervalWeekly, RepeatCount: 4,
+			},
+			want: 0,
+		},
+		{
+			name: "end date inside the window",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-03", SendTime: "09:00", Interval: types.IntervalWeekly, EndDate: "2025-03-15",
+			},
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).Window(from, to)
+			if err != nil {
+				t.Fatalf("Window() error = %v", err)
+			}
+			if len(times) != tt.want {
+				t.Errorf("Window() returned %d times, want %d: %v", len(times), tt.want, times)
+			}
+		})
+	}
+}
+
+func TestScheduler_Extend(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, LocalTZ)
+	config := &types.ScheduleConfig{
+		Message: "Monthly report", Channel: "C123",
+		StartDate: "2024-06-01", SendTime: "09:00", Interval: types.IntervalMonthly,
+	}
+	series := &types.Series{Name: "report", Config: *config, Managed: true}
+
+	s := New(client, config)
+	clock := NewFakeClock(now)
+	s.SetClock(clock)
+
+	// Feb 1 through May 1 fall inside the 120-day window (Jan 1 09:00 has passed)
+	n, err := s.Extend(series, 0)
+	if err != nil {
+		t.Fatalf("Extend() error = %v", err)
+	}
+	if n != 4 || len(series.Occurrences) != 4 || scheduleCalls(api) != 4 {
+		t.Fatalf("Extend() scheduled %d (%d recorded, %d API calls), want 4", n, len(series.Occurrences), scheduleCalls(api))
+	}
+	if got := series.Occurrences[0].PostAt.Format("2006-01-02 15:04"); got != "2025-02-01 09:00" {
+		t.Errorf("first occurrence = %s, want 2025-02-01 09:00", got)
+	}
+
+	// A month later only the newly-entered occurrence is scheduled
+	clock.Set(now.AddDate(0, 1, 0))
+	if n, err = s.Extend(series, 0); err != nil || n != 1 {
+		t.Errorf("second Extend() = %d, %v, want 1 new occurrence", n, err)
+	}
+	if got := series.Occurrences[len(series.Occurrences)-1].PostAt.Format("2006-01-02"); got != "2025-06-01" {
+		t.Errorf("newest occurrence = %s, want 2025-06-01", got)
+	}
+}
+
+func TestScheduler_Extend_Limit(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	config := &types.ScheduleConfig{
+		Message: "Standup", Channel: "C123",
+		StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalDaily,
+	}
+	// Jan 6-7 are already scheduled; Jan 8's occurrence was removed and is not tracked,
+	// but is before the last one so it stays removed
+	series := &types.Series{Name: "standup", Config: *config, Occurrences: []types.Occurrence{
+		{PostAt: time.Date(2025, 1, 6, 9, 0, 0, 0, LocalTZ), ScheduledID: "Q1"},
+		{PostAt: time.Date(2025, 1, 9, 9, 0, 0, 0, LocalTZ), ScheduledID: "Q2"},
+	}}
+
+	s := New(client, config)
+	s.SetClock(NewFakeClock(now))
+
+	n, err := s.Extend(series, 3)
+	if err != nil {
+		t.Fatalf("Extend() error = %v", err)
+	}
+	if n != 3 || scheduleCalls(api) != 3 {
+		t.Fatalf("Extend() scheduled %d (%d API calls), want 3", n, scheduleCalls(api))
+	}
+
+	var got []string
+	for _, occ := range series.Occurrences[2:] {
+		got = append(got, occ.PostAt.Format("2006-01-02"))
+	}
+	want := []string{"2025-01-10", "2025-01-11", "2025-01-12"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("new occurrence[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
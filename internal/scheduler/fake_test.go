@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/notify"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+// newFakeWorkspace returns an in-memory workspace holding the given channels,
+// which the token is a member of, and a client backed by it
+func newFakeWorkspace(channelIDs ...string) (*slack.FakeAPI, *slack.Client) {
+	api := slack.NewFakeAPI()
+	for _, id := range channelIDs {
+		addChannel(api, id, strings.ToLower(id))
+	}
+	return api, slack.NewFakeClient(api)
+}
+
+// addChannel adds a channel with a fixed ID to api, which the token is a
+// member of
+func addChannel(api *slack.FakeAPI, id, name string) {
+	var ch goslack.Channel
+	ch.ID = id
+	ch.Name = name
+	ch.IsChannel = true
+	ch.IsMember = true
+	api.Channels = append(api.Channels, ch)
+}
+
+// seedScheduled adds the scheduled occurrences to api, as if an earlier run had
+// scheduled them
+func seedScheduled(api *slack.FakeAPI, occurrences []types.Occurrence) {
+	for _, occ := range occurrences {
+		if occ.ScheduledID == "" {
+			continue
+		}
+		api.Scheduled = append(api.Scheduled, goslack.ScheduledMessage{
+			ID: occ.ScheduledID, Channel: occ.Channel, PostAt: int(occ.PostAt.Unix()), Text: occ.Message,
+		})
+	}
+}
+
+// scheduleCalls counts the chat.scheduleMessage calls made to api
+func scheduleCalls(api *slack.FakeAPI) int {
+	return api.Calls["chat.scheduleMessage"]
+}
+
+// TestScheduleListDelete_FakeAPI runs a schedule through listing and deleting
+// against an in-memory workspace
+func TestScheduleListDelete_FakeAPI(t *testing.T) {
+	api := slack.NewFakeAPI()
+	general := api.AddChannel("general")
+	client := slack.NewFakeClient(api)
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	s := New(client, &types.ScheduleConfig{
+		Message: "standup", Channel: "#general", StartDate: "2025-01-06", SendTime: "09:00",
+		Interval: types.IntervalDaily, RepeatCount: 3,
+	})
+	s.SetClock(NewFakeClock(now))
+	if _, err := s.Schedule(); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if len(api.Scheduled) != 3 || api.Scheduled[0].Channel != general || api.Scheduled[0].Text != "standup" {
+		t.Fatalf("scheduled %+v, want 3 standups in #general", api.Scheduled)
+	}
+
+	listed, err := MatchMessages(client, nil, "general", nil, ListFilter{})
+	if err != nil {
+		t.Fatalf("MatchMessages() error = %v", err)
+	}
+	if len(listed) != 3 || listed[0].ChannelName != "general" {
+		t.Fatalf("MatchMessages() = %+v, want the 3 standups", listed)
+	}
+
+	var out bytes.Buffer
+	deleted, err := ConfirmedDelete(client, listed, DeleteOptions{In: strings.NewReader("y\n"), Out: &out})
+	if err != nil {
+		t.Fatalf("ConfirmedDelete() error = %v", err)
+	}
+	if len(deleted) != 3 || len(api.Scheduled) != 0 {
+		t.Errorf("deleted %d, %d left in Slack; want 3 and 0", len(deleted), len(api.Scheduled))
+	}
+
+	// Re-running the same schedule doesn't double it
+	s = New(client, s.config)
+	s.SetClock(NewFakeClock(now))
+	s.Schedule()
+	s.Schedule()
+	if len(api.Scheduled) != 3 {
+		t.Errorf("%d messages scheduled after running twice, want 3", len(api.Scheduled))
+	}
+}
+
+// recordingNotifier keeps the events it is told about
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (r *recordingNotifier) Notify(event notify.Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestScheduler_Schedule_Notifies(t *testing.T) {
+	api := slack.NewFakeAPI()
+	api.AddChannel("general")
+	client := slack.NewFakeClient(api)
+	config := &types.ScheduleConfig{
+		Name: "standup", Message: "standup", Channel: "#general", StartDate: "2025-01-06", SendTime: "09:00",
+		Interval: types.IntervalDaily, RepeatCount: 3,
+	}
+	var notifier recordingNotifier
+	newScheduler := func() *Scheduler {
+		s := New(client, config)
+		s.SetClock(NewFakeClock(time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)))
+		s.SetNotifier(&notifier)
+		return s
+	}
+
+	s := newScheduler()
+	s.SetDryRun(true)
+	s.Schedule()
+	if len(notifier.events) != 0 {
+		t.Errorf("dry run sent %+v, want nothing", notifier.events)
+	}
+
+	newScheduler().Schedule()
+	if len(notifier.events) != 1 || notifier.events[0].Type != notify.EventScheduled || notifier.events[0].Count != 3 || notifier.events[0].Series != "standup" {
+		t.Fatalf("events = %+v, want one scheduled event for 3 messages", notifier.events)
+	}
+
+	// Everything is already scheduled, so there is nothing to report
+	newScheduler().Schedule()
+	if len(notifier.events) != 1 {
+		t.Errorf("events after a no-op run = %+v", notifier.events)
+	}
+
+	config.AllowDuplicates = true
+	api.Errors = map[string]error{"chat.scheduleMessage": fmt.Errorf("not_in_channel")}
+	newScheduler().Schedule()
+	if len(notifier.events) != 2 || notifier.events[1].Type != notify.EventScheduleFailed || !strings.Contains(notifier.events[1].Error, "not_in_channel") {
+		t.Errorf("events after a failure = %+v, want a schedule_failed event", notifier.events)
+	}
+}
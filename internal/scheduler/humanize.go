@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// Relative describes t relative to now, e.g. "in 3 days" or "2 hours ago". Each
+// value is rounded to its unit and rolls over into the next unit, so 59m40s is
+// "in 1 hour" rather than "in 60 minutes".
+func Relative(t, now time.Time) string {
+	d := t.Sub(now)
+	past := d < 0
+	if past {
+		d = -d
+	}
+	if d < time.Minute {
+		return "now"
+	}
+
+	var amount string
+	if m := roundUnits(d, time.Minute); m < 60 {
+		amount = plural(m, "minute")
+	} else if h := roundUnits(d, time.Hour); h < 24 {
+		amount = plural(h, "hour")
+	} else if days := roundUnits(d, 24*time.Hour); days < 60 {
+		amount = plural(days, "day")
+	} else {
+		amount = plural((days+15)/30, "month")
+	}
+
+	if past {
+		return amount + " ago"
+	}
+	return "in " + amount
+}
+
+func roundUnits(d, unit time.Duration) int {
+	return int((d + unit/2) / unit)
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelative(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		d    time.Duration
+		want string
+	}{
+		{"same instant", 0, "now"},
+		{"seconds ahead", 59 * time.Second, "now"},
+		{"seconds ago", -30 * time.Second, "now"},
+		{"one minute", time.Minute, "in 1 minute"},
+		{"minutes", 5*time.Minute + 20*time.Second, "in 5 minutes"},
+		{"rolls over to an hour", 59*time.Minute + 40*time.Second, "in 1 hour"},
+		{"hours", 2*time.Hour + 10*time.Minute, "in 2 hours"},
+		{"rounds hours", 2*time.Hour + 40*time.Minute, "in 3 hours"},
+		{"rolls over to a day", 23*time.Hour + 40*time.Minute, "in 1 day"},
+		{"days", 3 * 24 * time.Hour, "in 3 days"},
+		{"months", 90 * 24 * time.Hour, "in 3 months"},
+		{"minutes ago", -10 * time.Minute, "10 minutes ago"},
+		{"day ago", -25 * time.Hour, "1 day ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Relative(now.Add(tt.d), now); got != tt.want {
+				t.Errorf("Relative(%v) = %q, want %q", tt.d, got, tt.want)
+			}
+		})
+	}
+}
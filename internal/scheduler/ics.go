@@ -0,0 +1,425 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// icsTimeFormat is an iCalendar UTC date-time
+const icsTimeFormat = "20060102T150405Z"
+
+// maxSummaryLength caps event titles; the full message goes in the description
+const maxSummaryLength = 60
+
+// WriteBackupICS writes an export as an iCalendar file with one event per scheduled
+// message, so the posts can be subscribed to from a calendar app
+func WriteBackupICS(w io.Writer, backup *types.Backup) error {
+	bw := bufio.NewWriter(w)
+	write := func(name, value string) {
+		writeICSLine(bw, name+":"+value)
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", "-//slack-recurring-messages-scheduler//EN")
+	write("CALSCALE", "GREGORIAN")
+	for _, msg := range backup.Messages {
+		channel := msg.Channel
+		if msg.ChannelName != "" {
+			channel = "#" + msg.ChannelName
+		}
+
+		write("BEGIN", "VEVENT")
+		write("UID", icsUID(msg))
+		write("DTSTAMP", backup.ExportedAt.UTC().Format(icsTimeFormat))
+		write("DTSTART", msg.PostAt.UTC().Format(icsTimeFormat))
+		write("SUMMARY", escapeICSText(fmt.Sprintf("%s: %s", channel, eventTitle(msg.Message))))
+		write("DESCRIPTION", escapeICSText(msg.Message))
+		write("LOCATION", escapeICSText(channel))
+		if msg.Group != "" {
+			write("CATEGORIES", escapeICSText(msg.Group))
+		}
+		write("END", "VEVENT")
+	}
+	write("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// icsUID identifies an event stably across exports, so re-subscribing updates
+// events rather than duplicating them
+func icsUID(msg types.BackupMessage) string {
+	id := msg.ScheduledID
+	if id == "" {
+		id = fmt.Sprintf("%s-%d", msg.Channel, msg.PostAt.Unix())
+	}
+	return id + "@slack-scheduler"
+}
+
+// eventTitle returns the first line of a message, shortened for an event title
+func eventTitle(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if utf8.RuneCountInString(line) <= maxSummaryLength {
+		return line
+	}
+	runes := []rune(line)
+	return string(runes[:maxSummaryLength-1]) + "…"
+}
+
+// escapeICSText escapes a TEXT property value (RFC 5545 3.3.11)
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeICSLine writes a content line, folding it at 75 octets without splitting
+// a UTF-8 character
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// ICSEvent is a calendar event read from an iCalendar file
+type ICSEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+
+	// Start of the event (midnight for all-day events)
+	Start  time.Time
+	AllDay bool
+
+	// Recurrence rule (empty for single events) and excluded instances, including
+	// instances overridden by a separate event with a RECURRENCE-ID
+	RRule   string
+	ExDates []time.Time
+}
+
+// ICSImportOptions controls how calendar events become scheduled messages
+type ICSImportOptions struct {
+	// Channel name or ID every message is posted to
+	Channel string
+
+	// Where message text comes from: "summary" (default), "description", or a
+	// template using {summary}, {description} and {location}
+	MessageFrom string
+
+	// Send time (HH:MM) for all-day events (default 09:00)
+	AllDayTime string
+
+	// Name of the series the messages are recorded as (default derived from the
+	// channel and first instance)
+	Name string
+
+	// Store the imported series is recorded in (nil for none; the caller saves it)
+	Store *store.Store
+
+	// Clock the scheduling window is measured from (the system clock if nil)
+	Clock Clock
+}
+
+// ParseICS reads the events of an iCalendar file. Times without a timezone are
+// interpreted in loc.
+func ParseICS(r io.Reader, loc *time.Location) ([]ICSEvent, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ICSEvent
+	var overrides []ICSEvent
+	var current *ICSEvent
+	var isOverride bool
+	for n, line := range lines {
+		name, params, value, ok := parseICSLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current, isOverride = &ICSEvent{}, false
+			continue
+		case name == "END" && value == "VEVENT" && current != nil:
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", current.Summary)
+			}
+			if isOverride {
+				overrides = append(overrides, *current)
+			} else {
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		case current == nil:
+			continue
+		}
+
+		switch name {
+		case "UID":
+			current.UID = value
+		case "SUMMARY":
+			current.Summary = unescapeICSText(value)
+		case "DESCRIPTION":
+			current.Description = unescapeICSText(value)
+		case "LOCATION":
+			current.Location = unescapeICSText(value)
+		case "DTSTART":
+			current.Start, current.AllDay, err = parseICSTime(value, params, loc)
+		case "RRULE":
+			current.RRule = value
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, _, perr := parseICSTime(v, params, loc)
+				if perr != nil {
+					err = perr
+					break
+				}
+				current.ExDates = append(current.ExDates, t)
+			}
+		case "RECURRENCE-ID":
+			var t time.Time
+			t, _, err = parseICSTime(value, params, loc)
+			current.ExDates, isOverride = []time.Time{t}, true
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+
+	// An overridden instance is dropped from its series and added as its own event
+	for _, o := range overrides {
+		for i := range events {
+			if events[i].UID == o.UID && events[i].RRule != "" {
+				events[i].ExDates = append(events[i].ExDates, o.ExDates...)
+			}
+		}
+		o.ExDates = nil
+		events = append(events, o)
+	}
+	return events, nil
+}
+
+// unfoldICS splits an iCalendar file into content lines, joining folded lines
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseICSLine splits "NAME;PARAM=x:value" into its parts
+func parseICSLine(line string) (name string, params map[string]string, value string, ok bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", nil, "", false
+	}
+	parts := strings.Split(head, ";")
+	params = make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value, true
+}
+
+// parseICSTime parses a DATE or DATE-TIME value, honouring TZID
+func parseICSTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		tz, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown timezone %q", tzid)
+		}
+		loc = tz
+	}
+
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsTimeFormat, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t.In(loc), false, nil
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, false, nil
+}
+
+// unescapeICSText reverses escapeICSText
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// Times returns the event's instances in [from, to]. All-day events are placed
+// at clock (HH:MM).
+func (e ICSEvent) Times(from, to time.Time, clock string) ([]time.Time, error) {
+	start := e.Start
+	if e.AllDay {
+		c, err := time.Parse("15:04", clock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time: %s (use HH:MM, 24-hour)", clock)
+		}
+		start = time.Date(start.Year(), start.Month(), start.Day(), c.Hour(), c.Minute(), 0, 0, start.Location())
+	}
+
+	candidates := []time.Time{start}
+	if e.RRule != "" {
+		rule, err := ParseRRule(e.RRule, start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("event %q: %w", e.Summary, err)
+		}
+		candidates = rule.Times(start, &to, 0)
+	}
+
+	var times []time.Time
+	for _, t := range candidates {
+		if t.Before(from) || t.After(to) || e.excluded(t) {
+			continue
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// excluded reports whether t is an EXDATE; all-day exclusions match the whole day
+func (e ICSEvent) excluded(t time.Time) bool {
+	for _, ex := range e.ExDates {
+		if ex.Equal(t) {
+			return true
+		}
+		if e.AllDay && ex.Year() == t.Year() && ex.YearDay() == t.YearDay() {
+			return true
+		}
+	}
+	return false
+}
+
+// ICSMessage builds message text from an event
+func ICSMessage(e ICSEvent, from string) string {
+	switch from {
+	case "", "summary":
+		return e.Summary
+	case "description":
+		return e.Description
+	}
+	return strings.NewReplacer("{summary}", e.Summary, "{description}", e.Description, "{location}", e.Location).Replace(from)
+}
+
+// ImportICS schedules a message for every event instance within Slack's scheduling
+// window, returning the scheduled occurrences in time order. Instances whose
+// message text comes out empty are skipped. The messages carry series metadata
+// and, when opts.Store is set, are recorded there as one series, as far as
+// scheduling got.
+func ImportICS(client *slack.Client, events []ICSEvent, opts ICSImportOptions) ([]types.Occurrence, error) {
+	if opts.AllDayTime == "" {
+		opts.AllDayTime = "09:00"
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock{}
+	}
+	channelID, err := client.GetChannelID(opts.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	now := opts.Clock.Now()
+	maxFuture := now.AddDate(0, 0, MaxScheduleDays)
+
+	var pending []types.Occurrence
+	for _, e := range events {
+		text := strings.TrimSpace(ICSMessage(e, opts.MessageFrom))
+		if text == "" {
+			slog.Info(fmt.Sprintf("Skipping event with empty message: %s", e.Summary), "event", e.UID)
+			continue
+		}
+		times, err := e.Times(now.Add(time.Minute), maxFuture, opts.AllDayTime)
+		if err != nil {
+			return nil, err
+		}
+		if len(times) == 0 {
+			slog.Info(fmt.Sprintf("Skipping event with no instances in Slack's scheduling window: %s", e.Summary), "event", e.UID)
+		}
+		for _, t := range times {
+			pending = append(pending, types.Occurrence{Channel: channelID, Message: text, PostAt: t})
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].PostAt.Before(pending[j].PostAt) })
+
+	config := types.ScheduleConfig{
+		Name: opts.Name, Channel: opts.Channel, Interval: types.IntervalNone,
+		StartDate: pending[0].PostAt.In(opts.Clock.Location()).Format("2006-01-02"),
+	}
+	if config.Name == "" {
+		config.Name = store.DefaultName(&config)
+	}
+	if opts.Store != nil {
+		config.Name = opts.Store.UniqueName(config.Name)
+	}
+	hash := types.ConfigHash(config)
+
+	var scheduled []types.Occurrence
+	for i, occ := range pending {
+		slog.Info(fmt.Sprintf("Scheduling message for: %s", occ.PostAt.Format("2006-01-02 15:04 MST")), "post_at", occ.PostAt)
+		id, err := client.ScheduleMessage(occ.Channel, occ.Message, occ.PostAt, slack.WithMetadata(types.OccurrenceMetadata(nil, config.Name, i+1, hash)))
+		if err != nil {
+			recordImport(opts.Store, config, scheduled, now)
+			return scheduled, err
+		}
+		occ.ScheduledID = id
+		scheduled = append(scheduled, occ)
+	}
+	recordImport(opts.Store, config, scheduled, now)
+	return scheduled, nil
+}
+
+// recordImport puts the imported occurrences into st as a series, if there are any
+func recordImport(st *store.Store, config types.ScheduleConfig, occurrences []types.Occurrence, now time.Time) {
+	if st == nil || len(occurrences) == 0 {
+		return
+	}
+	st.Put(types.Series{Name: config.Name, CreatedAt: now, Config: config, Occurrences: occurrences})
+}
@@ -0,0 +1,258 @@
+package scheduler
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestWriteBackupICS(t *testing.T) {
+	backup := &types.Backup{
+		ExportedAt: time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC),
+		Messages: []types.BackupMessage{
+			{
+				Occurrence:  types.Occurrence{Channel: "C123", Message: "Standup, please; post updates\nsecond line", PostAt: time.Date(2025, 1, 13, 9, 0, 0, 0, LocalTZ), ScheduledID: "Q1"},
+				ChannelName: "general",
+				Group:       "standup",
+			},
+			{
+				Occurrence: types.Occurrence{Channel: "C456", Message: strings.Repeat("long ", 30), PostAt: time.Date(2025, 1, 14, 9, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBackupICS(&buf, backup); err != nil {
+		t.Fatalf("WriteBackupICS() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:Q1@slack-scheduler\r\n",
+		"DTSTAMP:20250110T120000Z\r\n",
+		"DTSTART:" + backup.Messages[0].PostAt.UTC().Format(icsTimeFormat) + "\r\n",
+		`SUMMARY:#general: Standup\, please\; post updates` + "\r\n",
+		`DESCRIPTION:Standup\, please\; post updates\nsecond line` + "\r\n",
+		"CATEGORIES:standup\r\n",
+		"UID:C456-1736845200@slack-scheduler\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("got %d events, want 2", n)
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line not folded (%d octets): %q", len(line), line)
+		}
+	}
+}
+
+func TestWriteICSLineFoldsOnRuneBoundary(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	writeICSLine(w, "DESCRIPTION:"+strings.Repeat("é", 100))
+	w.Flush()
+
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	if unfolded != "DESCRIPTION:"+strings.Repeat("é", 100)+"\r\n" {
+		t.Errorf("unfolded line = %q", unfolded)
+	}
+}
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"DTSTART;TZID=America/New_York:20250106T093000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6\r\n" +
+	"EXDATE;TZID=America/New_York:20250108T093000\r\n" +
+	"SUMMARY:Daily standup\\, team A\r\n" +
+	"DESCRIPTION:Post your updates in thread\\nthanks\r\n" +
+	" !\r\n" +
+	"LOCATION:Room 1\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"RECURRENCE-ID;TZID=America/New_York:20250113T093000\r\n" +
+	"DTSTART;TZID=America/New_York:20250113T110000\r\n" +
+	"SUMMARY:Standup (moved)\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20250120\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review@example.com\r\n" +
+	"DTSTART:20250115T170000Z\r\n" +
+	"SUMMARY:Review\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+
+	events, err := ParseICS(strings.NewReader(testCalendar), time.UTC)
+	if err != nil {
+		t.Fatalf("ParseICS() error = %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("ParseICS() returned %d events, want 4", len(events))
+	}
+
+	standup := events[0]
+	if standup.Summary != "Daily standup, team A" || standup.Description != "Post your updates in thread\nthanks!" {
+		t.Errorf("standup text = %q / %q", standup.Summary, standup.Description)
+	}
+	if !standup.Start.Equal(time.Date(2025, 1, 6, 9, 30, 0, 0, ny)) {
+		t.Errorf("standup start = %v", standup.Start)
+	}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	times, err := standup.Times(from, to, "09:00")
+	if err != nil {
+		t.Fatalf("Times() error = %v", err)
+	}
+	// Mon 6, (Wed 8 excluded), (Mon 13 overridden), Wed 15, Mon 20, Wed 22
+	wantDays := []int{6, 15, 20, 22}
+	if len(times) != len(wantDays) {
+		t.Fatalf("Times() = %v, want days %v", times, wantDays)
+	}
+	for i, day := range wantDays {
+		if times[i].Day() != day || times[i].Hour() != 9 || times[i].Minute() != 30 {
+			t.Errorf("times[%d] = %v, want Jan %d 09:30", i, times[i], day)
+		}
+	}
+
+	moved := events[3]
+	if moved.Summary != "Standup (moved)" || moved.Start.Hour() != 11 || len(moved.ExDates) != 0 {
+		t.Errorf("override event = %+v", moved)
+	}
+
+	holiday := events[1]
+	times, err = holiday.Times(from, to, "08:15")
+	if err != nil {
+		t.Fatalf("Times() error = %v", err)
+	}
+	if !holiday.AllDay || len(times) != 1 || times[0].Hour() != 8 || times[0].Minute() != 15 {
+		t.Errorf("all-day times = %v, want Jan 20 08:15", times)
+	}
+
+	if got := events[2].Start.UTC(); got != time.Date(2025, 1, 15, 17, 0, 0, 0, time.UTC) {
+		t.Errorf("UTC start = %v", got)
+	}
+}
+
+func TestParseICS_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		ics  string
+	}{
+		{"missing start", "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n"},
+		{"bad date", "BEGIN:VEVENT\nDTSTART:2025-01-01\nEND:VEVENT\n"},
+		{"unknown timezone", "BEGIN:VEVENT\nDTSTART;TZID=Nowhere/City:20250101T090000\nEND:VEVENT\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseICS(strings.NewReader(tt.ics), time.UTC); err == nil {
+				t.Error("ParseICS() expected error")
+			}
+		})
+	}
+}
+
+func TestICSMessage(t *testing.T) {
+	event := ICSEvent{Summary: "Retro", Description: "Bring notes", Location: "Zoom"}
+	tests := []struct {
+		from string
+		want string
+	}{
+		{"", "Retro"},
+		{"summary", "Retro"},
+		{"description", "Bring notes"},
+		{":calendar: {summary} in {location}: {description}", ":calendar: Retro in Zoom: Bring notes"},
+	}
+	for _, tt := range tests {
+		if got := ICSMessage(event, tt.from); got != tt.want {
+			t.Errorf("ICSMessage(%q) = %q, want %q", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestImportICS(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Truncate(time.Minute)
+	events := []ICSEvent{
+		// Daily for 5 days starting tomorrow
+		{Summary: "Standup", Start: tomorrow, RRule: "FREQ=DAILY;COUNT=5"},
+		// Past single event
+		{Summary: "Old", Start: time.Now().AddDate(0, 0, -1)},
+		// Beyond Slack's window
+		{Summary: "Far", Start: time.Now().AddDate(0, 0, MaxScheduleDays+5)},
+		// No description to use
+		{Summary: "Empty", Start: tomorrow.Add(time.Hour)},
+	}
+
+	got, err := ImportICS(client, events, ICSImportOptions{Channel: "C123", MessageFrom: "{summary}!"})
+	if err != nil {
+		t.Fatalf("ImportICS() error = %v", err)
+	}
+	if len(got) != 6 || scheduleCalls(api) != 6 {
+		t.Fatalf("ImportICS() scheduled %d (%d calls), want 6", len(got), scheduleCalls(api))
+	}
+	if got[0].Message != "Standup!" || got[0].Channel != "C123" || got[0].ScheduledID == "" {
+		t.Errorf("first occurrence = %+v", got[0])
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].PostAt.Before(got[i-1].PostAt) {
+			t.Errorf("occurrences not in time order: %v", got)
+		}
+	}
+
+	api.Calls = nil
+	got, err = ImportICS(client, events[3:], ICSImportOptions{Channel: "C123", MessageFrom: "description"})
+	if err != nil || len(got) != 0 || scheduleCalls(api) != 0 {
+		t.Errorf("empty description: got %d scheduled, err %v", len(got), err)
+	}
+}
+
+func TestImportICS_OldEvents(t *testing.T) {
+	_, client := newFakeWorkspace("C123")
+	st, _ := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	// Both started well over 10 years ago
+	events := []ICSEvent{
+		{Summary: "Birthday", Start: time.Date(1990, 1, 10, 9, 0, 0, 0, LocalTZ), RRule: "FREQ=YEARLY"},
+		{Summary: "Standup", Start: time.Date(2010, 1, 4, 9, 0, 0, 0, LocalTZ), RRule: "FREQ=WEEKLY;BYDAY=MO"},
+	}
+	got, err := ImportICS(client, events, ICSImportOptions{Channel: "C123", Name: "calendar", Store: st, Clock: NewFakeClock(now)})
+	if err != nil {
+		t.Fatalf("ImportICS() error = %v", err)
+	}
+	// 18 Mondays from Jan 6 within the 120-day window, and the birthday
+	if len(got) != 19 || got[0].Message != "Standup" || !got[0].PostAt.Equal(now.Add(time.Hour)) || got[1].Message != "Birthday" {
+		t.Fatalf("ImportICS() = %d occurrences starting %+v, want the 18 upcoming standups and the birthday", len(got), got[:2])
+	}
+
+	series, ok := st.Get("calendar")
+	if !ok || len(series.Occurrences) != len(got) || series.Config.Channel != "C123" || series.Occurrences[0].ScheduledID != got[0].ScheduledID {
+		t.Errorf("stored series = %+v, %v, want the imported occurrences", series, ok)
+	}
+}
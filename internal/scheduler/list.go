@@ -0,0 +1,390 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// ListedMessage is one scheduled message as reported by list
+type ListedMessage struct {
+	// 1-based position in the listing
+	Index int `json:"index"`
+
+	// Series the message belongs to in the local store (empty if untracked)
+	Group string `json:"group,omitempty"`
+
+	SlackID     string    `json:"slack_id"`
+	ChannelID   string    `json:"channel_id"`
+	ChannelName string    `json:"channel_name,omitempty"`
+	Text        string    `json:"text"`
+	PostAt      time.Time `json:"post_at"`
+
+	// Options the message was sent with, known only for messages of a series
+	Options types.SendOptions `json:"-"`
+}
+
+// ListMessages returns the messages scheduled in a channel (all channels if
+// channelID is empty) in time order, annotated like Export. When match is non-nil
+// only messages whose text it accepts are listed, and indexes count only those.
+func ListMessages(client *slack.Client, st *store.Store, channelID string, match TextMatcher) ([]ListedMessage, error) {
+	messages, err := client.ListScheduledMessages(channelID)
+	if err != nil {
+		return nil, err
+	}
+	annotated, err := annotate(client, st, slack.ToOccurrences(messages))
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make([]ListedMessage, 0, len(annotated))
+	for _, msg := range annotated {
+		if match != nil && !match(msg.Message) {
+			continue
+		}
+		listedMsg := ListedMessage{
+			Index:       len(listed) + 1,
+			Group:       msg.Group,
+			SlackID:     msg.ScheduledID,
+			ChannelID:   msg.Channel,
+			ChannelName: msg.ChannelName,
+			Text:        msg.Message,
+			PostAt:      msg.PostAt,
+		}
+		if st != nil {
+			if series, ok := st.FindByScheduledID(msg.ScheduledID); ok {
+				listedMsg.Options = seriesSendOptions(series, msg.ScheduledID)
+			}
+		}
+		listed = append(listed, listedMsg)
+	}
+	return listed, nil
+}
+
+// TextMatcher selects messages by their text
+type TextMatcher func(text string) bool
+
+// NewTextMatcher matches a case-insensitive substring, or a regular expression
+// when regex is set
+func NewTextMatcher(pattern string, regex bool) (TextMatcher, error) {
+	if !regex {
+		needle := strings.ToLower(pattern)
+		return func(text string) bool {
+			return strings.Contains(strings.ToLower(text), needle)
+		}, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --grep pattern: %w", err)
+	}
+	return re.MatchString, nil
+}
+
+// WriteListJSON writes a listing as a JSON array (RFC 3339 times), for scripting
+func WriteListJSON(w io.Writer, messages []ListedMessage) error {
+	if messages == nil {
+		messages = []ListedMessage{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(messages)
+}
+
+// ListFormat is an output format for listings
+type ListFormat string
+
+const (
+	ListJSON  ListFormat = "json"
+	ListTable ListFormat = "table"
+	ListWide  ListFormat = "wide"
+)
+
+// ParseListFormat validates an --output value
+func ParseListFormat(s string) (ListFormat, error) {
+	switch f := ListFormat(strings.ToLower(s)); f {
+	case ListJSON, ListTable, ListWide:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid output format: %s (use json, table or wide)", s)
+}
+
+// DefaultListFormat returns the format for a listing not given --output: the
+// configured default's, or table
+func DefaultListFormat(defaults types.Defaults) ListFormat {
+	if defaults.Output != "" {
+		return ListFormat(defaults.Output)
+	}
+	return ListTable
+}
+
+// maxTableText is how much message text a table row shows unless told not to truncate
+const maxTableText = 50
+
+// WriteList writes a listing in the given format. noTruncate keeps full message
+// text in table output (wide output never truncates).
+func WriteList(w io.Writer, messages []ListedMessage, format ListFormat, noTruncate bool) error {
+	switch format {
+	case ListJSON:
+		return WriteListJSON(w, messages)
+	case ListTable, ListWide:
+		return writeListTable(w, messages, format == ListWide, noTruncate)
+	}
+	return fmt.Errorf("invalid output format: %s (use json, table or wide)", format)
+}
+
+// writeListTable writes aligned columns; wide adds the channel ID and full text
+func writeListTable(w io.Writer, messages []ListedMessage, wide, noTruncate bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if wide {
+		fmt.Fprintln(tw, "ID\tGROUP\tCHANNEL\tCHANNEL ID\tWHEN\tTEXT")
+	} else {
+		fmt.Fprintln(tw, "ID\tGROUP\tCHANNEL\tWHEN\tTEXT")
+	}
+
+	for _, msg := range messages {
+		group := msg.Group
+		if group == "" {
+			group = "-"
+		}
+		channel := channelLabel(msg)
+		when := formatListTime(msg.PostAt)
+
+		// Keep each message on one row
+		text := oneLine(msg.Text)
+		if !wide && !noTruncate && utf8.RuneCountInString(text) > maxTableText {
+			text = string([]rune(text)[:maxTableText-1]) + "…"
+		}
+
+		if wide {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", msg.SlackID, group, channel, msg.ChannelID, when, text)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", msg.SlackID, group, channel, when, text)
+		}
+	}
+	return tw.Flush()
+}
+
+// ListFilter limits a listing to a time window; zero bounds are open
+type ListFilter struct {
+	// Inclusive lower bound
+	After time.Time
+
+	// Exclusive upper bound
+	Before time.Time
+}
+
+// NewListFilter builds a filter from --after/--before dates (YYYY-MM-DD, in the
+// local timezone) and an --upcoming window such as "7d" or "12h" from now. Empty
+// values leave that bound open.
+func NewListFilter(after, before, upcoming string, now time.Time) (ListFilter, error) {
+	var f ListFilter
+	var err error
+	if after != "" {
+		if f.After, err = time.ParseInLocation("2006-01-02", after, LocalTZ); err != nil {
+			return ListFilter{}, fmt.Errorf("invalid --after date: %s (use YYYY-MM-DD)", after)
+		}
+	}
+	if before != "" {
+		if f.Before, err = time.ParseInLocation("2006-01-02", before, LocalTZ); err != nil {
+			return ListFilter{}, fmt.Errorf("invalid --before date: %s (use YYYY-MM-DD)", before)
+		}
+	}
+
+	if upcoming != "" {
+		shift, err := ParseShift(upcoming)
+		if err != nil || shift.Days < 0 || shift.Offset < 0 {
+			return ListFilter{}, fmt.Errorf("invalid --upcoming window: %s (use e.g. 7d, 12h)", upcoming)
+		}
+		end, _ := shift.Apply(now.In(LocalTZ))
+		if f.After.IsZero() || f.After.Before(now) {
+			f.After = now
+		}
+		if f.Before.IsZero() || end.Before(f.Before) {
+			f.Before = end
+		}
+	}
+
+	if !f.After.IsZero() && !f.Before.IsZero() && !f.After.Before(f.Before) {
+		return ListFilter{}, fmt.Errorf("empty time window: %s to %s", f.After.Format("2006-01-02 15:04"), f.Before.Format("2006-01-02 15:04"))
+	}
+	return f, nil
+}
+
+// Matches reports whether t falls in the filter's window
+func (f ListFilter) Matches(t time.Time) bool {
+	if !f.After.IsZero() && t.Before(f.After) {
+		return false
+	}
+	if !f.Before.IsZero() && !t.Before(f.Before) {
+		return false
+	}
+	return true
+}
+
+// Filter returns the messages within the window. Indexes are left as they were in
+// the full listing, so they stay stable whatever window is shown.
+func (f ListFilter) Filter(messages []ListedMessage) []ListedMessage {
+	var filtered []ListedMessage
+	for _, msg := range messages {
+		if f.Matches(msg.PostAt) {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
+
+// GroupBy is how a listing groups its messages
+type GroupBy string
+
+const (
+	GroupByText    GroupBy = "text"
+	GroupByChannel GroupBy = "channel"
+	GroupByNone    GroupBy = "none"
+)
+
+// ParseGroupBy validates a --group-by value; empty means group by text
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch g := GroupBy(strings.ToLower(s)); g {
+	case "":
+		return GroupByText, nil
+	case GroupByText, GroupByChannel, GroupByNone:
+		return g, nil
+	}
+	return "", fmt.Errorf("invalid group-by mode: %s (use text, channel or none)", s)
+}
+
+// ListGroup is a set of listed messages sharing a text or channel
+type ListGroup struct {
+	// Shared text (the next message's, for a series with rotating text), or channel
+	// label (#name or ID); empty when not grouping
+	Key string
+
+	// Series the messages belong to in the local store, when grouped by text
+	Series string
+
+	// In time order, so Messages[0] is the next occurrence
+	Messages []ListedMessage
+}
+
+// GroupMessages groups a listing, ordering groups by their next occurrence.
+// Grouping by text keeps a tracked series together even when its text rotates.
+// GroupByNone returns a single group holding every message.
+func GroupMessages(messages []ListedMessage, by GroupBy) []ListGroup {
+	if by == GroupByNone {
+		if len(messages) == 0 {
+			return nil
+		}
+		return []ListGroup{{Messages: messages}}
+	}
+
+	var groups []ListGroup
+	index := make(map[string]int)
+	for _, msg := range messages {
+		key, label, series := "text:"+msg.Text, msg.Text, msg.Group
+		if by == GroupByChannel {
+			key, label, series = channelLabel(msg), channelLabel(msg), ""
+		} else if series != "" {
+			key = "series:" + series
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ListGroup{Key: label, Series: series})
+		}
+		groups[i].Messages = append(groups[i].Messages, msg)
+	}
+	return groups
+}
+
+// WriteGroupedList writes a listing in the narrative format, grouped as requested,
+// with times also shown relative to now
+func WriteGroupedList(w io.Writer, messages []ListedMessage, by GroupBy, now time.Time) {
+	groups := GroupMessages(messages, by)
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "No scheduled messages")
+		return
+	}
+	when := func(t time.Time) string {
+		return fmt.Sprintf("%s (%s)", formatListTime(t), Relative(t, now))
+	}
+
+	for n, group := range groups {
+		next := group.Messages[0].PostAt
+		switch by {
+		case GroupByNone:
+			for _, msg := range group.Messages {
+				fmt.Fprintf(w, "[%d] %s  %s  %s (%s)\n", msg.Index, when(msg.PostAt), channelLabel(msg), oneLine(msg.Text), msg.SlackID)
+			}
+			continue
+		case GroupByChannel:
+			fmt.Fprintf(w, "%s: %d message(s), next %s\n", group.Key, len(group.Messages), when(next))
+			for _, msg := range group.Messages {
+				fmt.Fprintf(w, "  [%d] %s  %s (%s)\n", msg.Index, when(msg.PostAt), oneLine(msg.Text), msg.SlackID)
+			}
+		default:
+			if !group.rotates() {
+				fmt.Fprintf(w, "Group %d: %q (%d message(s), next %s)\n", n+1, oneLine(group.Key), len(group.Messages), Relative(next, now))
+				for _, msg := range group.Messages {
+					fmt.Fprintf(w, "  [%d] %s  %s (%s)\n", msg.Index, when(msg.PostAt), channelLabel(msg), msg.SlackID)
+				}
+				break
+			}
+			fmt.Fprintf(w, "Group %d: %s, rotating messages (%d message(s), next %s)\n", n+1, group.Series, len(group.Messages), Relative(next, now))
+			for _, msg := range group.Messages {
+				fmt.Fprintf(w, "  [%d] %s  %s  %s (%s)\n", msg.Index, when(msg.PostAt), channelLabel(msg), oneLine(msg.Text), msg.SlackID)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "Total: %d message(s)\n", len(messages))
+}
+
+// rotates reports whether the group's messages don't all share its text
+func (g ListGroup) rotates() bool {
+	for _, msg := range g.Messages {
+		if msg.Text != g.Key {
+			return true
+		}
+	}
+	return false
+}
+
+// channelLabel is the channel's #name when known, otherwise its ID
+func channelLabel(msg ListedMessage) string {
+	if msg.ChannelName != "" {
+		return "#" + msg.ChannelName
+	}
+	return msg.ChannelID
+}
+
+func formatListTime(t time.Time) string {
+	return t.In(LocalTZ).Format("2006-01-02 15:04 MST")
+}
+
+// oneLine collapses whitespace so a message fits on a single line
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// WriteIDs prints one identifier per line for piping into other commands: listing
+// indexes, or Slack scheduled message IDs when slackIDs is set
+func WriteIDs(w io.Writer, messages []ListedMessage, slackIDs bool) {
+	for _, msg := range messages {
+		if slackIDs {
+			fmt.Fprintln(w, msg.SlackID)
+		} else {
+			fmt.Fprintln(w, msg.Index)
+		}
+	}
+}
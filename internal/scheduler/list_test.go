@@ -0,0 +1,408 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+func TestListMessages(t *testing.T) {
+	later := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC).Unix()
+	sooner := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC).Unix()
+	api := slack.NewFakeAPI()
+	general := api.AddChannel("general")
+	client := slack.NewFakeClient(api)
+	api.Scheduled = []goslack.ScheduledMessage{
+		{ID: "Q2", Channel: general, Text: "Retro", PostAt: int(later)},
+		{ID: "Q1", Channel: general, Text: "Standup", PostAt: int(sooner)},
+	}
+
+	st, _ := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	st.Put(types.Series{Name: "standup", Occurrences: []types.Occurrence{{ScheduledID: "Q1"}}})
+
+	listed, err := ListMessages(client, st, general, nil)
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+
+	// Series fanned out to several channels list under their shared group
+	st.Put(types.Series{Name: "retro-general", Config: types.ScheduleConfig{Group: "retro"}, Occurrences: []types.Occurrence{{ScheduledID: "Q2"}}})
+	if grouped, err := ListMessages(client, st, general, nil); err != nil || grouped[1].Group != "retro" {
+		t.Errorf("ListMessages() group = %+v, %v, want retro", grouped, err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteListJSON(&buf, listed); err != nil {
+		t.Fatalf("WriteListJSON() error = %v", err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want 2", len(got))
+	}
+
+	want := map[string]interface{}{
+		"index":        float64(1),
+		"group":        "standup",
+		"slack_id":     "Q1",
+		"channel_id":   general,
+		"channel_name": "general",
+		"text":         "Standup",
+	}
+	for key, value := range want {
+		if got[0][key] != value {
+			t.Errorf("first message %s = %v, want %v", key, got[0][key], value)
+		}
+	}
+	if postAt, err := time.Parse(time.RFC3339, fmt.Sprint(got[0]["post_at"])); err != nil || postAt.Unix() != sooner {
+		t.Errorf("first message post_at = %v, want RFC 3339 time of %d", got[0]["post_at"], sooner)
+	}
+	if _, ok := got[1]["group"]; ok || got[1]["index"] != float64(2) {
+		t.Errorf("second message = %v, want index 2 without group", got[1])
+	}
+}
+
+func TestListMessages_Grep(t *testing.T) {
+	base := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC).Unix()
+	api := slack.NewFakeAPI()
+	general := api.AddChannel("general")
+	client := slack.NewFakeClient(api)
+	api.Scheduled = []goslack.ScheduledMessage{
+		{ID: "Q1", Channel: general, Text: "Retro", PostAt: int(base)},
+		{ID: "Q2", Channel: general, Text: "Daily Standup", PostAt: int(base + 60)},
+		{ID: "Q3", Channel: general, Text: "standup notes", PostAt: int(base + 120)},
+	}
+
+	match, err := NewTextMatcher("standup", false)
+	if err != nil {
+		t.Fatalf("NewTextMatcher() error = %v", err)
+	}
+	listed, err := ListMessages(client, nil, "", match)
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	if len(listed) != 2 || listed[0].SlackID != "Q2" || listed[0].Index != 1 || listed[1].Index != 2 {
+		t.Errorf("ListMessages() = %+v, want Q2 and Q3 indexed 1 and 2", listed)
+	}
+}
+
+func TestNewTextMatcher(t *testing.T) {
+	tests := []struct {
+		pattern string
+		regex   bool
+		text    string
+		want    bool
+		wantErr bool
+	}{
+		{pattern: "standup", text: "Daily STANDUP", want: true},
+		{pattern: "standup", text: "Retro", want: false},
+		{pattern: "st.ndup", text: "standup", want: false},
+		{pattern: "^Daily (standup|sync)$", regex: true, text: "Daily sync", want: true},
+		{pattern: "^standup", regex: true, text: "Daily standup", want: false},
+		{pattern: "(", regex: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		match, err := NewTextMatcher(tt.pattern, tt.regex)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("NewTextMatcher(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+		if err == nil && match(tt.text) != tt.want {
+			t.Errorf("NewTextMatcher(%q, %v)(%q) = %v, want %v", tt.pattern, tt.regex, tt.text, !tt.want, tt.want)
+		}
+	}
+}
+
+func TestWriteListJSON_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteListJSON(&buf, nil); err != nil {
+		t.Fatalf("WriteListJSON() error = %v", err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("WriteListJSON(nil) = %q, want empty array", got)
+	}
+}
+
+func TestParseListFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ListFormat
+		wantErr bool
+	}{
+		{"json", ListJSON, false},
+		{"TABLE", ListTable, false},
+		{"wide", ListWide, false},
+		{"yaml", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseListFormat(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseListFormat(%q) = %q, %v; want %q (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if got := DefaultListFormat(types.Defaults{}); got != ListTable {
+		t.Errorf("DefaultListFormat() = %q, want table", got)
+	}
+	if got := DefaultListFormat(types.Defaults{Output: "wide"}); got != ListWide {
+		t.Errorf("DefaultListFormat() = %q, want the configured wide", got)
+	}
+}
+
+func TestWriteList_Table(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	messages := []ListedMessage{
+		{Index: 1, Group: "standup", SlackID: "Q1", ChannelID: "C777", ChannelName: "general", Text: "Standup\ntime", PostAt: time.Date(2030, 1, 1, 9, 0, 0, 0, LocalTZ)},
+		{Index: 2, SlackID: "Q2", ChannelID: "C888", Text: long, PostAt: time.Date(2030, 1, 2, 9, 0, 0, 0, LocalTZ)},
+	}
+	fullText := strings.TrimSpace(long)
+
+	tests := []struct {
+		name       string
+		format     ListFormat
+		noTruncate bool
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:    "table",
+			format:  ListTable,
+			want:    []string{"ID", "GROUP", "CHANNEL", "WHEN", "TEXT", "Q1", "standup", "#general", "2030-01-01 09:00", "Standup time", "Q2", "-", "C888", "…"},
+			notWant: []string{"CHANNEL ID", fullText},
+		},
+		{
+			name:       "table without truncation",
+			format:     ListTable,
+			noTruncate: true,
+			want:       []string{fullText},
+			notWant:    []string{"…"},
+		},
+		{
+			name:    "wide",
+			format:  ListWide,
+			want:    []string{"CHANNEL ID", "C777", fullText},
+			notWant: []string{"…"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteList(&buf, messages, tt.format, tt.noTruncate); err != nil {
+				t.Fatalf("WriteList() error = %v", err)
+			}
+			out := buf.String()
+			if lines := strings.Count(out, "\n"); lines != 3 {
+				t.Errorf("got %d lines, want header + 2 rows:\n%s", lines, out)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output missing %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out, s) {
+					t.Errorf("output unexpectedly contains %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestNewListFilter(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, LocalTZ)
+	day := func(d int) time.Time { return time.Date(2025, 2, d, 0, 0, 0, 0, LocalTZ) }
+
+	tests := []struct {
+		name                    string
+		after, before, upcoming string
+		want                    ListFilter
+		wantErr                 bool
+	}{
+		{name: "open", want: ListFilter{}},
+		{name: "date range", after: "2025-02-01", before: "2025-03-01", want: ListFilter{After: day(1), Before: time.Date(2025, 3, 1, 0, 0, 0, 0, LocalTZ)}},
+		{name: "upcoming", upcoming: "7d", want: ListFilter{After: now, Before: now.AddDate(0, 0, 7)}},
+		{name: "upcoming hours", upcoming: "12h", want: ListFilter{After: now, Before: now.Add(12 * time.Hour)}},
+		{name: "upcoming capped by before", before: "2025-02-12", upcoming: "7d", want: ListFilter{After: now, Before: day(12)}},
+		{name: "later after wins over now", after: "2025-02-14", upcoming: "7d", want: ListFilter{After: day(14), Before: now.AddDate(0, 0, 7)}},
+		{name: "bad after", after: "02/01/2025", wantErr: true},
+		{name: "bad before", before: "tomorrow", wantErr: true},
+		{name: "negative upcoming", upcoming: "-7d", wantErr: true},
+		{name: "bad upcoming", upcoming: "week", wantErr: true},
+		{name: "empty window", after: "2025-03-01", before: "2025-02-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewListFilter(tt.after, tt.before, tt.upcoming, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewListFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.After.Equal(tt.want.After) || !got.Before.Equal(tt.want.Before) {
+				t.Errorf("NewListFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilter_Filter(t *testing.T) {
+	var messages []ListedMessage
+	for d := 1; d <= 5; d++ {
+		messages = append(messages, ListedMessage{Index: d, SlackID: fmt.Sprintf("Q%d", d), PostAt: time.Date(2025, 2, d, 9, 0, 0, 0, LocalTZ)})
+	}
+
+	f := ListFilter{After: time.Date(2025, 2, 2, 9, 0, 0, 0, LocalTZ), Before: time.Date(2025, 2, 4, 9, 0, 0, 0, LocalTZ)}
+	got := f.Filter(messages)
+	if len(got) != 2 || got[0].Index != 2 || got[1].Index != 3 {
+		t.Errorf("Filter() = %+v, want messages 2 and 3 with original indexes", got)
+	}
+}
+
+func TestGroupMessages(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2030, 1, d, 9, 0, 0, 0, LocalTZ) }
+	messages := []ListedMessage{
+		{Index: 1, SlackID: "Q1", ChannelID: "C1", ChannelName: "general", Text: "Standup", PostAt: at(1)},
+		{Index: 2, SlackID: "Q2", ChannelID: "C2", Text: "Retro", PostAt: at(2)},
+		{Index: 3, SlackID: "Q3", ChannelID: "C2", Text: "Standup", PostAt: at(3)},
+		{Index: 4, SlackID: "Q4", ChannelID: "C1", ChannelName: "general", Text: "Standup", PostAt: at(4)},
+	}
+
+	tests := []struct {
+		by       GroupBy
+		wantKeys []string
+		wantLens []int
+	}{
+		{GroupByText, []string{"Standup", "Retro"}, []int{3, 1}},
+		{GroupByChannel, []string{"#general", "C2"}, []int{2, 2}},
+		{GroupByNone, []string{""}, []int{4}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.by), func(t *testing.T) {
+			groups := GroupMessages(messages, tt.by)
+			if len(groups) != len(tt.wantKeys) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.wantKeys))
+			}
+			for i, g := range groups {
+				if g.Key != tt.wantKeys[i] || len(g.Messages) != tt.wantLens[i] {
+					t.Errorf("group %d = %q with %d messages, want %q with %d", i, g.Key, len(g.Messages), tt.wantKeys[i], tt.wantLens[i])
+				}
+			}
+		})
+	}
+
+	// A tracked series with rotating text stays in one group
+	rotating := []ListedMessage{
+		{Index: 1, Group: "icebreaker", SlackID: "Q1", ChannelID: "C1", Text: "Favourite snack?", PostAt: at(1)},
+		{Index: 2, SlackID: "Q2", ChannelID: "C1", Text: "Best trip?", PostAt: at(2)},
+		{Index: 3, Group: "icebreaker", SlackID: "Q3", ChannelID: "C1", Text: "Best trip?", PostAt: at(8)},
+	}
+	groups := GroupMessages(rotating, GroupByText)
+	if len(groups) != 2 || groups[0].Series != "icebreaker" || len(groups[0].Messages) != 2 || groups[0].Key != "Favourite snack?" {
+		t.Errorf("GroupMessages(rotating) = %+v, want the icebreaker series grouped apart from the untracked message", groups)
+	}
+
+	if groups := GroupMessages(nil, GroupByNone); groups != nil {
+		t.Errorf("GroupMessages(nil) = %v, want nil", groups)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    GroupBy
+		wantErr bool
+	}{
+		{"", GroupByText, false},
+		{"text", GroupByText, false},
+		{"Channel", GroupByChannel, false},
+		{"none", GroupByNone, false},
+		{"series", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseGroupBy(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseGroupBy(%q) = %q, %v; want %q (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteGroupedList(t *testing.T) {
+	now := time.Date(2029, 12, 31, 9, 0, 0, 0, LocalTZ)
+	messages := []ListedMessage{
+		{Index: 1, SlackID: "Q1", ChannelID: "C1", ChannelName: "general", Text: "Standup", PostAt: time.Date(2030, 1, 1, 9, 0, 0, 0, LocalTZ)},
+		{Index: 2, SlackID: "Q2", ChannelID: "C1", ChannelName: "general", Text: "Retro", PostAt: time.Date(2030, 1, 2, 9, 0, 0, 0, LocalTZ)},
+	}
+
+	tests := []struct {
+		by   GroupBy
+		want []string
+	}{
+		{GroupByText, []string{`Group 1: "Standup" (1 message(s), next in 1 day)`, `Group 2: "Retro" (1 message(s), next in 2 days)`, "  [2] 2030-01-02 09:00", "(in 2 days)  #general (Q2)", "Total: 2 message(s)"}},
+		{GroupByChannel, []string{"#general: 2 message(s), next 2030-01-01 09:00", "(in 1 day)\n", "  [1] 2030-01-01 09:00", "Retro (Q2)"}},
+		{GroupByNone, []string{"[1] 2030-01-01 09:00", "(in 1 day)  #general  Standup (Q1)", "[2] "}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.by), func(t *testing.T) {
+			var buf bytes.Buffer
+			WriteGroupedList(&buf, messages, tt.by, now)
+			for _, s := range tt.want {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	WriteGroupedList(&buf, []ListedMessage{
+		{Index: 1, Group: "icebreaker", SlackID: "Q1", ChannelID: "C1", Text: "Favourite snack?", PostAt: time.Date(2030, 1, 1, 9, 0, 0, 0, LocalTZ)},
+		{Index: 2, Group: "icebreaker", SlackID: "Q2", ChannelID: "C1", Text: "Best trip?", PostAt: time.Date(2030, 1, 8, 9, 0, 0, 0, LocalTZ)},
+	}, GroupByText, now)
+	for _, s := range []string{"Group 1: icebreaker, rotating messages (2 message(s)", "C1  Best trip? (Q2)"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("rotating output missing %q:\n%s", s, buf.String())
+		}
+	}
+
+	buf.Reset()
+	WriteGroupedList(&buf, nil, GroupByText, now)
+	if got := buf.String(); got != "No scheduled messages\n" {
+		t.Errorf("empty listing = %q", got)
+	}
+}
+
+func TestWriteIDs(t *testing.T) {
+	messages := []ListedMessage{
+		{Index: 2, SlackID: "Q2"},
+		{Index: 5, SlackID: "Q5"},
+	}
+	tests := []struct {
+		slackIDs bool
+		want     string
+	}{
+		{false, "2\n5\n"},
+		{true, "Q2\nQ5\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		WriteIDs(&buf, messages, tt.slackIDs)
+		if buf.String() != tt.want {
+			t.Errorf("WriteIDs(slackIDs=%v) = %q, want %q", tt.slackIDs, buf.String(), tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	WriteIDs(&buf, nil, true)
+	if buf.Len() != 0 {
+		t.Errorf("WriteIDs(nil) = %q, want no output", buf.String())
+	}
+}
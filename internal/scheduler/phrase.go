@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// phraseUnits maps the units "every N ..." accepts to intervals
+var phraseUnits = map[string]types.Interval{
+	"hour": types.IntervalHourly, "day": types.IntervalDaily,
+	"week": types.IntervalWeekly, "month": types.IntervalMonthly,
+}
+
+// phraseIntervals are the single words standing for "every <unit>"
+var phraseIntervals = map[string]types.Interval{
+	"hourly": types.IntervalHourly, "daily": types.IntervalDaily,
+	"weekly": types.IntervalWeekly, "monthly": types.IntervalMonthly,
+}
+
+// weekdayNames are the days "every weekday" covers
+var weekdayNames = []types.DayOfWeek{types.Monday, types.Tuesday, types.Wednesday, types.Thursday, types.Friday}
+
+// ParsePhrase reads a schedule written in plain English, as typed into the
+// /schedule slash command, into the date, time and recurrence fields of a config.
+// It understands:
+//
+//	every friday 2pm, every mon and wed at 9:30, every weekday at noon
+//	every day 9am, every other week, every 3 months, daily, weekly
+//	tomorrow 10am, friday at 14:00, 2030-01-15 9am (a single message)
+//	... starting 2030-01-15, ... until 2030-06-30, ... 6 times
+//
+// Times default to 09:00. Recurring schedules without an end run indefinitely
+// (kept topped up by the daemon) and start from today in now's time zone.
+func ParsePhrase(phrase string, now time.Time) (types.ScheduleConfig, error) {
+	config := types.ScheduleConfig{
+		StartDate: now.Format("2006-01-02"),
+		SendTime:  "09:00",
+		Interval:  types.IntervalNone,
+	}
+	words := phraseWords(phrase)
+	if len(words) == 0 {
+		return config, fmt.Errorf("no schedule given (e.g. \"every friday 2pm\")")
+	}
+
+	var date time.Time
+	var named []types.DayOfWeek
+	recurring, timed := false, false
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "at" || word == "on" || word == "and" || word == "starting":
+			// Filler around the parts that matter
+
+		case word == "every":
+			if recurring {
+				return config, fmt.Errorf("only one \"every\" is allowed")
+			}
+			n, err := parseEvery(words[i+1:], &config)
+			if err != nil {
+				return config, err
+			}
+			i += n
+			recurring = true
+
+		case phraseIntervals[word] != "":
+			if recurring {
+				return config, fmt.Errorf("only one repeat interval is allowed")
+			}
+			config.Interval = phraseIntervals[word]
+			recurring = true
+
+		case word == "until":
+			if i+1 >= len(words) {
+				return config, fmt.Errorf("\"until\" needs a date (YYYY-MM-DD)")
+			}
+			i++
+			if _, err := time.Parse("2006-01-02", words[i]); err != nil {
+				return config, fmt.Errorf("invalid end date: %s (use YYYY-MM-DD)", words[i])
+			}
+			config.EndDate = words[i]
+
+		case i+1 < len(words) && (words[i+1] == "times" || words[i+1] == "time"):
+			count, err := strconv.Atoi(word)
+			if err != nil || count < 1 {
+				return config, fmt.Errorf("invalid count: %s times", word)
+			}
+			config.RepeatCount = count
+			i++
+
+		case word == "today" || word == "tomorrow":
+			date = now
+			if word == "tomorrow" {
+				date = now.AddDate(0, 0, 1)
+			}
+
+		default:
+			if t, err := time.ParseInLocation("2006-01-02", word, now.Location()); err == nil {
+				date = t
+			} else if day, err := types.ParseDayOfWeek(strings.TrimSuffix(word, "s")); err == nil {
+				date = nextWeekday(now, day)
+				named = append(named, day)
+			} else if clock, ok := parseClock(word); ok {
+				if timed {
+					return config, fmt.Errorf("only one time is allowed")
+				}
+				config.SendTime = clock
+				timed = true
+			} else {
+				return config, fmt.Errorf("don't understand %q in %q", word, phrase)
+			}
+		}
+	}
+
+	switch {
+	case date.IsZero():
+	case recurring && len(named) == 0:
+		// "every day starting tomorrow"
+		config.StartDate = date.Format("2006-01-02")
+	case recurring:
+		// "every other week on friday"
+		if config.Interval != types.IntervalWeekly || len(config.Days) > 0 {
+			return config, fmt.Errorf("use \"every <day>\" for a repeating day, or a single date without \"every\"")
+		}
+		config.Days = named
+	default:
+		// "friday 2pm" said on a Friday afternoon means next week's
+		if at, err := time.ParseInLocation("2006-01-02 15:04", date.Format("2006-01-02")+" "+config.SendTime, now.Location()); len(named) > 0 && err == nil && !at.After(now) {
+			date = date.AddDate(0, 0, 7)
+		}
+		config.StartDate = date.Format("2006-01-02")
+	}
+	if recurring && config.RepeatCount == 0 && config.EndDate == "" {
+		config.RepeatCount = -1
+	}
+	if !recurring && (config.RepeatCount > 1 || config.EndDate != "") {
+		return config, fmt.Errorf("a count or end date needs a repeat (e.g. \"every week\")")
+	}
+	if !recurring {
+		config.RepeatCount = 0
+	}
+	return config, nil
+}
+
+// parseEvery reads what follows "every" into config and returns how many words
+// it used
+func parseEvery(words []string, config *types.ScheduleConfig) (int, error) {
+	if len(words) == 0 {
+		return 0, fmt.Errorf("\"every\" needs a unit or day (e.g. \"every week\", \"every friday\")")
+	}
+
+	used := 0
+	if words[0] == "other" {
+		config.Every = 2
+		used++
+	} else if n, err := strconv.Atoi(words[0]); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf("invalid interval multiplier: %d (must be 1 or greater)", n)
+		}
+		config.Every = n
+		used++
+	}
+	if used >= len(words) {
+		return 0, fmt.Errorf("\"every %s\" needs a unit (e.g. days, weeks)", words[0])
+	}
+
+	unit := words[used]
+	if interval, ok := phraseUnits[strings.TrimSuffix(unit, "s")]; ok {
+		config.Interval = interval
+		return used + 1, nil
+	}
+	if unit == "weekday" || unit == "weekdays" {
+		config.Interval = types.IntervalWeekly
+		config.Days = append([]types.DayOfWeek(nil), weekdayNames...)
+		return used + 1, nil
+	}
+
+	// A list of days: "every mon, wed and fri"
+	config.Interval = types.IntervalWeekly
+	for ; used < len(words); used++ {
+		if words[used] == "and" {
+			continue
+		}
+		day, err := types.ParseDayOfWeek(strings.TrimSuffix(words[used], "s"))
+		if err != nil {
+			break
+		}
+		config.Days = append(config.Days, day)
+	}
+	if len(config.Days) == 0 {
+		return 0, fmt.Errorf("don't understand \"every %s\" (use a unit like week or a day like friday)", unit)
+	}
+	return used, nil
+}
+
+// parseClock parses a time of day such as 2pm, 2:30pm, 14:00, noon or midnight
+// into HH:MM
+func parseClock(word string) (string, bool) {
+	switch word {
+	case "noon":
+		return "12:00", true
+	case "midnight":
+		return "00:00", true
+	}
+	for _, layout := range []string{"15:04", "3pm", "3:04pm", "3am", "3:04am"} {
+		if t, err := time.Parse(layout, word); err == nil {
+			return t.Format("15:04"), true
+		}
+	}
+	return "", false
+}
+
+// nextWeekday returns the next date falling on day, today included
+func nextWeekday(now time.Time, day types.DayOfWeek) time.Time {
+	for i := 0; i < 7; i++ {
+		d := now.AddDate(0, 0, i)
+		if strings.EqualFold(d.Weekday().String(), string(day)) {
+			return d
+		}
+	}
+	return now
+}
+
+// phraseWords lowercases a phrase and splits it into words, treating commas as
+// spaces and "2 pm" as "2pm"
+func phraseWords(phrase string) []string {
+	fields := strings.Fields(strings.ToLower(strings.ReplaceAll(phrase, ",", " ")))
+	var words []string
+	for _, f := range fields {
+		if (f == "am" || f == "pm") && len(words) > 0 {
+			words[len(words)-1] += f
+			continue
+		}
+		words = append(words, f)
+	}
+	return words
+}
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestParsePhrase(t *testing.T) {
+	// A Friday afternoon
+	now := time.Date(2030, 1, 4, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		phrase string
+		want   types.ScheduleConfig
+	}{
+		{"every friday 2pm", types.ScheduleConfig{StartDate: "2030-01-04", SendTime: "14:00", Interval: types.IntervalWeekly, Days: []types.DayOfWeek{types.Friday}, RepeatCount: -1}},
+		{"every Mon and Wed at 9:30", types.ScheduleConfig{StartDate: "2030-01-04", SendTime: "09:30", Interval: types.IntervalWeekly, Days: []types.DayOfWeek{types.Monday, types.Wednesday}, RepeatCount: -1}},
+		{"every weekday at noon until 2030-02-01", types.ScheduleConfig{StartDate: "2030-01-04", SendTime: "12:00", Interval: types.IntervalWeekly, Days: weekdayNames, EndDate: "2030-02-01"}},
+		{"every other week on thursday 10 am", types.ScheduleConfig{StartDate: "2030-01-04", SendTime: "10:00", Interval: types.IntervalWeekly, Every: 2, Days: []types.DayOfWeek{types.Thursday}, RepeatCount: -1}},
+		{"every 3 months 6 times", types.ScheduleConfig{StartDate: "2030-01-04", SendTime: "09:00", Interval: types.IntervalMonthly, Every: 3, RepeatCount: 6}},
+		{"daily 8:15am starting tomorrow", types.ScheduleConfig{StartDate: "2030-01-05", SendTime: "08:15", Interval: types.IntervalDaily, RepeatCount: -1}},
+		{"tomorrow 10am", types.ScheduleConfig{StartDate: "2030-01-05", SendTime: "10:00", Interval: types.IntervalNone}},
+		{"2030-01-15", types.ScheduleConfig{StartDate: "2030-01-15", SendTime: "09:00", Interval: types.IntervalNone}},
+		// Today's 2pm has passed, so it's next Friday's
+		{"friday at 14:00", types.ScheduleConfig{StartDate: "2030-01-11", SendTime: "14:00", Interval: types.IntervalNone}},
+		{"friday 5pm", types.ScheduleConfig{StartDate: "2030-01-04", SendTime: "17:00", Interval: types.IntervalNone}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePhrase(tt.phrase, now)
+		if err != nil {
+			t.Errorf("ParsePhrase(%q) error = %v", tt.phrase, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePhrase(%q) = %+v, want %+v", tt.phrase, got, tt.want)
+		}
+	}
+
+	for _, phrase := range []string{"", "every", "every fortnight", "every day weekly", "sometime soon", "tomorrow until 2030-02-01", "every day 2pm 3pm", "every month on friday"} {
+		if _, err := ParsePhrase(phrase, now); err == nil {
+			t.Errorf("ParsePhrase(%q) expected an error", phrase)
+		}
+	}
+}
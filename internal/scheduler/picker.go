@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch reports whether query's characters appear in text in order (ignoring
+// case), fzf-style. Higher scores mean tighter matches: consecutive characters and
+// matches at word starts count extra.
+func FuzzyMatch(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+	t := []rune(strings.ToLower(text))
+
+	score, qi, prev := 0, 0, -2
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score++
+		}
+		prev = ti
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+// Picker is a line-based multi-select for choosing scheduled messages. Typing
+// "/text" fuzzy-filters the list, numbers or ranges ("2", "1-3,5") toggle items,
+// "a" toggles every shown item, an empty line or "d" finishes and "q" cancels.
+type Picker struct {
+	Items []ListedMessage
+	In    io.Reader
+	Out   io.Writer
+}
+
+// Pick runs the picker, returning the selected messages in listing order (nil if
+// cancelled or nothing was chosen)
+func (p *Picker) Pick() ([]ListedMessage, error) {
+	selected := make(map[int]bool)
+	shown := p.filter("")
+	reader := bufio.NewReader(p.In)
+
+	for {
+		p.render(shown, selected)
+		fmt.Fprint(p.Out, "> ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || line == "d":
+			return p.selection(selected), nil
+		case line == "q":
+			return nil, nil
+		case strings.HasPrefix(line, "/"):
+			shown = p.filter(strings.TrimSpace(line[1:]))
+		case line == "a":
+			for _, i := range shown {
+				selected[i] = !selected[i]
+			}
+		default:
+			positions, err := parsePositions(line, len(shown))
+			if err != nil {
+				fmt.Fprintf(p.Out, "%v\n", err)
+				continue
+			}
+			for _, pos := range positions {
+				i := shown[pos-1]
+				selected[i] = !selected[i]
+			}
+		}
+	}
+}
+
+// filter returns the indexes of items matching query, best matches first
+func (p *Picker) filter(query string) []int {
+	type scored struct{ index, score int }
+	var matches []scored
+	for i, item := range p.Items {
+		haystack := fmt.Sprintf("%s %s %s %s", item.Group, channelLabel(item), formatListTime(item.PostAt), item.Text)
+		if score, ok := FuzzyMatch(query, haystack); ok {
+			matches = append(matches, scored{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].score > matches[b].score })
+
+	shown := make([]int, len(matches))
+	for n, m := range matches {
+		shown[n] = m.index
+	}
+	return shown
+}
+
+func (p *Picker) render(shown []int, selected map[int]bool) {
+	count := 0
+	for _, v := range selected {
+		if v {
+			count++
+		}
+	}
+	fmt.Fprintf(p.Out, "\n%d of %d shown, %d selected\n", len(shown), len(p.Items), count)
+	for n, i := range shown {
+		mark := " "
+		if selected[i] {
+			mark = "x"
+		}
+		item := p.Items[i]
+		fmt.Fprintf(p.Out, "%3d [%s] %s  %s  %s\n", n+1, mark, formatListTime(item.PostAt), channelLabel(item), oneLine(item.Text))
+	}
+	fmt.Fprintln(p.Out, "/text filter, numbers toggle (1-3,5), a toggle shown, enter done, q cancel")
+}
+
+func (p *Picker) selection(selected map[int]bool) []ListedMessage {
+	var chosen []ListedMessage
+	for i, item := range p.Items {
+		if selected[i] {
+			chosen = append(chosen, item)
+		}
+	}
+	return chosen
+}
+
+// parsePositions parses "1-3,5" into 1-based positions within n items
+func parsePositions(s string, n int) ([]int, error) {
+	var positions []int
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(lo)
+		to := from
+		if err == nil && isRange {
+			to, err = strconv.Atoi(hi)
+		}
+		if err != nil || from < 1 || to > n || from > to {
+			return nil, fmt.Errorf("invalid selection: %s (choose 1-%d)", part, n)
+		}
+		for i := from; i <= to; i++ {
+			positions = append(positions, i)
+		}
+	}
+	return positions, nil
+}
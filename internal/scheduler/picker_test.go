@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query, text string
+		want        bool
+	}{
+		{"", "anything", true},
+		{"stdup", "Daily standup", true},
+		{"STANDUP", "daily standup", true},
+		{"pudnats", "standup", false},
+		{"retro", "standup", false},
+	}
+	for _, tt := range tests {
+		if _, ok := FuzzyMatch(tt.query, tt.text); ok != tt.want {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, want %v", tt.query, tt.text, ok, tt.want)
+		}
+	}
+
+	tight, _ := FuzzyMatch("retro", "retro notes")
+	loose, _ := FuzzyMatch("retro", "read every team report once")
+	if tight <= loose {
+		t.Errorf("contiguous match scored %d, scattered match %d; want contiguous higher", tight, loose)
+	}
+}
+
+func TestPicker(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2030, 1, d, 9, 0, 0, 0, LocalTZ) }
+	items := []ListedMessage{
+		{Index: 1, SlackID: "Q1", Text: "Standup", PostAt: at(1)},
+		{Index: 2, SlackID: "Q2", Text: "Retro", PostAt: at(2)},
+		{Index: 3, SlackID: "Q3", Text: "Standup", PostAt: at(3)},
+		{Index: 4, SlackID: "Q4", Text: "Planning", PostAt: at(4)},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"pick by number", "2\n\n", []string{"Q2"}},
+		{"ranges and toggling off", "1-3\n2\nd\n", []string{"Q1", "Q3"}},
+		{"filter then select all shown", "/stnd\na\n\n", []string{"Q1", "Q3"}},
+		{"filter then number refers to filtered view", "/plan\n1\n\n", []string{"Q4"}},
+		{"cancel", "1\nq\n", nil},
+		{"end of input cancels", "1\n", nil},
+		{"invalid selection is reported and ignored", "9\n4\n\n", []string{"Q4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := &Picker{Items: items, In: strings.NewReader(tt.input), Out: &out}
+			got, err := p.Pick()
+			if err != nil {
+				t.Fatalf("Pick() error = %v", err)
+			}
+			var ids []string
+			for _, m := range got {
+				ids = append(ids, m.SlackID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Pick() = %v, want %v\n%s", ids, tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"2", []int{2}, false},
+		{"1-3,5", []int{1, 2, 3, 5}, false},
+		{"1 4", []int{1, 4}, false},
+		{"0", nil, true},
+		{"6", nil, true},
+		{"3-1", nil, true},
+		{"x", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parsePositions(tt.input, 5)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePositions(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parsePositions(%q) = %v, want %v", tt.input, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parsePositions(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// PlanAction is what applying a plan does to one series
+type PlanAction string
+
+const (
+	PlanCreate    PlanAction = "create"
+	PlanUpdate    PlanAction = "update"
+	PlanDelete    PlanAction = "delete"
+	PlanRepair    PlanAction = "repair"
+	PlanUnchanged PlanAction = "unchanged"
+
+	// The name is taken by a series not applied from the file, which is left alone
+	PlanConflict PlanAction = "conflict"
+)
+
+// PlanItem is the planned change for one series
+type PlanItem struct {
+	Action PlanAction
+	Name   string
+
+	// Desired config (nil for deletes)
+	Config *types.ScheduleConfig
+
+	// Existing series in the local store (nil for creates); for conflicts, the
+	// series holding the name
+	Series *types.Series
+
+	// Tracked occurrences no longer scheduled in Slack (repairs)
+	Missing []types.Occurrence
+}
+
+// Plan is the delta between a schedule file and what is actually scheduled
+type Plan struct {
+	Source string
+	Items  []PlanItem
+}
+
+// HasChanges reports whether applying the plan would change anything
+func (p Plan) HasChanges() bool {
+	for _, item := range p.Items {
+		if item.Action != PlanUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildPlan diffs the desired schedules from a file against the series previously
+// applied from that file. Entries without a name get one derived from their config.
+// An entry whose name belongs to a series from elsewhere (scheduled by hand or
+// from another file) is planned as a conflict rather than replacing it. When
+// scheduled (what Slack currently has) is non-nil, unchanged series whose
+// upcoming occurrences have gone missing from Slack are planned for repair.
+func BuildPlan(source string, desired []types.ScheduleConfig, existing []types.Series, scheduled []types.Occurrence, now time.Time) (Plan, error) {
+	plan := Plan{Source: source}
+
+	current := make(map[string]*types.Series)
+	others := make(map[string]*types.Series)
+	for i := range existing {
+		if existing[i].Source == source {
+			current[existing[i].Name] = &existing[i]
+		} else {
+			others[existing[i].Name] = &existing[i]
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range desired {
+		config := desired[i]
+		if config.Name == "" {
+			config.Name = store.DefaultName(&config)
+		}
+		if seen[config.Name] {
+			return Plan{}, fmt.Errorf("duplicate schedule name in %s: %s", source, config.Name)
+		}
+		seen[config.Name] = true
+
+		series, ok := current[config.Name]
+		switch {
+		case !ok && others[config.Name] != nil:
+			plan.Items = append(plan.Items, PlanItem{Action: PlanConflict, Name: config.Name, Config: &config, Series: others[config.Name]})
+		case !ok:
+			plan.Items = append(plan.Items, PlanItem{Action: PlanCreate, Name: config.Name, Config: &config})
+		case !sameConfig(series.Config, config):
+			plan.Items = append(plan.Items, PlanItem{Action: PlanUpdate, Name: config.Name, Config: &config, Series: series})
+		default:
+			item := PlanItem{Action: PlanUnchanged, Name: config.Name, Config: &config, Series: series}
+			if scheduled != nil {
+				tracked := make([]types.Occurrence, 0, len(series.Occurrences))
+				for _, i := range upcoming(series, now) {
+					tracked = append(tracked, series.Occurrences[i])
+				}
+				if missing := Reconcile(tracked, scheduled).Missing; len(missing) > 0 {
+					item.Action, item.Missing = PlanRepair, missing
+				}
+			}
+			plan.Items = append(plan.Items, item)
+		}
+	}
+
+	for i := range existing {
+		series := &existing[i]
+		if series.Source == source && !seen[series.Name] {
+			plan.Items = append(plan.Items, PlanItem{Action: PlanDelete, Name: series.Name, Series: series})
+		}
+	}
+
+	return plan, nil
+}
+
+// sameConfig compares configs as they are stored, so e.g. an empty and a nil day
+// list (which the store can't tell apart) are equal
+func sameConfig(a, b types.ScheduleConfig) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// Write prints the plan in a terraform-like summary
+func (p Plan) Write(w io.Writer) {
+	symbols := map[PlanAction]string{
+		PlanCreate: "+", PlanUpdate: "~", PlanDelete: "-", PlanRepair: "!", PlanUnchanged: " ", PlanConflict: "x",
+	}
+	counts := make(map[PlanAction]int)
+
+	fmt.Fprintf(w, "Plan for %s:\n", p.Source)
+	for _, item := range p.Items {
+		counts[item.Action]++
+		line := fmt.Sprintf("  %s %s (%s)", symbols[item.Action], item.Name, item.Action)
+		switch item.Action {
+		case PlanRepair:
+			line += fmt.Sprintf(": %d occurrence(s) missing from Slack", len(item.Missing))
+		case PlanConflict:
+			line += ": " + conflictReason(item.Series)
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "%d to create, %d to update, %d to delete, %d to repair, %d unchanged\n",
+		counts[PlanCreate], counts[PlanUpdate], counts[PlanDelete], counts[PlanRepair], counts[PlanUnchanged])
+	if counts[PlanConflict] > 0 {
+		fmt.Fprintf(w, "%d name conflict(s) will be skipped; rename those entries\n", counts[PlanConflict])
+	}
+}
+
+// conflictReason says where the series holding a conflicting name came from
+func conflictReason(series *types.Series) string {
+	if series.Source == "" {
+		return "name taken by a series scheduled outside this file"
+	}
+	return fmt.Sprintf("name taken by a series from %s", series.Source)
+}
+
+// ApplyPlan carries out the plan's changes as of clock's time, recording them in st
+// (the caller saves it). Each change is attempted independently; the results report
+// per-series outcomes.
+func ApplyPlan(client *slack.Client, st *store.Store, plan Plan, clock Clock) []BatchResult {
+	var results []BatchResult
+
+	for _, item := range plan.Items {
+		var result BatchResult
+		switch item.Action {
+		case PlanCreate:
+			result = applySchedule(client, st, plan.Source, *item.Config, clock)
+
+		case PlanUpdate:
+			// Replace the old occurrences wholesale with ones from the new definition
+			_, err := newWithClock(client, &item.Series.Config, clock).deleteUpcoming(item.Series)
+			st.Put(*item.Series)
+			if err != nil {
+				result = BatchResult{Name: item.Name, Series: *item.Series, Err: err}
+				break
+			}
+			result = applySchedule(client, st, plan.Source, *item.Config, clock)
+
+		case PlanDelete:
+			_, err := newWithClock(client, &item.Series.Config, clock).deleteUpcoming(item.Series)
+			if err != nil {
+				st.Put(*item.Series)
+			} else {
+				st.Delete(item.Name)
+			}
+			result = BatchResult{Name: item.Name, Series: *item.Series, Err: err}
+
+		case PlanConflict:
+			result = BatchResult{Name: item.Name, Series: *item.Series, Err: fmt.Errorf("not applied: %s", conflictReason(item.Series))}
+
+		case PlanRepair:
+			rescheduled, err := RescheduleMissing(client, item.Missing, clock.Now())
+			series := *item.Series
+			series.Occurrences = append([]types.Occurrence(nil), item.Series.Occurrences...)
+			for _, occ := range rescheduled {
+				for i := range series.Occurrences {
+					if occurrenceKey(series.Occurrences[i]) == occurrenceKey(occ) {
+						series.Occurrences[i].ScheduledID = occ.ScheduledID
+					}
+				}
+			}
+			st.Put(series)
+			result = BatchResult{Name: item.Name, Series: series, Err: err}
+
+		default:
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// newWithClock creates a scheduler for config whose time comes from clock
+func newWithClock(client *slack.Client, config *types.ScheduleConfig, clock Clock) *Scheduler {
+	s := New(client, config)
+	s.SetClock(clock)
+	return s
+}
+
+// applySchedule schedules a config and records the resulting series in the store
+func applySchedule(client *slack.Client, st *store.Store, source string, config types.ScheduleConfig, clock Clock) BatchResult {
+	slog.Info(fmt.Sprintf("== %s ==", config.Name), "series", config.Name)
+	s := newWithClock(client, &config, clock)
+	_, err := s.Schedule()
+
+	series := s.Series(config.Name)
+	series.Source = source
+	if len(series.Occurrences) > 0 || err == nil {
+		st.Put(series)
+	}
+	return BatchResult{Name: config.Name, Series: series, Err: err}
+}
@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestBuildPlan(t *testing.T) {
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+	standup := types.ScheduleConfig{Name: "standup", Message: "Standup", Channel: "C123", StartDate: "2025-01-13", SendTime: "09:00", Interval: types.IntervalDaily, RepeatCount: 2}
+	retro := types.ScheduleConfig{Name: "retro", Message: "Retro", Channel: "C123", StartDate: "2025-01-17", SendTime: "15:00", Interval: types.IntervalNone}
+	retroMoved := retro
+	retroMoved.SendTime = "16:00"
+	unnamed := types.ScheduleConfig{Message: "Demo", Channel: "C123", StartDate: "2025-01-20", SendTime: "10:00", Interval: types.IntervalNone}
+
+	occ := func(day int, id string) types.Occurrence {
+		return types.Occurrence{Channel: "C123", Message: "Standup", PostAt: time.Date(2025, 1, day, 9, 0, 0, 0, LocalTZ), ScheduledID: id}
+	}
+	existing := []types.Series{
+		{Name: "standup", Source: "team.yaml", Config: standup, Occurrences: []types.Occurrence{occ(13, "Q1"), occ(14, "Q2")}},
+		{Name: "retro", Source: "team.yaml", Config: retro},
+		{Name: "old", Source: "team.yaml", Config: retro},
+		{Name: "manual", Config: retro},
+	}
+
+	tests := []struct {
+		name      string
+		desired   []types.ScheduleConfig
+		scheduled []types.Occurrence
+		want      map[string]PlanAction
+	}{
+		{
+			name:    "create, update, delete and unchanged",
+			desired: []types.ScheduleConfig{standup, retroMoved, unnamed},
+			want: map[string]PlanAction{
+				"standup": PlanUnchanged, "retro": PlanUpdate, "old": PlanDelete, "c123-2025-01-20": PlanCreate,
+			},
+		},
+		{
+			name:      "occurrence deleted in slack is repaired",
+			desired:   []types.ScheduleConfig{standup, retro},
+			scheduled: []types.Occurrence{occ(14, "Q2")},
+			want:      map[string]PlanAction{"standup": PlanRepair, "retro": PlanUnchanged, "old": PlanDelete},
+		},
+		{
+			name:    "name of a series scheduled by hand conflicts",
+			desired: []types.ScheduleConfig{standup, retro, {Name: "manual", Message: "Mine", Channel: "C123", StartDate: "2025-01-20", SendTime: "10:00", Interval: types.IntervalNone}},
+			want:    map[string]PlanAction{"standup": PlanUnchanged, "retro": PlanUnchanged, "old": PlanDelete, "manual": PlanConflict},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := BuildPlan("team.yaml", tt.desired, existing, tt.scheduled, now)
+			if err != nil {
+				t.Fatalf("BuildPlan() error = %v", err)
+			}
+
+			got := make(map[string]PlanAction)
+			for _, item := range plan.Items {
+				got[item.Name] = item.Action
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("BuildPlan() = %v, want %v", got, tt.want)
+			}
+			for name, action := range tt.want {
+				if got[name] != action {
+					t.Errorf("%s: action = %q, want %q", name, got[name], action)
+				}
+			}
+			if !plan.HasChanges() {
+				t.Error("HasChanges() = false, want true")
+			}
+		})
+	}
+
+	if _, err := BuildPlan("team.yaml", []types.ScheduleConfig{retro, retro}, nil, nil, now); err == nil {
+		t.Error("BuildPlan() expected error for duplicate names")
+	}
+}
+
+func TestPlan_Write(t *testing.T) {
+	plan := Plan{Source: "team.yaml", Items: []PlanItem{
+		{Action: PlanCreate, Name: "demo"},
+		{Action: PlanRepair, Name: "standup", Missing: make([]types.Occurrence, 1)},
+		{Action: PlanUnchanged, Name: "retro"},
+		{Action: PlanConflict, Name: "manual", Series: &types.Series{Name: "manual"}},
+	}}
+
+	var buf bytes.Buffer
+	plan.Write(&buf)
+	for _, want := range []string{"+ demo (create)", "! standup (repair): 1 occurrence(s) missing", "1 to create, 0 to update, 0 to delete, 1 to repair, 1 unchanged", "x manual (conflict): name taken by a series scheduled outside this file", "1 name conflict(s)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("plan output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if (Plan{Items: []PlanItem{{Action: PlanUnchanged}}}).HasChanges() {
+		t.Error("HasChanges() = true for a plan with only unchanged items")
+	}
+}
+
+func TestApplyPlan(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	st, err := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	stale := types.Series{Name: "old", Source: "team.yaml", Occurrences: []types.Occurrence{
+		{Channel: "C123", Message: "Old", PostAt: tomorrow, ScheduledID: "Q9"},
+	}}
+	st.Put(stale)
+	seedScheduled(api, stale.Occurrences)
+
+	desired := []types.ScheduleConfig{
+		{Name: "demo", Message: "Demo", Channel: "C123", StartDate: tomorrow.Format("2006-01-02"), SendTime: "10:00", Interval: types.IntervalNone},
+	}
+	plan, err := BuildPlan("team.yaml", desired, st.List(), nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := ApplyPlan(client, st, plan, SystemClock{})
+	if failed := WriteBatchReport(&bytes.Buffer{}, results); failed != 0 {
+		t.Fatalf("ApplyPlan() had %d failures: %+v", failed, results)
+	}
+
+	if _, ok := st.Get("old"); ok {
+		t.Error("deleted series still in store")
+	}
+	demo, ok := st.Get("demo")
+	if !ok || demo.Source != "team.yaml" || len(demo.Occurrences) != 1 {
+		t.Errorf("created series = %+v, %v, want demo from team.yaml with 1 occurrence", demo, ok)
+	}
+
+	// Applying again is a no-op
+	plan, _ = BuildPlan("team.yaml", desired, st.List(), nil, time.Now())
+	if plan.HasChanges() {
+		t.Errorf("second plan has changes: %+v", plan.Items)
+	}
+}
+
+func TestApplyPlan_Conflict(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+	st, _ := store.Open(filepath.Join(t.TempDir(), store.FileName))
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	manual := types.Series{Name: "standup", Occurrences: []types.Occurrence{
+		{Channel: "C123", Message: "Mine", PostAt: tomorrow, ScheduledID: "Q5"},
+	}}
+	st.Put(manual)
+
+	desired := []types.ScheduleConfig{
+		{Name: "standup", Message: "Standup", Channel: "C123", StartDate: tomorrow.Format("2006-01-02"), SendTime: "10:00", Interval: types.IntervalNone},
+	}
+	plan, err := BuildPlan("team.yaml", desired, st.List(), nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := ApplyPlan(client, st, plan, SystemClock{})
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("ApplyPlan() = %+v, want the conflict reported", results)
+	}
+	if got, _ := st.Get("standup"); got.Source != "" || len(got.Occurrences) != 1 || got.Occurrences[0].ScheduledID != "Q5" {
+		t.Errorf("series scheduled by hand = %+v, want it untouched", got)
+	}
+	if scheduleCalls(api) != 0 {
+		t.Errorf("made %d scheduleMessage calls for a conflicting entry", scheduleCalls(api))
+	}
+}
+
+func TestSameConfig(t *testing.T) {
+	a := types.ScheduleConfig{Name: "standup", Days: []types.DayOfWeek{}}
+	b := types.ScheduleConfig{Name: "standup"}
+	if !sameConfig(a, b) {
+		t.Error("sameConfig() = false for empty vs nil days")
+	}
+	b.Days = []types.DayOfWeek{types.Monday}
+	if sameConfig(a, b) {
+		t.Error("sameConfig() = true for different days")
+	}
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/message"
+)
+
+// Preview renders the message as it will be posted now, approximating how Slack
+// displays it: mentions, channels and user groups are shown by name and common
+// emoji as unicode. Color adds ANSI styling for bold, italics, code and quotes.
+func (s *Scheduler) Preview(color bool) (string, error) {
+	text, err := s.textAt(s.currentTime(), 0, 0)
+	if err != nil {
+		return "", err
+	}
+
+	// Names are cosmetic; a missing scope just leaves IDs in the preview
+	opts := message.PreviewOptions{Color: color}
+	opts.Users, _ = s.client.GetUserNameMap()
+	opts.Channels, _ = s.client.GetChannelNameMap()
+	opts.Groups, _ = s.client.GetUserGroupMap()
+
+	return message.RenderPreview(text, opts), nil
+}
+
+// SendPreview posts the message as it will be posted now to a test target for a
+// real visual check: a channel, a user, or the token's own user when target is
+// empty. The schedule's thread is not used.
+func (s *Scheduler) SendPreview(target string) error {
+	if target == "" {
+		self, err := s.client.GetSelfID()
+		if err != nil {
+			return err
+		}
+		target = self
+	}
+	channelID, err := s.client.GetChannelID(target)
+	if err != nil {
+		return err
+	}
+	text, err := s.textAt(s.currentTime(), 0, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range message.Split(text, message.MaxLength) {
+		if _, err := s.client.SendMessage(channelID, part, s.postOptions()...); err != nil {
+			return err
+		}
+	}
+	slog.Info(fmt.Sprintf("Sent preview to %s", channelLabelFor(target)), "channel", target)
+	return nil
+}
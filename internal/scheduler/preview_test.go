@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+// newPreviewWorkspace returns a fake workspace with #general (C777), @alice and
+// @engineering-team
+func newPreviewWorkspace() (*slack.FakeAPI, *slack.Client) {
+	api, client := newFakeWorkspace()
+	addChannel(api, "C777", "general")
+	api.AddUser("alice")
+	api.UserGroups = []goslack.UserGroup{{ID: "S1", Handle: "engineering-team"}}
+	return api, client
+}
+
+// posted formats the messages api was sent as "channel: text thread=ts"
+func posted(api *slack.FakeAPI) []string {
+	var out []string
+	for _, post := range api.Posted {
+		out = append(out, post.Channel+": "+post.Text+" thread="+post.Values.Get("thread_ts"))
+	}
+	return out
+}
+
+func TestScheduler_Preview(t *testing.T) {
+	api, client := newPreviewWorkspace()
+
+	s := New(client, &types.ScheduleConfig{
+		Message: "@alice and @engineering-team: see <#C777> :rocket:", Channel: "#general", ResolveMentions: true,
+	})
+	got, err := s.Preview(false)
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+	if want := "@alice and @engineering-team: see #general 🚀"; got != want {
+		t.Errorf("Preview() = %q, want %q", got, want)
+	}
+	if len(api.Posted) != 0 {
+		t.Errorf("Preview() posted %v, want nothing", posted(api))
+	}
+}
+
+func TestScheduler_SendPreview(t *testing.T) {
+	api, client := newPreviewWorkspace()
+	s := New(client, &types.ScheduleConfig{Message: "hello", Channel: "#general", Thread: "1736931600.000100"})
+
+	if err := s.SendPreview(""); err != nil {
+		t.Fatalf("SendPreview() error = %v", err)
+	}
+	if err := s.SendPreview("#general"); err != nil {
+		t.Fatalf("SendPreview(#general) error = %v", err)
+	}
+
+	// The schedule's thread belongs to the real channel, not the preview target
+	want := []string{"D0SELF: hello thread=", "C777: hello thread="}
+	if got := posted(api); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("posted %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// ReconcileResult describes the drift between locally tracked occurrences and Slack
+type ReconcileResult struct {
+	// Occurrences present both locally and in Slack
+	Matched []types.Occurrence
+
+	// Occurrences tracked locally that are no longer scheduled in Slack
+	// (deleted via another tool, or never scheduled successfully)
+	Missing []types.Occurrence
+
+	// Occurrences scheduled in Slack that aren't tracked locally
+	Untracked []types.Occurrence
+}
+
+// InSync reports whether local state and Slack agree
+func (r ReconcileResult) InSync() bool {
+	return len(r.Missing) == 0 && len(r.Untracked) == 0
+}
+
+// Reconcile compares tracked occurrences against what is actually scheduled in Slack.
+// Occurrences are matched by Slack scheduled message ID first; those left over,
+// including ones without an ID, are then matched by channel, text and post time.
+// Matching IDs first keeps a content match from taking the Slack entry another
+// occurrence holds the ID of.
+func Reconcile(tracked, scheduled []types.Occurrence) ReconcileResult {
+	var result ReconcileResult
+
+	byID := make(map[string]int)
+	byContent := make(map[string][]int)
+	for i, occ := range scheduled {
+		byID[occ.ScheduledID] = i
+		key := occurrenceKey(occ)
+		byContent[key] = append(byContent[key], i)
+	}
+
+	used := make(map[int]bool)
+	matches := make([]int, len(tracked))
+	for i, occ := range tracked {
+		matches[i] = -1
+		if idx, ok := byID[occ.ScheduledID]; ok && occ.ScheduledID != "" && !used[idx] {
+			matches[i] = idx
+			used[idx] = true
+		}
+	}
+	for i, occ := range tracked {
+		if matches[i] >= 0 {
+			continue
+		}
+		for _, candidate := range byContent[occurrenceKey(occ)] {
+			if !used[candidate] {
+				matches[i] = candidate
+				used[candidate] = true
+				break
+			}
+		}
+	}
+
+	for i, occ := range tracked {
+		if matches[i] >= 0 {
+			result.Matched = append(result.Matched, scheduled[matches[i]])
+		} else {
+			result.Missing = append(result.Missing, occ)
+		}
+	}
+
+	for i, occ := range scheduled {
+		if !used[i] {
+			result.Untracked = append(result.Untracked, occ)
+		}
+	}
+
+	return result
+}
+
+func occurrenceKey(occ types.Occurrence) string {
+	return fmt.Sprintf("%s|%d|%s", occ.Channel, occ.PostAt.Unix(), occ.Message)
+}
+
+// RescheduleMissing re-creates missing occurrences in Slack, skipping those whose
+// post time is before now. It returns the occurrences with their new Slack IDs.
+// Forgetting missing occurrences instead is what Cleanup does.
+func RescheduleMissing(client *slack.Client, missing []types.Occurrence, now time.Time) ([]types.Occurrence, error) {
+	var rescheduled []types.Occurrence
+
+	for _, occ := range missing {
+		if occ.PostAt.Before(now) {
+			slog.Info(fmt.Sprintf("Skipping past time: %s", occ.PostAt.In(LocalTZ).Format("2006-01-02 15:04 MST")), "post_at", occ.PostAt)
+			continue
+		}
+
+		id, err := client.ScheduleMessage(occ.Channel, occ.Message, occ.PostAt)
+		if err != nil {
+			return rescheduled, err
+		}
+		occ.ScheduledID = id
+		rescheduled = append(rescheduled, occ)
+	}
+
+	return rescheduled, nil
+}
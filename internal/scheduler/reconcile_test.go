@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestReconcile(t *testing.T) {
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tracked := []types.Occurrence{
+		{Channel: "C1", Message: "standup", PostAt: at, ScheduledID: "Q1"},
+		{Channel: "C1", Message: "standup", PostAt: at.AddDate(0, 0, 1), ScheduledID: "Q2"},
+		{Channel: "C1", Message: "retro", PostAt: at.AddDate(0, 0, 2)},
+	}
+	scheduled := []types.Occurrence{
+		{Channel: "C1", Message: "standup", PostAt: at, ScheduledID: "Q1"},
+		{Channel: "C1", Message: "retro", PostAt: at.AddDate(0, 0, 2), ScheduledID: "Q3"},
+		{Channel: "C2", Message: "other", PostAt: at, ScheduledID: "Q4"},
+	}
+
+	result := Reconcile(tracked, scheduled)
+
+	if len(result.Matched) != 2 {
+		t.Errorf("expected 2 matched, got %d", len(result.Matched))
+	}
+	if len(result.Missing) != 1 || result.Missing[0].ScheduledID != "Q2" {
+		t.Errorf("expected Q2 to be missing, got %+v", result.Missing)
+	}
+	if len(result.Untracked) != 1 || result.Untracked[0].ScheduledID != "Q4" {
+		t.Errorf("expected Q4 to be untracked, got %+v", result.Untracked)
+	}
+	if result.InSync() {
+		t.Error("InSync() = true, want false")
+	}
+}
+
+func TestReconcile_DuplicateContent(t *testing.T) {
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	occ := types.Occurrence{Channel: "C1", Message: "hi", PostAt: at}
+
+	// Two identical local entries but only one in Slack: one must be reported missing
+	result := Reconcile([]types.Occurrence{occ, occ}, []types.Occurrence{
+		{Channel: "C1", Message: "hi", PostAt: at, ScheduledID: "Q1"},
+	})
+
+	if len(result.Matched) != 1 || len(result.Missing) != 1 || len(result.Untracked) != 0 {
+		t.Errorf("matched=%d missing=%d untracked=%d, want 1/1/0",
+			len(result.Matched), len(result.Missing), len(result.Untracked))
+	}
+}
+
+func TestReconcile_IDBeforeContent(t *testing.T) {
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	// The first entry has no ID and would take Q1 by content, leaving the entry
+	// that owns Q1 reported missing
+	result := Reconcile([]types.Occurrence{
+		{Channel: "C1", Message: "hi", PostAt: at},
+		{Channel: "C1", Message: "hi", PostAt: at, ScheduledID: "Q1"},
+	}, []types.Occurrence{
+		{Channel: "C1", Message: "hi", PostAt: at, ScheduledID: "Q1"},
+	})
+
+	if len(result.Matched) != 1 || result.Matched[0].ScheduledID != "Q1" {
+		t.Errorf("Matched = %+v, want Q1", result.Matched)
+	}
+	if len(result.Missing) != 1 || result.Missing[0].ScheduledID != "" {
+		t.Errorf("Missing = %+v, want only the entry without an ID", result.Missing)
+	}
+}
+
+func TestReconcile_InSync(t *testing.T) {
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	occ := types.Occurrence{Channel: "C1", Message: "hi", PostAt: at, ScheduledID: "Q1"}
+
+	if result := Reconcile([]types.Occurrence{occ}, []types.Occurrence{occ}); !result.InSync() {
+		t.Errorf("expected in sync, got %+v", result)
+	}
+	if result := Reconcile(nil, nil); !result.InSync() {
+		t.Error("expected empty inputs to be in sync")
+	}
+}
+
+func TestRescheduleMissing(t *testing.T) {
+	api, client := newFakeWorkspace("C1")
+	at := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	missing := []types.Occurrence{
+		{Channel: "C1", Message: "hi", PostAt: at.AddDate(0, 0, -1), ScheduledID: "Q1"},
+		{Channel: "C1", Message: "hi", PostAt: at.AddDate(0, 0, 1), ScheduledID: "Q2"},
+	}
+
+	// Only the occurrence after the given time comes back, whatever the wall clock says
+	rescheduled, err := RescheduleMissing(client, missing, at)
+	if err != nil {
+		t.Fatalf("RescheduleMissing() error = %v", err)
+	}
+	if len(rescheduled) != 1 || !rescheduled[0].PostAt.Equal(missing[1].PostAt) || rescheduled[0].ScheduledID != api.Scheduled[0].ID {
+		t.Errorf("rescheduled = %+v, want the Jan 16 occurrence with its new ID", rescheduled)
+	}
+}
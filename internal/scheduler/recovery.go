@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// ResumeState returns what a failed Schedule call left undone, recorded under the
+// series name, so ResumeFailed can retry just those occurrences later. It reports
+// false if the last Schedule call didn't leave anything undone.
+func (s *Scheduler) ResumeState(name string, err error) (types.ResumeState, bool) {
+	if len(s.failed) == 0 {
+		return types.ResumeState{}, false
+	}
+	state := types.ResumeState{
+		Series:  s.Series(name),
+		Pending: append([]types.PendingOccurrence(nil), s.failed...),
+	}
+	if err != nil {
+		state.Error = err.Error()
+	}
+	return state, true
+}
+
+// ResumeFailed schedules the occurrences a failed Schedule call left pending,
+// adding them to the state's series. Occurrences now in the past are dropped. What
+// fails again stays pending in state, together with the occurrences never
+// reached, and the error is returned.
+func (s *Scheduler) ResumeFailed(state *types.ResumeState) error {
+	if s.config.RecipientLocal {
+		if err := s.useRecipientTimezone(); err != nil {
+			return err
+		}
+	}
+	channelID, err := s.client.GetChannelID(s.config.Channel)
+	if err != nil {
+		return err
+	}
+
+	now := s.currentTime()
+	pending := state.Pending
+	state.Pending = nil
+	for i, p := range pending {
+		if p.PostAt.Before(now) {
+			slog.Info(fmt.Sprintf("Skipping past time: %s", p.PostAt.Format("2006-01-02 15:04 MST")), "post_at", p.PostAt)
+			continue
+		}
+
+		text, err := s.textAt(p.PostAt, p.Index, p.Total)
+		if err == nil {
+			slog.Info(fmt.Sprintf("[%s] Scheduling message for: %s", state.Series.Name, p.PostAt.Format("2006-01-02 15:04 MST")), "series", state.Series.Name, "post_at", p.PostAt)
+			var occurrences []types.Occurrence
+			occurrences, err = s.scheduleText(channelID, text, p.PostAt, p.Index)
+			state.Series.Occurrences = append(state.Series.Occurrences, occurrences...)
+		}
+		if err != nil {
+			state.Pending = append(state.Pending, pending[i:]...)
+			state.Error = err.Error()
+			return err
+		}
+	}
+	state.Error = ""
+	return nil
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestScheduler_ResumeFailed(t *testing.T) {
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	failAt := fmt.Sprint(time.Date(2025, 1, 8, 9, 0, 0, 0, LocalTZ).Unix())
+	failing := true
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/chat.scheduleMessage" {
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+		r.ParseForm()
+		if failing && r.Form.Get("post_at") == failAt {
+			w.Write([]byte(`{"ok":false,"error":"invalid_time"}`))
+			return
+		}
+		posted = append(posted, r.Form.Get("text"))
+		w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	config := &types.ScheduleConfig{
+		Message: "Standup {{occurrence}}/{{total}}", Template: true, Channel: "C123",
+		StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalDaily, RepeatCount: 4,
+	}
+	s := New(client, config)
+	s.SetClock(NewFakeClock(now))
+	_, err := s.Schedule()
+	if err == nil {
+		t.Fatal("Schedule() expected an error for the third message")
+	}
+
+	state, ok := s.ResumeState("standup", err)
+	if !ok {
+		t.Fatalf("ResumeState() reported nothing to resume after %v", err)
+	}
+	if len(state.Series.Occurrences) != 2 || len(state.Pending) != 2 || state.Error == "" {
+		t.Fatalf("ResumeState() = %d scheduled, %d pending, error %q; want 2, 2 and the error", len(state.Series.Occurrences), len(state.Pending), state.Error)
+	}
+
+	failing = false
+	s = New(client, &state.Series.Config)
+	s.SetClock(NewFakeClock(now))
+	if err := s.ResumeFailed(&state); err != nil {
+		t.Fatalf("ResumeFailed() error = %v", err)
+	}
+	if len(state.Pending) != 0 || len(state.Series.Occurrences) != 4 {
+		t.Errorf("after ResumeFailed: %d pending, %d scheduled; want 0 and 4", len(state.Pending), len(state.Series.Occurrences))
+	}
+	if want := "Standup 3/4"; len(posted) != 4 || posted[2] != want {
+		t.Errorf("posted %q, want the resumed message numbered %q", posted, want)
+	}
+}
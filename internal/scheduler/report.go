@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Report statuses
+const (
+	ReportOK      = "ok"
+	ReportPartial = "partial"
+	ReportFailed  = "failed"
+)
+
+// errorCodes name the exit codes in reports, for wrappers reading the JSON
+// rather than the process status
+var errorCodes = map[int]string{
+	ExitError:           "error",
+	ExitInvalid:         "invalid",
+	ExitAuth:            "auth",
+	ExitChannelNotFound: "channel_not_found",
+	ExitPartial:         "partial",
+}
+
+// Report is the final JSON object a command writes with --output json, on
+// failure as well as success, so wrappers can tell a partial failure from a
+// complete one without parsing the log
+type Report struct {
+	// ReportOK, ReportPartial or ReportFailed
+	Status   string `json:"status"`
+	ExitCode int    `json:"exit_code"`
+
+	// The error the command ended with and its class (see ErrorCode)
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+
+	// One entry per occurrence scheduled or attempted
+	Results []OccurrenceResult `json:"results"`
+}
+
+// OccurrenceResult is the outcome of scheduling one occurrence
+type OccurrenceResult struct {
+	Series  string    `json:"series,omitempty"`
+	Channel string    `json:"channel,omitempty"`
+	PostAt  time.Time `json:"post_at"`
+
+	// "scheduled" or "failed"
+	Status      string `json:"status"`
+	ScheduledID string `json:"scheduled_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ErrorCode names the class of err as reported in JSON: "invalid", "auth",
+// "channel_not_found", "partial" or "error" (empty for nil)
+func ErrorCode(err error) string {
+	return errorCodes[ExitCode(err)]
+}
+
+// NewReport reports a command that ended with err (nil on success)
+func NewReport(results []OccurrenceResult, err error) Report {
+	if results == nil {
+		results = []OccurrenceResult{}
+	}
+	report := Report{Status: ReportOK, ExitCode: ExitCode(err), Results: results}
+	if err != nil {
+		report.Status = ReportFailed
+		if report.ExitCode == ExitPartial {
+			report.Status = ReportPartial
+		}
+		report.Error = err.Error()
+		report.ErrorCode = errorCodes[report.ExitCode]
+	}
+	return report
+}
+
+// Results returns the outcome of each occurrence the last Schedule call
+// scheduled or failed to, for a Report. Like Series, they're attributed to the
+// config's Name or else fallbackName.
+func (s *Scheduler) Results(fallbackName string) []OccurrenceResult {
+	name := s.config.Name
+	if name == "" {
+		name = fallbackName
+	}
+	results := make([]OccurrenceResult, 0, len(s.scheduled)+len(s.failed))
+	for _, occ := range s.scheduled {
+		results = append(results, OccurrenceResult{
+			Series:      name,
+			Channel:     occ.Channel,
+			PostAt:      occ.PostAt,
+			Status:      "scheduled",
+			ScheduledID: occ.ScheduledID,
+		})
+	}
+	for i, occ := range s.failed {
+		results = append(results, OccurrenceResult{
+			Series:  name,
+			Channel: s.failures[i].channelID,
+			PostAt:  occ.PostAt,
+			Status:  "failed",
+			Error:   s.failures[i].reason,
+		})
+	}
+	return results
+}
+
+// failure is where and why an occurrence wasn't scheduled
+type failure struct {
+	channelID string
+	reason    string
+}
+
+// failureReason says why results[i] has nothing scheduled
+func failureReason(results []scheduled, i int) string {
+	if i < len(results) && results[i].err != nil {
+		return results[i].err.Error()
+	}
+	return "not attempted after an earlier failure"
+}
+
+// BatchReport reports a batch or fan-out: "ok" if every entry succeeded,
+// "partial" if only some did (or one failed part way) and "failed" otherwise
+func BatchReport(results []BatchResult) Report {
+	var occurrences []OccurrenceResult
+	var first error
+	for _, r := range results {
+		occurrences = append(occurrences, r.Results...)
+		if r.Err != nil && first == nil {
+			first = r.Err
+		}
+	}
+	report := NewReport(occurrences, first)
+	report.ExitCode = BatchExitCode(results)
+	report.ErrorCode = errorCodes[report.ExitCode]
+	switch report.ExitCode {
+	case ExitOK:
+		report.Status = ReportOK
+	case ExitPartial:
+		report.Status = ReportPartial
+	default:
+		report.Status = ReportFailed
+	}
+	return report
+}
+
+// WriteReportJSON writes a report as indented JSON
+func WriteReportJSON(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
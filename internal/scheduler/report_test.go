@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestSchedulerResults_PartialFailure(t *testing.T) {
+	api := &failingAfterAPI{FakeAPI: slack.NewFakeAPI(), n: 1}
+	general := api.AddChannel("general")
+	s := New(slack.NewClientWithAPI(api), &types.ScheduleConfig{
+		Message: "standup", Channel: "#general", StartDate: "2025-01-06", SendTime: "09:00",
+		Interval: types.IntervalDaily, RepeatCount: 3,
+	})
+	s.SetClock(NewFakeClock(time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)))
+	_, err := s.Schedule()
+
+	report := NewReport(s.Results("standup"), err)
+	if report.Status != ReportPartial || report.ExitCode != ExitPartial || report.ErrorCode != "partial" {
+		t.Errorf("report = %s/%d/%s, want partial/%d/partial", report.Status, report.ExitCode, report.ErrorCode, ExitPartial)
+	}
+	if len(report.Results) != 3 {
+		t.Fatalf("%d results, want 3: %+v", len(report.Results), report.Results)
+	}
+	first, second := report.Results[0], report.Results[1]
+	if first.Status != "scheduled" || first.ScheduledID == "" || first.Channel != general || first.Series != "standup" {
+		t.Errorf("first result = %+v, want scheduled in %s", first, general)
+	}
+	if second.Status != "failed" || second.Error == "" || second.Channel != general {
+		t.Errorf("second result = %+v, want failed with an error", second)
+	}
+	if !second.PostAt.Equal(time.Date(2025, 1, 7, 9, 0, 0, 0, LocalTZ)) {
+		t.Errorf("second result post_at = %v, want Jan 7 09:00", second.PostAt)
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	ok := NewReport(nil, nil)
+	if ok.Status != ReportOK || ok.ExitCode != ExitOK || ok.Error != "" || ok.Results == nil {
+		t.Errorf("success report = %+v", ok)
+	}
+
+	failed := NewReport(nil, Invalid(errors.New("bad date")))
+	if failed.Status != ReportFailed || failed.ExitCode != ExitInvalid || failed.ErrorCode != "invalid" || failed.Error != "bad date" {
+		t.Errorf("failure report = %+v", failed)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReportJSON(&buf, failed); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if decoded["status"] != "failed" || decoded["error_code"] != "invalid" || decoded["exit_code"] != float64(ExitInvalid) {
+		t.Errorf("JSON = %v", decoded)
+	}
+	if results, ok := decoded["results"].([]interface{}); !ok || len(results) != 0 {
+		t.Errorf("results = %v, want an empty array", decoded["results"])
+	}
+}
+
+func TestBatchReport(t *testing.T) {
+	scheduled := OccurrenceResult{Series: "a", Status: "scheduled", ScheduledID: "Q1"}
+	results := []BatchResult{
+		{Name: "a", Results: []OccurrenceResult{scheduled}},
+		{Name: "b", Err: errors.New("channel not found: random")},
+	}
+	report := BatchReport(results)
+	if report.Status != ReportPartial || report.ExitCode != ExitPartial || len(report.Results) != 1 {
+		t.Errorf("mixed batch report = %+v", report)
+	}
+	if report.Error != "channel not found: random" {
+		t.Errorf("error = %q, want the failed entry's", report.Error)
+	}
+
+	report = BatchReport(results[:1])
+	if report.Status != ReportOK || report.Error != "" {
+		t.Errorf("successful batch report = %+v", report)
+	}
+}
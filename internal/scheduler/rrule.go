@@ -0,0 +1,429 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RRule is a parsed RFC 5545 recurrence rule. The supported subset is
+// FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL, BYDAY
+// (with ordinals like 1MO or -1FR for monthly/yearly rules, counted within the
+// year in yearly rules without BYMONTH), BYMONTHDAY, BYMONTH, BYHOUR, BYMINUTE,
+// BYSETPOS and WKST.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []rruleDay
+	ByMonthDay []int
+	ByMonth    []int
+	ByHour     []int
+	ByMinute   []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// rruleDay is a BYDAY entry: a weekday with an optional ordinal (0 = every)
+type rruleDay struct {
+	Weekday time.Weekday
+	Nth     int
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// ParseRRule parses an iCalendar RRULE such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" is accepted. UNTIL dates are interpreted in loc unless they end in Z.
+func ParseRRule(rule string, loc *time.Location) (*RRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r := &RRule{Interval: 1, WeekStart: time.Monday}
+
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE part: %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+			switch r.Freq {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+			default:
+				return nil, fmt.Errorf("unsupported RRULE FREQ: %s (use DAILY, WEEKLY, MONTHLY or YEARLY)", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("must be 1 or greater")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("must be 1 or greater")
+			}
+		case "UNTIL":
+			var until time.Time
+			until, err = parseRRuleUntil(value, loc)
+			r.Until = &until
+		case "BYDAY":
+			r.ByDay, err = parseRRuleDays(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseRRuleInts(value, -31, 31, true)
+		case "BYMONTH":
+			r.ByMonth, err = parseRRuleInts(value, 1, 12, false)
+		case "BYHOUR":
+			r.ByHour, err = parseRRuleInts(value, 0, 23, false)
+		case "BYMINUTE":
+			r.ByMinute, err = parseRRuleInts(value, 0, 59, false)
+		case "BYSETPOS":
+			r.BySetPos, err = parseRRuleInts(value, -366, 366, true)
+		case "WKST":
+			wd, ok := rruleWeekdays[strings.ToUpper(value)]
+			if !ok {
+				err = fmt.Errorf("invalid weekday")
+			}
+			r.WeekStart = wd
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part: %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %s=%s: %w", key, value, err)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("RRULE must include FREQ")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, fmt.Errorf("RRULE cannot have both COUNT and UNTIL")
+	}
+	if r.Freq == "YEARLY" && len(r.ByMonth) == 0 && len(r.ByMonthDay) > 0 && r.hasOrdinals() {
+		// BYDAY only limits BYMONTHDAY here, and RFC 5545 doesn't say what an
+		// ordinal means when it does
+		return nil, fmt.Errorf("RRULE BYDAY ordinals can't be combined with BYMONTHDAY in a yearly rule without BYMONTH")
+	}
+	return r, nil
+}
+
+func parseRRuleUntil(value string, loc *time.Location) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		if t, err := time.Parse("20060102T150405Z", value); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("20060102", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("use YYYYMMDD or YYYYMMDDTHHMMSS[Z]")
+	}
+	// A date-only UNTIL includes the whole day
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+func parseRRuleDays(value string) ([]rruleDay, error) {
+	var days []rruleDay
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if len(part) < 2 {
+			return nil, fmt.Errorf("invalid day %q", part)
+		}
+		wd, ok := rruleWeekdays[part[len(part)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", part)
+		}
+		day := rruleDay{Weekday: wd}
+		if prefix := part[:len(part)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid day ordinal %q", part)
+			}
+			day.Nth = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseRRuleInts(value string, min, max int, nonZero bool) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < min || n > max || (nonZero && n == 0) {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// Times expands the rule from dtstart, stopping at the rule's COUNT/UNTIL, at end
+// (if non-nil), or at limit occurrences (if > 0), whichever comes first.
+// Without an end, expansion never runs more than 10 years past dtstart; with one,
+// it runs up to end however long ago dtstart was, so old recurring events still
+// have their current instances.
+func (r *RRule) Times(dtstart time.Time, end *time.Time, limit int) []time.Time {
+	var times []time.Time
+	hardStop := dtstart.AddDate(10, 0, 0)
+	if end != nil {
+		hardStop = *end
+	}
+
+	for period := 0; ; period += r.Interval {
+		periodStart := r.periodStart(dtstart, period)
+		if periodStart.After(hardStop) || (end != nil && periodStart.After(*end)) ||
+			(r.Until != nil && periodStart.After(*r.Until)) {
+			break
+		}
+
+		for _, t := range r.applySetPos(r.candidates(dtstart, periodStart)) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if (r.Until != nil && t.After(*r.Until)) || (end != nil && t.After(*end)) {
+				return times
+			}
+			times = append(times, t)
+			if (r.Count > 0 && len(times) >= r.Count) || (limit > 0 && len(times) >= limit) {
+				return times
+			}
+		}
+	}
+
+	return times
+}
+
+// periodStart returns the first day of the n-th period after dtstart's period
+func (r *RRule) periodStart(dtstart time.Time, n int) time.Time {
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	switch r.Freq {
+	case "DAILY":
+		return time.Date(y, m, d+n, 0, 0, 0, 0, loc)
+	case "WEEKLY":
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return time.Date(y, m, d-offset+7*n, 0, 0, 0, 0, loc)
+	case "MONTHLY":
+		return time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, loc)
+	default: // YEARLY
+		return time.Date(y+n, 1, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// candidates returns the sorted occurrence times within one period
+func (r *RRule) candidates(dtstart, periodStart time.Time) []time.Time {
+	var days []time.Time
+
+	switch r.Freq {
+	case "DAILY":
+		days = []time.Time{periodStart}
+	case "WEEKLY":
+		if len(r.ByDay) == 0 {
+			days = []time.Time{periodStart.AddDate(0, 0, (int(dtstart.Weekday())-int(periodStart.Weekday())+7)%7)}
+		} else {
+			for i := 0; i < 7; i++ {
+				days = append(days, periodStart.AddDate(0, 0, i))
+			}
+		}
+	case "MONTHLY":
+		days = r.monthDays(dtstart, periodStart)
+	default: // YEARLY
+		days = r.yearDays(dtstart, periodStart)
+	}
+
+	var times []time.Time
+	for _, day := range days {
+		if !r.matchesFilters(day) {
+			continue
+		}
+		for _, hour := range r.hours(dtstart) {
+			for _, minute := range r.minutes(dtstart) {
+				times = append(times, time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()))
+			}
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// yearDays expands a yearly rule within one year, per RFC 5545: BYMONTH picks
+// the months BYMONTHDAY and BYDAY apply in; without it BYMONTHDAY applies in
+// every month, BYDAY alone across the whole year, and with neither the rule
+// recurs on dtstart's month and day
+func (r *RRule) yearDays(dtstart, yearStart time.Time) []time.Time {
+	monthStart := func(m time.Month) time.Time {
+		return time.Date(yearStart.Year(), m, 1, 0, 0, 0, 0, yearStart.Location())
+	}
+
+	var days []time.Time
+	switch {
+	case len(r.ByMonth) > 0:
+		for _, m := range r.ByMonth {
+			days = append(days, r.monthDays(dtstart, monthStart(time.Month(m)))...)
+		}
+	case len(r.ByMonthDay) > 0:
+		for m := time.January; m <= time.December; m++ {
+			days = append(days, r.monthDays(dtstart, monthStart(m))...)
+		}
+	case len(r.ByDay) > 0:
+		last := yearStart.AddDate(1, 0, -1).YearDay()
+		for d := 1; d <= last; d++ {
+			day := yearStart.AddDate(0, 0, d-1)
+			if matchesByDay(r.ByDay, day.Weekday(), d, last) {
+				days = append(days, day)
+			}
+		}
+	default:
+		days = r.monthDays(dtstart, monthStart(dtstart.Month()))
+	}
+	return days
+}
+
+// hasOrdinals reports whether any BYDAY entry has an ordinal, like 1MO
+func (r *RRule) hasOrdinals() bool {
+	for _, bd := range r.ByDay {
+		if bd.Nth != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays expands BYMONTHDAY/BYDAY within a single month
+func (r *RRule) monthDays(dtstart, monthStart time.Time) []time.Time {
+	last := daysInMonth(monthStart.Year(), monthStart.Month())
+	dayOf := func(d int) time.Time {
+		return time.Date(monthStart.Year(), monthStart.Month(), d, 0, 0, 0, 0, monthStart.Location())
+	}
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		// Months without dtstart's day are skipped, per RFC 5545
+		if dtstart.Day() > last {
+			return nil
+		}
+		return []time.Time{dayOf(dtstart.Day())}
+	}
+
+	var days []time.Time
+	for d := 1; d <= last; d++ {
+		day := dayOf(d)
+		if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, d, last) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !matchesByDay(r.ByDay, day.Weekday(), d, last) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func matchesMonthDay(monthDays []int, d, last int) bool {
+	for _, md := range monthDays {
+		if md == d || (md < 0 && last+md+1 == d) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesByDay checks a day against BYDAY entries, with ordinals relative to the
+// period (month or year) it is day number d of last
+func matchesByDay(byDay []rruleDay, weekday time.Weekday, d, last int) bool {
+	for _, bd := range byDay {
+		if weekday != bd.Weekday {
+			continue
+		}
+		if bd.Nth == 0 {
+			return true
+		}
+		nthFromStart := (d-1)/7 + 1
+		nthFromEnd := -((last-d)/7 + 1)
+		if bd.Nth == nthFromStart || bd.Nth == nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilters applies BYMONTH and, for daily/weekly rules, BYDAY and BYMONTHDAY
+func (r *RRule) matchesFilters(day time.Time) bool {
+	if len(r.ByMonth) > 0 {
+		found := false
+		for _, m := range r.ByMonth {
+			if int(day.Month()) == m {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if r.Freq == "DAILY" || r.Freq == "WEEKLY" {
+		if len(r.ByDay) > 0 {
+			found := false
+			for _, bd := range r.ByDay {
+				if day.Weekday() == bd.Weekday {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, day.Day(), daysInMonth(day.Year(), day.Month())) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *RRule) hours(dtstart time.Time) []int {
+	if len(r.ByHour) > 0 {
+		return r.ByHour
+	}
+	return []int{dtstart.Hour()}
+}
+
+func (r *RRule) minutes(dtstart time.Time) []int {
+	if len(r.ByMinute) > 0 {
+		return r.ByMinute
+	}
+	return []int{dtstart.Minute()}
+}
+
+// applySetPos keeps only the BYSETPOS positions (1-based, negative from the end)
+func (r *RRule) applySetPos(times []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 {
+		return times
+	}
+
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		idx := pos - 1
+		if pos < 0 {
+			idx = len(times) + pos
+		}
+		if idx >= 0 && idx < len(times) && !containsTime(selected, times[idx]) {
+			selected = append(selected, times[idx])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestParseRRule_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=SECONDLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTHDAY=13;BYDAY=1FR",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;FOO=BAR",
+		"FREQ",
+	}
+
+	for _, rule := range tests {
+		t.Run(rule, func(t *testing.T) {
+			if _, err := ParseRRule(rule, time.UTC); err == nil {
+				t.Errorf("ParseRRule(%q) expected error, got nil", rule)
+			}
+		})
+	}
+}
+
+func TestRRule_Times(t *testing.T) {
+	dtstart := time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		name string
+		rule string
+		want []string
+	}{
+		{
+			name: "weekly on monday and wednesday",
+			rule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			want: []string{"2025-01-13 09:00", "2025-01-15 09:00", "2025-01-20 09:00", "2025-01-22 09:00"},
+		},
+		{
+			name: "biweekly with interval",
+			rule: "RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			want: []string{"2025-01-13 09:00", "2025-01-27 09:00", "2025-02-10 09:00"},
+		},
+		{
+			name: "daily until date inclusive",
+			rule: "FREQ=DAILY;UNTIL=20250115",
+			want: []string{"2025-01-13 09:00", "2025-01-14 09:00", "2025-01-15 09:00"},
+		},
+		{
+			name: "last friday of the month",
+			rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			want: []string{"2025-01-31 09:00", "2025-02-28 09:00", "2025-03-28 09:00"},
+		},
+		{
+			name: "second tuesday of the month",
+			rule: "FREQ=MONTHLY;BYDAY=2TU;COUNT=2",
+			want: []string{"2025-01-14 09:00", "2025-02-11 09:00"},
+		},
+		{
+			name: "last weekday of the month via BYSETPOS",
+			rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3",
+			want: []string{"2025-01-31 09:00", "2025-02-28 09:00", "2025-03-31 09:00"},
+		},
+		{
+			name: "31st skips short months",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+			want: []string{"2025-01-31 09:00", "2025-03-31 09:00", "2025-05-31 09:00"},
+		},
+		{
+			name: "last day of month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=2",
+			want: []string{"2025-01-31 09:00", "2025-02-28 09:00"},
+		},
+		{
+			name: "weekdays at 9 and 17",
+			rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,17;COUNT=3",
+			want: []string{"2025-01-13 09:00", "2025-01-13 17:00", "2025-01-14 09:00"},
+		},
+		{
+			name: "yearly in march on the first",
+			rule: "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1;COUNT=2",
+			want: []string{"2025-03-01 09:00", "2026-03-01 09:00"},
+		},
+		{
+			name: "yearly ordinals count within the year",
+			rule: "FREQ=YEARLY;BYDAY=20MO;COUNT=2",
+			want: []string{"2025-05-19 09:00", "2026-05-18 09:00"},
+		},
+		{
+			name: "last monday of the year",
+			rule: "FREQ=YEARLY;BYDAY=-1MO;COUNT=2",
+			want: []string{"2025-12-29 09:00", "2026-12-28 09:00"},
+		},
+		{
+			name: "yearly ordinals count within BYMONTH",
+			rule: "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=2",
+			want: []string{"2025-11-27 09:00", "2026-11-26 09:00"},
+		},
+		{
+			name: "yearly BYMONTHDAY applies in every month",
+			rule: "FREQ=YEARLY;BYMONTHDAY=1;COUNT=3",
+			want: []string{"2025-02-01 09:00", "2025-03-01 09:00", "2025-04-01 09:00"},
+		},
+		{
+			name: "friday the 13th",
+			rule: "FREQ=YEARLY;BYMONTHDAY=13;BYDAY=FR;COUNT=2",
+			want: []string{"2025-06-13 09:00", "2026-02-13 09:00"},
+		},
+		{
+			name: "yearly on dtstart's date",
+			rule: "FREQ=YEARLY;COUNT=2",
+			want: []string{"2025-01-13 09:00", "2026-01-13 09:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error = %v", tt.rule, err)
+			}
+
+			times := rule.Times(dtstart, nil, 0)
+			if len(times) != len(tt.want) {
+				t.Fatalf("got %d times, want %d: %v", len(times), len(tt.want), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02 15:04"); got != tt.want[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_RRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *types.ScheduleConfig
+		wantDays []string
+	}{
+		{
+			name: "rule count",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13",
+				SendTime:  "09:00",
+				RRule:     "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3",
+			},
+			wantDays: []string{"2025-01-13", "2025-01-15", "2025-01-20"},
+		},
+		{
+			name: "end date bounds an open rule",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13",
+				SendTime:  "09:00",
+				EndDate:   "2025-01-16",
+				RRule:     "FREQ=DAILY",
+			},
+			wantDays: []string{"2025-01-13", "2025-01-14", "2025-01-15", "2025-01-16"},
+		},
+		{
+			name: "repeat count bounds the rule",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-13",
+				SendTime:    "09:00",
+				RepeatCount: 2,
+				RRule:       "FREQ=DAILY;COUNT=10",
+			},
+			wantDays: []string{"2025-01-13", "2025-01-14"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+			if len(times) != len(tt.wantDays) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.wantDays), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02"); got != tt.wantDays[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.wantDays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_OpenRRuleWindow(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate: "2025-01-01",
+		SendTime:  "09:00",
+		RRule:     "FREQ=DAILY",
+	}
+
+	times, err := newTestScheduler(config).CalculateScheduleTimes()
+	if err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+	// Jan 1 through May 1 inclusive (start + 120 days lands exactly on May 1 09:00)
+	if len(times) != MaxScheduleDays+1 {
+		t.Errorf("got %d times, want %d", len(times), MaxScheduleDays+1)
+	}
+}
@@ -1,16 +1,36 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/message"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/notify"
 	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
 	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
 )
 
 // LocalTZ is the user's local timezone
 var LocalTZ *time.Location
 
+// MaxScheduleDays is how far in advance Slack allows messages to be scheduled
+const MaxScheduleDays = 120
+
+// ConfirmThreshold is how many messages Schedule will create without asking first
+const ConfirmThreshold = 20
+
+// MaxHourlyOccurrences caps hourly schedules so a loose end date can't flood a channel
+const MaxHourlyOccurrences = 72
+
+// SplitSpacing separates the parts of a message split for length, so they post in order
+const SplitSpacing = time.Minute
+
 func init() {
 	LocalTZ = time.Local
 }
@@ -19,6 +39,46 @@ func init() {
 type Scheduler struct {
 	client *slack.Client
 	config *types.ScheduleConfig
+
+	// Occurrences removed by ExcludeDates during the last calculation
+	excluded []time.Time
+
+	// Occurrences successfully scheduled by the last Schedule call
+	scheduled []types.Occurrence
+
+	// Occurrences the last Schedule call failed to schedule or never got to
+	failed []types.PendingOccurrence
+
+	// Where and why each of failed wasn't scheduled, in the same order
+	failures []failure
+
+	// Wall-clock send time every occurrence is rebuilt from (set in calculateTimes)
+	sendClock time.Time
+
+	// Clock used for relative schedules and past/future checks, and whose time zone
+	// occurrences are calculated in (SystemClock if nil)
+	clock Clock
+
+	// Time zone overriding the clock's, e.g. a DM recipient's
+	loc *time.Location
+
+	// Do everything but the chat.scheduleMessage calls
+	dryRun bool
+
+	// Asked before scheduling more than ConfirmThreshold messages (never asked if nil)
+	confirm func(prompt string) bool
+
+	// Mentions for RotateUsers, in turn order (resolved on first use)
+	assignees []string
+
+	// Whether the escaped-broadcast warning has been logged
+	warnedBroadcast bool
+
+	// How many chat.scheduleMessage calls Schedule makes at once (one if <= 1)
+	concurrency int
+
+	// Told the outcome of each Schedule call (nil for none)
+	notifier notify.Notifier
 }
 
 // New creates a new scheduler
@@ -29,26 +89,225 @@ func New(client *slack.Client, config *types.ScheduleConfig) *Scheduler {
 	}
 }
 
-// CalculateScheduleTimes returns all the times when messages should be sent
+// SetClock replaces the wall clock, e.g. with a FakeClock in tests
+func (s *Scheduler) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// SetLocation calculates occurrences in loc instead of the clock's time zone, e.g.
+// for a configured default timezone (nil keeps the clock's). Schedules sent at
+// the recipient's local time still use the recipient's.
+func (s *Scheduler) SetLocation(loc *time.Location) {
+	s.loc = loc
+}
+
+// SetNotifier reports the outcome of each Schedule call, e.g. to webhooks
+func (s *Scheduler) SetNotifier(n notify.Notifier) {
+	s.notifier = n
+}
+
+// SetConcurrency sets how many messages Schedule schedules at a time. Rate limited
+// calls are retried by the client, so a handful of workers is safe.
+func (s *Scheduler) SetConcurrency(workers int) {
+	s.concurrency = workers
+}
+
+// SetConfirm sets the yes/no prompt Schedule uses before scheduling more than
+// ConfirmThreshold messages; nil (the default, e.g. for --yes) never asks
+func (s *Scheduler) SetConfirm(confirm func(prompt string) bool) {
+	s.confirm = confirm
+}
+
+// SetDryRun makes Schedule resolve the channel, calculate and preview the
+// occurrences but not actually schedule anything
+func (s *Scheduler) SetDryRun(dryRun bool) {
+	s.dryRun = dryRun
+}
+
+// CalculateScheduleTimes returns all the times when messages should be sent.
+// Occurrences on excluded dates are left out and can be inspected with Excluded;
+// those during quiet hours are postponed to their end.
 func (s *Scheduler) CalculateScheduleTimes() ([]time.Time, error) {
-	// Parse start date and time
-	startDateTime, err := s.parseDateTime(s.config.StartDate, s.config.SendTime)
+	s.excluded = nil
+
+	times, err := s.calculateTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	if times, err = s.removeExcluded(times); err != nil {
+		return nil, err
+	}
+	return s.applyQuietHours(times)
+}
+
+// applyQuietHours moves occurrences inside the quiet hours window to the moment
+// it ends, dropping any that then land on a time already taken
+func (s *Scheduler) applyQuietHours(times []time.Time) ([]time.Time, error) {
+	if s.config.QuietHours == "" {
+		return times, nil
+	}
+	start, end, err := types.ParseQuietHours(s.config.QuietHours)
 	if err != nil {
 		return nil, err
 	}
 
+	// endOn is when the window ends on the given day, built from the wall clock
+	// so it holds across DST transitions
+	endOn := func(year int, month time.Month, day int, loc *time.Location) time.Time {
+		return time.Date(year, month, day, int(end/time.Hour), int(end%time.Hour/time.Minute), 0, 0, loc)
+	}
+
+	kept := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		switch {
+		case start < end && offset >= start && offset < end,
+			start > end && offset < end:
+			t = endOn(t.Year(), t.Month(), t.Day(), t.Location())
+		case start > end && offset >= start:
+			// The window ends the next morning
+			t = endOn(t.Year(), t.Month(), t.Day()+1, t.Location())
+		}
+		if !containsTime(kept, t) {
+			kept = append(kept, t)
+		}
+	}
+	return kept, nil
+}
+
+// Excluded returns the occurrences dropped by ExcludeDates in the last calculation
+func (s *Scheduler) Excluded() []time.Time {
+	return s.excluded
+}
+
+func (s *Scheduler) removeExcluded(times []time.Time) ([]time.Time, error) {
+	if len(s.config.ExcludeDates) == 0 {
+		return times, nil
+	}
+
+	blackout := make(map[string]bool)
+	for _, d := range s.config.ExcludeDates {
+		if _, err := time.ParseInLocation("2006-01-02", d, s.location()); err != nil {
+			return nil, fmt.Errorf("failed to parse excluded date: %w", err)
+		}
+		blackout[d] = true
+	}
+
+	kept := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		if blackout[t.Format("2006-01-02")] {
+			s.excluded = append(s.excluded, t)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, nil
+}
+
+func (s *Scheduler) calculateTimes() ([]time.Time, error) {
+	if s.config.Thread != "" {
+		if _, _, err := slack.ParseThread(s.config.Thread); err != nil {
+			return nil, err
+		}
+	} else if s.config.AlsoToChannel {
+		return nil, fmt.Errorf("also-to-channel requires a thread")
+	}
+	if len(s.config.RotateMessages) > 0 && s.config.Message != "" {
+		return nil, fmt.Errorf("message and rotate-messages cannot be used together")
+	}
+	for i, body := range s.config.RotateMessages {
+		if strings.TrimSpace(body) == "" {
+			return nil, fmt.Errorf("rotating message %d is empty", i+1)
+		}
+	}
+	for i, user := range s.config.RotateUsers {
+		if strings.TrimPrefix(strings.TrimSpace(user), "@") == "" {
+			return nil, fmt.Errorf("assignee %d is empty", i+1)
+		}
+	}
+	if s.config.Raw && (len(s.config.RotateUsers) > 0 || s.config.ResolveMentions) {
+		return nil, fmt.Errorf("raw messages cannot contain mentions (rotate-users or resolve-mentions)")
+	}
+	if s.config.Template {
+		for _, body := range append([]string{s.config.Message}, s.config.RotateMessages...) {
+			if err := message.ParseTemplate(body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// A relative delay replaces the date/time flags with a single message
+	if s.config.In != "" {
+		return s.calculateRelativeTime()
+	}
+
 	// Parse end date if provided (set to end of day)
 	var endDateTime *time.Time
 	if s.config.EndDate != "" {
-		end, err := time.ParseInLocation("2006-01-02", s.config.EndDate, LocalTZ)
+		end, err := time.ParseInLocation("2006-01-02", s.config.EndDate, s.location())
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse end date: %w", err)
 		}
 		// Set to end of day (23:59:59)
-		endOfDay := time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, LocalTZ)
+		endOfDay := time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, s.location())
 		endDateTime = &endOfDay
 	}
 
+	if !s.config.WeekendPolicy.IsValid() {
+		return nil, fmt.Errorf("invalid weekend policy: %s (use: skip, previous, next)", s.config.WeekendPolicy)
+	}
+
+	if s.config.Anchor != "" {
+		if _, err := time.ParseInLocation("2006-01-02", s.config.Anchor, s.location()); err != nil {
+			return nil, fmt.Errorf("failed to parse anchor date: %w", err)
+		}
+	}
+
+	if s.config.Every < 0 {
+		return nil, fmt.Errorf("invalid interval multiplier: %d (must be 1 or greater)", s.config.Every)
+	}
+
+	// A cron expression replaces the interval and send time entirely
+	if s.config.Cron != "" {
+		return s.calculateCronTimes(endDateTime)
+	}
+
+	for _, d := range s.config.Days {
+		if !d.IsValid() {
+			return nil, fmt.Errorf("invalid day of week: %s (use: mon,tue,wed,thu,fri,sat,sun)", d)
+		}
+	}
+	for d, tm := range s.config.DayTimes {
+		if !d.IsValid() {
+			return nil, fmt.Errorf("invalid day of week: %s (use: mon,tue,wed,thu,fri,sat,sun)", d)
+		}
+		if _, err := time.Parse("15:04", tm); err != nil {
+			return nil, fmt.Errorf("invalid time for %s: %s (use HH:MM, 24-hour)", d, tm)
+		}
+	}
+
+	// Per-day times may stand in for a single send time, as long as every day
+	// the series is sent on has one
+	sendTime := s.config.SendTime
+	if sendTime == "" && len(s.config.DayTimes) > 0 {
+		if err := checkDayTimes(s.config); err != nil {
+			return nil, err
+		}
+		sendTime = "00:00"
+	}
+
+	// Parse start date and time
+	startDateTime, err := s.parseDateTime(s.config.StartDate, sendTime)
+	if err != nil {
+		return nil, err
+	}
+	s.sendClock, _ = time.Parse("15:04", sendTime)
+
+	if s.config.RRule != "" {
+		return s.calculateRRuleTimes(startDateTime, endDateTime)
+	}
+
 	var times []time.Time
 
 	switch s.config.Interval {
@@ -56,6 +315,12 @@ func (s *Scheduler) CalculateScheduleTimes() ([]time.Time, error) {
 		// Single message
 		times = append(times, startDateTime)
 
+	case types.IntervalHourly:
+		times, err = s.calculateHourlyTimes(startDateTime, endDateTime)
+		if err != nil {
+			return nil, err
+		}
+
 	case types.IntervalDaily:
 		times = s.calculateDailyTimes(startDateTime, endDateTime)
 
@@ -63,7 +328,10 @@ func (s *Scheduler) CalculateScheduleTimes() ([]time.Time, error) {
 		times = s.calculateWeeklyTimes(startDateTime, endDateTime)
 
 	case types.IntervalMonthly:
-		times = s.calculateMonthlyTimes(startDateTime, endDateTime)
+		times, err = s.calculateMonthlyTimes(startDateTime, endDateTime)
+		if err != nil {
+			return nil, err
+		}
 
 	default:
 		return nil, fmt.Errorf("invalid interval: %s", s.config.Interval)
@@ -72,18 +340,123 @@ func (s *Scheduler) CalculateScheduleTimes() ([]time.Time, error) {
 	return times, nil
 }
 
+// checkDayTimes reports a schedule that relies on per-day times for its send
+// time but would post on a day that has none
+func checkDayTimes(config *types.ScheduleConfig) error {
+	if config.Interval != types.IntervalWeekly || config.RRule != "" {
+		return fmt.Errorf("per-day times only apply to weekly schedules; set a send time")
+	}
+	for _, d := range config.Days {
+		if _, ok := config.DayTimes[d]; !ok {
+			return fmt.Errorf("no time given for %s: add one to the per-day times or set a send time", d)
+		}
+	}
+	return nil
+}
+
+// location returns the time zone dates and send times are interpreted in
+func (s *Scheduler) location() *time.Location {
+	if s.loc != nil {
+		return s.loc
+	}
+	if loc := s.getClock().Location(); loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+func (s *Scheduler) getClock() Clock {
+	if s.clock == nil {
+		return SystemClock{}
+	}
+	return s.clock
+}
+
+// currentTime returns the scheduler's notion of now in the schedule's time zone
+func (s *Scheduler) currentTime() time.Time {
+	return s.getClock().Now().In(s.location())
+}
+
+// calculateRelativeTime returns the single occurrence "In" from now, rejecting
+// delays beyond what Slack can schedule
+func (s *Scheduler) calculateRelativeTime() ([]time.Time, error) {
+	d, err := time.ParseDuration(s.config.In)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse relative time: %w", err)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("invalid relative time: %s (must be in the future)", s.config.In)
+	}
+
+	now := s.currentTime()
+	t := now.Add(d)
+	if t.After(now.AddDate(0, 0, MaxScheduleDays)) {
+		return nil, fmt.Errorf("relative time %s is more than %d days in the future", s.config.In, MaxScheduleDays)
+	}
+	return []time.Time{t}, nil
+}
+
+// step returns the interval multiplier, treating an unset value as 1
+func (s *Scheduler) step() int {
+	if s.config.Every < 1 {
+		return 1
+	}
+	return s.config.Every
+}
+
 func (s *Scheduler) parseDateTime(date, timeStr string) (time.Time, error) {
 	dateTimeStr := fmt.Sprintf("%s %s", date, timeStr)
-	t, err := time.ParseInLocation("2006-01-02 15:04", dateTimeStr, LocalTZ)
+	t, err := time.ParseInLocation("2006-01-02 15:04", dateTimeStr, s.location())
 	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to parse date/time: %w", err)
 	}
 	return t, nil
 }
 
-func (s *Scheduler) calculateDailyTimes(start time.Time, endDate *time.Time) []time.Time {
+// calculateCronTimes expands the cron expression from the start date (or now, if no
+// start date is given) until the end date, or MaxScheduleDays if there is none
+func (s *Scheduler) calculateCronTimes(endDate *time.Time) ([]time.Time, error) {
+	spec, err := ParseCron(s.config.Cron)
+	if err != nil {
+		return nil, err
+	}
+
+	start := s.currentTime()
+	if s.config.StartDate != "" {
+		start, err = time.ParseInLocation("2006-01-02", s.config.StartDate, s.location())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse start date: %w", err)
+		}
+	}
+
+	end := start.AddDate(0, 0, MaxScheduleDays)
+	if endDate != nil {
+		end = *endDate
+	}
+
+	return spec.Times(start, end, s.config.RepeatCount), nil
+}
+
+// calculateRRuleTimes expands the recurrence rule from the start date/time. Rules
+// without COUNT or UNTIL stop at the end date, or MaxScheduleDays if there is none.
+func (s *Scheduler) calculateRRuleTimes(start time.Time, endDate *time.Time) ([]time.Time, error) {
+	rule, err := ParseRRule(s.config.RRule, s.location())
+	if err != nil {
+		return nil, err
+	}
+
+	if endDate == nil && rule.Count == 0 && rule.Until == nil {
+		end := start.AddDate(0, 0, MaxScheduleDays)
+		endDate = &end
+	}
+
+	return rule.Times(start, endDate, s.config.RepeatCount), nil
+}
+
+// calculateHourlyTimes steps in elapsed hours (not wall-clock hours), so pings stay
+// evenly spaced across DST transitions
+func (s *Scheduler) calculateHourlyTimes(start time.Time, endDate *time.Time) ([]time.Time, error) {
 	var times []time.Time
-	current := start
 	count := s.config.RepeatCount
 
 	// If no end date and count <= 0, default to 1
@@ -91,21 +464,54 @@ func (s *Scheduler) calculateDailyTimes(start time.Time, endDate *time.Time) []t
 		count = 1
 	}
 
-	for {
+	for i := 0; ; i += s.step() {
+		current := start.Add(time.Duration(i) * time.Hour)
+
 		// Check if we've exceeded end date
 		if endDate != nil && current.After(*endDate) {
 			break
 		}
 
+		if len(times) >= MaxHourlyOccurrences {
+			return nil, fmt.Errorf("hourly schedule would create more than %d messages; use a count or an earlier end date", MaxHourlyOccurrences)
+		}
 		times = append(times, current)
 
 		// Check count limit (if count is set and positive)
 		if count > 0 && len(times) >= count {
 			break
 		}
+	}
 
-		// Move to next day
-		current = current.AddDate(0, 0, 1)
+	return times, nil
+}
+
+func (s *Scheduler) calculateDailyTimes(start time.Time, endDate *time.Time) []time.Time {
+	var times []time.Time
+	count := s.config.RepeatCount
+
+	// If no end date and count <= 0, default to 1
+	if endDate == nil && count <= 0 {
+		count = 1
+	}
+
+	// Every day (or every N days)
+	for i := 0; ; i += s.step() {
+		current := s.onDay(start, i)
+
+		// Check if we've exceeded end date
+		if endDate != nil && current.After(*endDate) {
+			break
+		}
+
+		if t, ok := s.applyWeekendPolicy(current, start, endDate); ok && !containsTime(times, t) {
+			times = append(times, t)
+		}
+
+		// Check count limit (if count is set and positive)
+		if count > 0 && len(times) >= count {
+			break
+		}
 
 		// Safety limit to prevent infinite loops (only if no end date)
 		if endDate == nil && current.After(start.AddDate(10, 0, 0)) {
@@ -119,12 +525,12 @@ func (s *Scheduler) calculateDailyTimes(start time.Time, endDate *time.Time) []t
 func (s *Scheduler) calculateWeeklyTimes(start time.Time, endDate *time.Time) []time.Time {
 	var times []time.Time
 
-	// If specific days are specified, use them
-	if len(s.config.Days) > 0 {
+	// If specific days (or per-day times) are specified, use them
+	if len(s.config.Days) > 0 || len(s.config.DayTimes) > 0 {
 		times = s.calculateSpecificDaysTimes(start, endDate)
 	} else {
-		// Otherwise, repeat on the same day of week
-		current := start
+		// Otherwise, repeat on the same day of week (the anchor's, if one is set)
+		first := s.alignToAnchor(start)
 		count := s.config.RepeatCount
 
 		// If no end date and count <= 0, default to 1
@@ -132,7 +538,10 @@ func (s *Scheduler) calculateWeeklyTimes(start time.Time, endDate *time.Time) []
 			count = 1
 		}
 
-		for {
+		// Every week (or every N weeks)
+		for i := 0; ; i += 7 * s.step() {
+			current := s.onDay(first, i)
+
 			// Check if we've exceeded end date
 			if endDate != nil && current.After(*endDate) {
 				break
@@ -145,9 +554,6 @@ func (s *Scheduler) calculateWeeklyTimes(start time.Time, endDate *time.Time) []
 				break
 			}
 
-			// Move to next week
-			current = current.AddDate(0, 0, 7)
-
 			// Safety limit to prevent infinite loops (only if no end date)
 			if endDate == nil && current.After(start.AddDate(5, 0, 0)) {
 				break
@@ -160,7 +566,6 @@ func (s *Scheduler) calculateWeeklyTimes(start time.Time, endDate *time.Time) []
 
 func (s *Scheduler) calculateSpecificDaysTimes(start time.Time, endDate *time.Time) []time.Time {
 	var times []time.Time
-	current := start
 	count := s.config.RepeatCount
 
 	// If no end date and count <= 0, default to 1 (safety: don't schedule infinite messages)
@@ -179,22 +584,54 @@ func (s *Scheduler) calculateSpecificDaysTimes(start time.Time, endDate *time.Ti
 		types.Sunday:    time.Sunday,
 	}
 
-	// Create a set of target weekdays
+	// Create a set of target weekdays, falling back to the days given per-day times
+	days := s.config.Days
+	if len(days) == 0 {
+		for d := range s.config.DayTimes {
+			days = append(days, d)
+		}
+	}
 	targetDays := make(map[time.Weekday]bool)
-	for _, d := range s.config.Days {
+	for _, d := range days {
 		targetDays[dayMap[d]] = true
 	}
 
+	// Per-day send times, keyed by weekday (validated in calculateTimes)
+	dayTimes := make(map[time.Weekday]time.Time)
+	for d, tm := range s.config.DayTimes {
+		if parsed, err := time.Parse("15:04", tm); err == nil {
+			dayTimes[dayMap[d]] = parsed
+		}
+	}
+
+	// Weeks are counted from the Monday of the anchor's week (or the start date's
+	// week), so with a multiplier only every Nth week is used
+	reference := start
+	if anchor, ok := s.anchor(); ok {
+		reference = anchor
+	}
+	weekStart := reference.AddDate(0, 0, -((int(reference.Weekday()) + 6) % 7))
+	weekStart = time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, start.Location())
+
 	// Find all matching days starting from start date
-	for {
+	for i := 0; ; i++ {
+		current := s.onDay(start, i)
+
 		// Check if we've exceeded end date
 		if endDate != nil && current.After(*endDate) {
 			break
 		}
 
+		week := floorDiv(daysBetween(weekStart, current), 7)
+
 		// If this day matches one of our target days, add it
-		if targetDays[current.Weekday()] {
-			times = append(times, current)
+		if targetDays[current.Weekday()] && floorMod(week, s.step()) == 0 {
+			occurrence := current
+			if tm, ok := dayTimes[current.Weekday()]; ok {
+				occurrence = time.Date(current.Year(), current.Month(), current.Day(),
+					tm.Hour(), tm.Minute(), 0, 0, current.Location())
+			}
+			times = append(times, occurrence)
 
 			// Check count limit (if count is set and positive)
 			if count > 0 && len(times) >= count {
@@ -202,9 +639,6 @@ func (s *Scheduler) calculateSpecificDaysTimes(start time.Time, endDate *time.Ti
 			}
 		}
 
-		// Move to next day
-		current = current.AddDate(0, 0, 1)
-
 		// Safety limit to prevent infinite loops (only if no end date)
 		if endDate == nil && current.After(start.AddDate(5, 0, 0)) {
 			break
@@ -214,9 +648,134 @@ func (s *Scheduler) calculateSpecificDaysTimes(start time.Time, endDate *time.Ti
 	return times
 }
 
-func (s *Scheduler) calculateMonthlyTimes(start time.Time, endDate *time.Time) []time.Time {
+// weekendPolicy returns the effective weekend policy
+func (s *Scheduler) weekendPolicy() types.WeekendPolicy {
+	if s.config.WeekendPolicy == types.WeekendKeep && s.config.WeekdaysOnly {
+		return types.WeekendSkip
+	}
+	return s.config.WeekendPolicy
+}
+
+// applyWeekendPolicy moves or drops a weekend occurrence. It reports false if the
+// occurrence should be dropped, including when a shift moves it outside [start, endDate].
+func (s *Scheduler) applyWeekendPolicy(t, start time.Time, endDate *time.Time) (time.Time, bool) {
+	weekday := t.Weekday()
+	if weekday != time.Saturday && weekday != time.Sunday {
+		return t, true
+	}
+
+	switch s.weekendPolicy() {
+	case types.WeekendSkip:
+		return t, false
+	case types.WeekendPrevious:
+		if weekday == time.Saturday {
+			t = t.AddDate(0, 0, -1)
+		} else {
+			t = t.AddDate(0, 0, -2)
+		}
+	case types.WeekendNext:
+		if weekday == time.Saturday {
+			t = t.AddDate(0, 0, 2)
+		} else {
+			t = t.AddDate(0, 0, 1)
+		}
+	default:
+		return t, true
+	}
+
+	if t.Before(start) || (endDate != nil && t.After(*endDate)) {
+		return t, false
+	}
+	return t, true
+}
+
+// containsTime reports whether times already includes t
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, existing := range times {
+		if existing.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// onDay returns the occurrence the given number of days after start's date, at the
+// configured wall-clock send time. Occurrences are rebuilt from the date instead of
+// accumulated with AddDate so that the local time holds across DST transitions.
+func (s *Scheduler) onDay(start time.Time, days int) time.Time {
+	hour, minute := s.sendClock.Hour(), s.sendClock.Minute()
+	date := time.Date(start.Year(), start.Month(), start.Day()+days, hour, minute, 0, 0, time.UTC)
+	t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, start.Location())
+	if t.Hour() == hour && t.Minute() == minute {
+		return t
+	}
+
+	// The wall-clock time was skipped by a DST jump: read it with the offset in effect
+	// before the jump, which lands the same distance past the transition
+	_, offset := t.Add(-12 * time.Hour).Zone()
+	return date.Add(-time.Duration(offset) * time.Second).In(start.Location())
+}
+
+// anchor returns the parsed anchor date, if one is configured (validated in calculateTimes)
+func (s *Scheduler) anchor() (time.Time, bool) {
+	if s.config.Anchor == "" {
+		return time.Time{}, false
+	}
+	anchor, err := time.ParseInLocation("2006-01-02", s.config.Anchor, s.location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return anchor, true
+}
+
+// alignToAnchor returns the first date on or after start that is a whole number of
+// (multiplied) weeks from the anchor date, at start's time of day
+func (s *Scheduler) alignToAnchor(start time.Time) time.Time {
+	anchor, ok := s.anchor()
+	if !ok {
+		return start
+	}
+
+	period := 7 * s.step()
+	offset := floorMod(daysBetween(anchor, start), period)
+	if offset == 0 {
+		return start
+	}
+	return start.AddDate(0, 0, period-offset)
+}
+
+// floorDiv divides rounding towards negative infinity (dates before an anchor
+// belong to negative weeks)
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// floorMod returns a non-negative remainder for positive b
+func floorMod(a, b int) int {
+	return ((a % b) + b) % b
+}
+
+// daysBetween returns the number of calendar days from a to b, ignoring time of day
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+func (s *Scheduler) calculateMonthlyTimes(start time.Time, endDate *time.Time) ([]time.Time, error) {
+	monthDay, err := types.ParseMonthDay(s.config.MonthDay)
+	if err != nil {
+		return nil, err
+	}
+	if monthDay == 0 {
+		monthDay = start.Day()
+	}
+
 	var times []time.Time
-	current := start
 	count := s.config.RepeatCount
 
 	// If no end date and count <= 0, default to 1
@@ -224,89 +783,696 @@ func (s *Scheduler) calculateMonthlyTimes(start time.Time, endDate *time.Time) [
 		count = 1
 	}
 
-	for {
+	// Each occurrence is computed from the start month rather than by adding a
+	// month to the previous one, so a clamped Feb 28 doesn't drag March back too
+	for i := 0; ; i += s.step() {
+		current := monthlyOccurrence(start, i, monthDay, s.sendClock)
+
 		// Check if we've exceeded end date
 		if endDate != nil && current.After(*endDate) {
 			break
 		}
 
-		times = append(times, current)
+		// Safety limit to prevent infinite loops (only if no end date)
+		if endDate == nil && current.After(start.AddDate(10, 0, 0)) {
+			break
+		}
+
+		// An explicit month day may fall before the start date in the first month
+		if current.Before(start) {
+			continue
+		}
+
+		if t, ok := s.applyWeekendPolicy(current, start, endDate); ok && !containsTime(times, t) {
+			times = append(times, t)
+		}
 
 		// Check count limit (if count is set and positive)
 		if count > 0 && len(times) >= count {
 			break
 		}
+	}
 
-		// Move to next month
-		current = current.AddDate(0, 1, 0)
+	return times, nil
+}
 
-		// Safety limit to prevent infinite loops (only if no end date)
-		if endDate == nil && current.After(start.AddDate(10, 0, 0)) {
+// monthlyOccurrence returns the occurrence offset months after start's month on the
+// given day (-1 = last day), clamped to the month's length, at the clock's time of day
+func monthlyOccurrence(start time.Time, offset, day int, clock time.Time) time.Time {
+	first := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+	last := daysInMonth(first.Year(), first.Month())
+	if day < 0 || day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, clock.Hour(), clock.Minute(), 0, 0, start.Location())
+}
+
+// daysInMonth returns the number of days in the given month
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// MessageText returns the message as it will be posted, with extra links appended
+// and markdown-style links converted to Slack syntax. Templates are returned
+// unrendered and rotating schedules return their first message; see textAt.
+func (s *Scheduler) MessageText() (string, error) {
+	return s.format(s.body(1))
+}
+
+// body returns the raw text of the n-th occurrence: the schedule's message, or the
+// next of its rotating messages (the first when n is 0)
+func (s *Scheduler) body(n int) string {
+	rotate := s.config.RotateMessages
+	if len(rotate) == 0 {
+		return s.config.Message
+	}
+	if n < 1 {
+		n = 1
+	}
+	return rotate[(n-1)%len(rotate)]
+}
+
+// textAt returns the text for the n-th of total occurrences (0 when unknown),
+// rendering the message template if the schedule has one
+func (s *Scheduler) textAt(t time.Time, n, total int) (string, error) {
+	assignee, err := s.assigneeAt(n)
+	if err != nil {
+		return "", err
+	}
+
+	body := s.body(n)
+	if s.config.Template {
+		body, err = message.Render(body, s.config.Data, message.TemplateVars{
+			PostAt:     t.In(s.location()),
+			Occurrence: n,
+			Total:      total,
+			Channel:    s.config.Channel,
+			Assignee:   assignee,
+		})
+		if err != nil {
+			return "", err
+		}
+	} else if assignee != "" {
+		body = strings.ReplaceAll(body, "{{assignee}}", assignee)
+	}
+	if s.config.ResolveMentions {
+		body, err = message.ResolveMentions(body, s.mentionFor)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve mention: %w", err)
+		}
+	}
+	if !s.config.AllowBroadcast && !s.config.Raw {
+		var escaped []string
+		if body, escaped = message.EscapeBroadcasts(body); len(escaped) > 0 && !s.warnedBroadcast {
+			slog.Warn(fmt.Sprintf("Warning: @%s will not notify anyone; use --allow-broadcast to notify the whole channel", strings.Join(escaped, ", @")), "escaped", escaped)
+			s.warnedBroadcast = true
+		}
+	}
+
+	text, err := s.format(body)
+	if err != nil {
+		return "", err
+	}
+	if n := utf8.RuneCountInString(text); n > message.MaxLength && !s.config.Split {
+		return "", fmt.Errorf("message is %d characters, over Slack's %d limit (use --split to post it in parts)", n, message.MaxLength)
+	}
+	return text, nil
+}
+
+// scheduleText schedules text at t as the n-th occurrence (0 if it has no place in
+// the schedule), split into parts SplitSpacing apart if it is over Slack's length
+// limit. Parts after the first are recorded as continuations.
+func (s *Scheduler) scheduleText(channelID, text string, t time.Time, n int) ([]types.Occurrence, error) {
+	var occurrences []types.Occurrence
+	for i, part := range message.Split(text, message.MaxLength) {
+		postAt := t.Add(time.Duration(i) * SplitSpacing)
+		id, err := s.client.ScheduleMessage(channelID, part, postAt, s.occurrenceOptions(n)...)
+		if err != nil {
+			return occurrences, err
+		}
+		occurrences = append(occurrences, types.Occurrence{Channel: channelID, Message: part, PostAt: postAt, ScheduledID: id, Continuation: i > 0})
+	}
+	return occurrences, nil
+}
+
+// mentionFor returns the Slack mention syntax for a plain-text @name or @email,
+// falling back to a user group with that handle (e.g. @engineering-team)
+func (s *Scheduler) mentionFor(name string) (string, error) {
+	id, err := s.client.FindUser(name)
+	if err == nil {
+		return "<@" + id + ">", nil
+	}
+	if groupID, groupErr := s.client.FindUserGroup(name); groupErr == nil {
+		return "<!subteam^" + groupID + ">", nil
+	}
+	return "", err
+}
+
+// assigneeAt returns the mention of whose turn the n-th occurrence is (the first
+// person's when n is 0), or "" when the schedule has no assignee rotation
+func (s *Scheduler) assigneeAt(n int) (string, error) {
+	if len(s.config.RotateUsers) == 0 {
+		return "", nil
+	}
+	if s.assignees == nil {
+		for _, name := range s.config.RotateUsers {
+			id, err := s.client.GetUserID(name)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve assignee: %w", err)
+			}
+			s.assignees = append(s.assignees, "<@"+id+">")
+		}
+	}
+	if n < 1 {
+		n = 1
+	}
+	return s.assignees[(n-1)%len(s.assignees)], nil
+}
+
+// format appends the schedule's links and converts markdown-style links. Raw
+// messages are escaped instead, so only the appended links are parsed.
+func (s *Scheduler) format(body string) (string, error) {
+	if s.config.Raw {
+		body = message.EscapeControl(body)
+	}
+	text, err := message.AppendLinks(body, s.config.Links)
+	if err != nil {
+		return "", err
+	}
+	if s.config.Raw {
+		return text, nil
+	}
+	return message.ConvertMarkdownLinks(text), nil
+}
+
+// SendNow posts the message immediately, with the same channel resolution and
+// formatting as scheduled messages
+func (s *Scheduler) SendNow() error {
+	channelID, err := s.client.GetChannelID(s.config.Channel)
+	if err != nil {
+		return err
+	}
+	if err := s.checkChannel(channelID); err != nil {
+		return err
+	}
+	if err := s.checkThread(channelID); err != nil {
+		return err
+	}
+	text, err := s.textAt(s.currentTime(), 0, 0)
+	if err != nil {
+		return err
+	}
+	s.warnUnknownEmoji()
+
+	// Parts of a split message after the first reply in its thread
+	parts := message.Split(text, message.MaxLength)
+	ts, err := s.client.SendMessage(channelID, parts[0], s.messageOptions()...)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts[1:] {
+		opts := s.messageOptions()
+		if s.config.Thread == "" {
+			opts = append(opts, slack.InThread(ts, false))
+		}
+		if _, err := s.client.SendMessage(channelID, part, opts...); err != nil {
+			return err
+		}
+	}
+	if len(parts) > 1 {
+		slog.Info(fmt.Sprintf("Sent message to %s in %d parts", channelLabelFor(s.config.Channel), len(parts)), "channel", channelID, "parts", len(parts))
+		return nil
+	}
+	slog.Info(fmt.Sprintf("Sent message to %s", channelLabelFor(s.config.Channel)), "channel", channelID)
+	return nil
+}
+
+// messageOptions returns the extra Slack message options for an occurrence whose
+// position in the schedule isn't known
+func (s *Scheduler) messageOptions() []slack.MessageOption {
+	return s.occurrenceOptions(0)
+}
+
+// occurrenceOptions returns the extra Slack message options for the n-th
+// occurrence: postOptions, the thread, and metadata identifying the series
+func (s *Scheduler) occurrenceOptions(n int) []slack.MessageOption {
+	return slack.WithSendOptions(s.sendOptions(n))
+}
+
+// sendOptions records the options the n-th occurrence is sent with
+func (s *Scheduler) sendOptions(n int) types.SendOptions {
+	opts := types.SendOptions{
+		Username:  s.config.Username,
+		IconEmoji: s.config.IconEmoji,
+		Raw:       s.config.Raw,
+		Metadata:  s.metadata(n),
+	}
+	if s.config.Thread != "" {
+		// Bad thread values are rejected by calculateTimes before anything is sent
+		if _, ts, err := slack.ParseThread(s.config.Thread); err == nil {
+			opts.ThreadTS, opts.AlsoToChannel = ts, s.config.AlsoToChannel
+		}
+	}
+	return opts
+}
+
+// metadata is the schedule's own metadata plus the series name, the occurrence's
+// position (0 when unknown) and the config hash, so scheduled messages can be
+// traced back to the series and config version that created them
+func (s *Scheduler) metadata(n int) *types.MessageMetadata {
+	name := s.config.Name
+	if name == "" {
+		name = store.DefaultName(s.config)
+	}
+	return types.OccurrenceMetadata(s.config.Metadata, name, n, types.ConfigHash(*s.config))
+}
+
+// postOptions returns the message options that don't depend on where or in which
+// occurrence the message is posted, i.e. all but the thread and metadata
+func (s *Scheduler) postOptions() []slack.MessageOption {
+	return slack.WithSendOptions(types.SendOptions{Username: s.config.Username, IconEmoji: s.config.IconEmoji, Raw: s.config.Raw})
+}
+
+// seriesSendOptions returns the options the series' message with the given
+// scheduled ID was sent with, numbering occurrences as Extend does
+func seriesSendOptions(series types.Series, id string) types.SendOptions {
+	config := series.Config
+	n := series.Pruned
+	for _, occ := range series.Occurrences {
+		if !occ.Extra && !occ.Continuation {
+			n++
+		}
+		if occ.ScheduledID == id {
+			if occ.Extra {
+				n = 0
+			}
 			break
 		}
 	}
+	return New(nil, &config).sendOptions(n)
+}
 
-	return times
+// checkChannel fails early for channels that can't be posted to: archived ones
+// and ones the token can't see. Public channels the token isn't in only get a
+// warning, since the chat:write.public scope allows posting to them.
+func (s *Scheduler) checkChannel(channelID string) error {
+	if !strings.HasPrefix(channelID, "C") && !strings.HasPrefix(channelID, "G") {
+		// DMs were opened by the token itself
+		return nil
+	}
+
+	status, err := s.client.GetChannelStatus(channelID)
+	if err != nil {
+		return err
+	}
+	label := channelID
+	if status.Name != "" {
+		label = "#" + status.Name
+	}
+	if status.Archived {
+		return fmt.Errorf("channel %s is archived; unarchive it or pick another channel", label)
+	}
+	if !status.Member {
+		if status.Private {
+			return fmt.Errorf("not a member of private channel %s; /invite the app (or join the channel) first", label)
+		}
+		slog.Warn(fmt.Sprintf("Warning: not a member of %s; posting needs the chat:write.public scope, otherwise /invite the app first", label), "channel", channelID)
+	}
+	return nil
+}
+
+// checkThread rejects a thread permalink from a different channel than the one
+// the messages are posted to
+func (s *Scheduler) checkThread(channelID string) error {
+	if s.config.Thread == "" {
+		return nil
+	}
+	threadChannel, _, err := slack.ParseThread(s.config.Thread)
+	if err != nil {
+		return err
+	}
+	if threadChannel != "" && threadChannel != channelID {
+		return fmt.Errorf("thread %s is in channel %s, not %s", s.config.Thread, threadChannel, channelID)
+	}
+	return nil
+}
+
+// warnUnknownEmoji logs a warning for misspelled :shortcodes:, which Slack will
+// render as literal text
+func (s *Scheduler) warnUnknownEmoji() {
+	custom, err := s.client.GetCustomEmoji()
+	if err != nil {
+		// Missing emoji:read scope shouldn't block scheduling; check standard emoji only
+		custom = nil
+	}
+
+	texts := append([]string{s.config.Message}, s.config.RotateMessages...)
+	if s.config.IconEmoji != "" {
+		texts = append(texts, ":"+strings.Trim(s.config.IconEmoji, ":")+":")
+	}
+	typos := message.MisspelledEmoji(strings.Join(texts, "\n"), custom)
+	if len(typos) == 0 {
+		return
+	}
+	var names, hints []string
+	for _, typo := range typos {
+		names = append(names, typo.Name)
+		hints = append(hints, fmt.Sprintf(":%s: (did you mean :%s:?)", typo.Name, typo.Suggestion))
+	}
+	slog.Warn("Warning: misspelled emoji shortcode(s) will appear as literal text: "+strings.Join(hints, ", "), "emoji", names)
+}
+
+// useRecipientTimezone switches the schedule to the DM recipient's Slack time zone
+func (s *Scheduler) useRecipientTimezone() error {
+	if !slack.IsUserTarget(s.config.Channel) {
+		return fmt.Errorf("recipient-local time requires a user target (@name or user ID), got %s", s.config.Channel)
+	}
+
+	userID, err := s.client.GetUserID(s.config.Channel)
+	if err != nil {
+		return err
+	}
+	loc, err := s.client.GetUserTimezone(userID)
+	if err != nil {
+		return err
+	}
+
+	slog.Info(fmt.Sprintf("Using recipient's time zone: %s", loc), "time_zone", loc.String())
+	s.loc = loc
+	return nil
+}
+
+// Summarize describes the total number of occurrences and the span they cover
+func Summarize(times []time.Time) string {
+	switch len(times) {
+	case 0:
+		return "No messages to schedule"
+	case 1:
+		return fmt.Sprintf("1 message at %s", times[0].Format("2006-01-02 15:04 MST"))
+	}
+	return fmt.Sprintf("%d messages from %s to %s", len(times),
+		times[0].Format("2006-01-02 15:04 MST"), times[len(times)-1].Format("2006-01-02 15:04 MST"))
+}
+
+// Series returns the record of the last Schedule call for the local store, named
+// after the config's Name (or the given fallback name if it has none)
+func (s *Scheduler) Series(fallbackName string) types.Series {
+	name := s.config.Name
+	if name == "" {
+		name = fallbackName
+	}
+	return types.Series{
+		Name:        name,
+		CreatedAt:   s.currentTime(),
+		Config:      *s.config,
+		Occurrences: s.scheduled,
+	}
+}
+
+// ScheduleContext is Schedule with its Slack calls cancelled when ctx is done.
+// Messages scheduled before the cancellation are returned and recorded as usual.
+func (s *Scheduler) ScheduleContext(ctx context.Context) ([]string, error) {
+	client := s.client
+	s.client = client.WithContext(ctx)
+	defer func() { s.client = client }()
+	return s.Schedule()
 }
 
 // Schedule schedules all messages and returns the scheduled message IDs
 func (s *Scheduler) Schedule() ([]string, error) {
+	ids, err := s.schedule()
+	s.notify(ids, err)
+	return ids, err
+}
+
+// notify reports a Schedule outcome to the notifier; dry runs and schedules with
+// nothing left to do aren't reported
+func (s *Scheduler) notify(ids []string, err error) {
+	if s.notifier == nil || s.dryRun || (err == nil && len(ids) == 0) {
+		return
+	}
+	event := notify.Event{
+		Type:    notify.EventScheduled,
+		Series:  s.config.Name,
+		Channel: s.config.Channel,
+		Count:   len(ids),
+		Time:    s.currentTime(),
+	}
+	if err != nil {
+		event.Type = notify.EventScheduleFailed
+		event.Error = err.Error()
+	}
+	s.notifier.Notify(event)
+}
+
+func (s *Scheduler) schedule() ([]string, error) {
+	if s.config.RecipientLocal {
+		if err := s.useRecipientTimezone(); err != nil {
+			return nil, err
+		}
+	}
+
 	times, err := s.CalculateScheduleTimes()
 	if err != nil {
-		return nil, err
+		return nil, Invalid(err)
 	}
+	s.scheduled = nil
+	s.failed = nil
+	s.failures = nil
 
 	// Resolve channel ID
 	channelID, err := s.client.GetChannelID(s.config.Channel)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkChannel(channelID); err != nil {
+		return nil, err
+	}
+	if err := s.checkThread(channelID); err != nil {
+		return nil, err
+	}
+
+	for _, t := range s.excluded {
+		slog.Info(fmt.Sprintf("Skipping excluded date: %s", t.Format("2006-01-02 15:04 MST")), "post_at", t)
+	}
+
+	s.warnUnknownEmoji()
+
+	slog.Info(Summarize(times), "occurrences", len(times))
 
 	var scheduledIDs []string
-	now := time.Now().In(LocalTZ)
+	now := s.currentTime()
 
-	for _, t := range times {
+	// Render every message up front so a template error doesn't leave the
+	// schedule half-created
+	var pending []time.Time
+	var texts []string
+	var indexes []int
+	for i, t := range times {
 		// Skip times in the past
 		if t.Before(now) {
-			fmt.Printf("Skipping past time: %s\n", t.Format("2006-01-02 15:04 MST"))
+			slog.Info(fmt.Sprintf("Skipping past time: %s", t.Format("2006-01-02 15:04 MST")), "post_at", t)
 			continue
 		}
 
 		// Slack only allows scheduling up to 120 days in advance
-		maxFuture := now.AddDate(0, 0, 120)
+		maxFuture := now.AddDate(0, 0, MaxScheduleDays)
 		if t.After(maxFuture) {
-			fmt.Printf("Skipping time too far in future (>120 days): %s\n", t.Format("2006-01-02 15:04 MST"))
+			slog.Info(fmt.Sprintf("Skipping time too far in future (>120 days): %s", t.Format("2006-01-02 15:04 MST")), "post_at", t)
 			continue
 		}
 
-		fmt.Printf("Scheduling message for: %s\n", t.Format("2006-01-02 15:04 MST"))
-		id, err := s.client.ScheduleMessage(channelID, s.config.Message, t)
+		text, err := s.textAt(t, i+1, len(times))
 		if err != nil {
-			return scheduledIDs, err
+			return nil, err
+		}
+		pending = append(pending, t)
+		texts = append(texts, text)
+		indexes = append(indexes, i+1)
+	}
+
+	if !s.config.AllowDuplicates {
+		duplicate := s.findDuplicates(channelID, pending, texts)
+		kept := 0
+		for i, t := range pending {
+			if duplicate[i] {
+				slog.Info(fmt.Sprintf("Skipping duplicate of a message already scheduled for: %s", t.Format("2006-01-02 15:04 MST")), "post_at", t)
+				continue
+			}
+			pending[kept], texts[kept], indexes[kept] = t, texts[i], indexes[i]
+			kept++
+		}
+		pending, texts, indexes = pending[:kept], texts[:kept], indexes[:kept]
+	}
+
+	if s.dryRun {
+		for i, t := range pending {
+			slog.Info(fmt.Sprintf("Would schedule message for: %s", t.Format("2006-01-02 15:04 MST")), "post_at", t, "dry_run", true)
+			if n := len(message.Split(texts[i], message.MaxLength)); n > 1 {
+				slog.Info(fmt.Sprintf("  (split into %d parts)", n), "parts", n)
+			}
+			if s.config.Template {
+				slog.Info("  "+texts[i], "text", texts[i])
+			}
+		}
+		slog.Info(fmt.Sprintf("Dry run: %d message(s) would be scheduled in channel %s; nothing was sent to Slack", len(pending), channelID),
+			"channel", channelID, "count", len(pending), "dry_run", true)
+		return nil, nil
+	}
+
+	// Guard against mistyped counts and runaway interval/cron combinations
+	if len(pending) > ConfirmThreshold && s.confirm != nil {
+		if !s.confirm(fmt.Sprintf("Schedule %d messages to %s?", len(pending), channelLabelFor(s.config.Channel))) {
+			return nil, fmt.Errorf("scheduling cancelled")
+		}
+	}
+
+	var scheduleErr error
+	results := s.scheduleAll(channelID, pending, texts, indexes)
+	for i, t := range pending {
+		if i >= len(results) || results[i].err != nil || len(results[i].occurrences) == 0 {
+			s.failed = append(s.failed, types.PendingOccurrence{PostAt: t, Index: indexes[i], Total: len(times)})
+			s.failures = append(s.failures, failure{channelID: channelID, reason: failureReason(results, i)})
+		}
+		if i >= len(results) {
+			continue
+		}
+		for _, occ := range results[i].occurrences {
+			scheduledIDs = append(scheduledIDs, occ.ScheduledID)
+		}
+		s.scheduled = append(s.scheduled, results[i].occurrences...)
+		if results[i].err != nil && scheduleErr == nil {
+			scheduleErr = results[i].err
 		}
-		scheduledIDs = append(scheduledIDs, id)
+	}
+	if scheduleErr != nil && len(scheduledIDs) > 0 {
+		return scheduledIDs, &PartialError{Scheduled: len(scheduledIDs), Failed: len(s.failed), Err: scheduleErr}
+	}
+	if scheduleErr != nil {
+		return scheduledIDs, scheduleErr
 	}
 
 	// Verify messages were actually scheduled by listing them
-	fmt.Printf("\nVerifying scheduled messages...\n")
+	slog.Info("Verifying scheduled messages...")
 	scheduledMessages, err := s.client.ListScheduledMessages(channelID)
 	if err != nil {
-		fmt.Printf("Warning: Could not verify scheduled messages: %v\n", err)
+		slog.Warn(fmt.Sprintf("Warning: Could not verify scheduled messages: %v", err), "error", err)
 	} else {
-		fmt.Printf("Found %d scheduled message(s) in channel %s:\n", len(scheduledMessages), channelID)
+		slog.Info(fmt.Sprintf("Found %d scheduled message(s) in channel %s:", len(scheduledMessages), channelID),
+			"channel", channelID, "count", len(scheduledMessages))
 		for _, msg := range scheduledMessages {
 			postAt := time.Unix(int64(msg.PostAt), 0)
-			fmt.Printf("  - ID: %s, Scheduled for: %s, Text: %.50s...\n",
-				msg.ID, postAt.Format("2006-01-02 15:04 MST"), msg.Text)
+			slog.Info(fmt.Sprintf("  - ID: %s, Scheduled for: %s, Text: %.50s...",
+				msg.ID, postAt.Format("2006-01-02 15:04 MST"), msg.Text), "id", msg.ID, "post_at", postAt)
 		}
 		if len(scheduledMessages) == 0 {
-			fmt.Printf("  ⚠️  No scheduled messages found! The message may not have been scheduled.\n")
-			fmt.Printf("  Check that:\n")
-			fmt.Printf("    1. Your app has 'chat:write' scope (and 'chat:write.public' if posting to public channels)\n")
-			fmt.Printf("    2. Your app/bot is a member of the channel\n")
-			fmt.Printf("    3. The scheduled time is in the future\n")
+			slog.Warn("No scheduled messages found! The message may not have been scheduled.\n" +
+				"  Check that:\n" +
+				"    1. Your app has 'chat:write' scope (and 'chat:write.public' if posting to public channels)\n" +
+				"    2. Your app/bot is a member of the channel\n" +
+				"    3. The scheduled time is in the future")
 		}
 	}
 
 	return scheduledIDs, nil
 }
+
+// findDuplicates reports which messages the channel already has scheduled with
+// the same text at the same time. If the channel's scheduled messages can't be
+// listed, nothing is treated as a duplicate.
+func (s *Scheduler) findDuplicates(channelID string, times []time.Time, texts []string) []bool {
+	duplicate := make([]bool, len(times))
+	if len(times) == 0 {
+		return duplicate
+	}
+	existing, err := s.client.ListScheduledMessages(channelID)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Warning: could not check for duplicate messages: %v", err), "error", err)
+		return duplicate
+	}
+
+	type key struct {
+		postAt int64
+		text   string
+	}
+	scheduled := make(map[key]bool, len(existing))
+	for _, msg := range existing {
+		scheduled[key{int64(msg.PostAt), msg.Text}] = true
+	}
+	for i, t := range times {
+		// A split message is found by its first part
+		first := message.Split(texts[i], message.MaxLength)[0]
+		duplicate[i] = scheduled[key{t.Unix(), first}]
+	}
+	return duplicate
+}
+
+// scheduled is the outcome of scheduling one pending message
+type scheduled struct {
+	occurrences []types.Occurrence
+	err         error
+}
+
+// scheduleAll schedules texts[i] at times[i] as occurrence indexes[i], up to
+// s.concurrency at a time, and returns the outcomes in input order. Nothing new is
+// started after a failure, but messages already in flight finish and are reported
+// too.
+func (s *Scheduler) scheduleAll(channelID string, times []time.Time, texts []string, indexes []int) []scheduled {
+	results := make([]scheduled, len(times))
+	schedule := func(i int) {
+		slog.Info(fmt.Sprintf("Scheduling message for: %s", times[i].Format("2006-01-02 15:04 MST")), "post_at", times[i])
+		results[i].occurrences, results[i].err = s.scheduleText(channelID, texts[i], times[i], indexes[i])
+	}
+
+	if s.concurrency <= 1 {
+		for i := range times {
+			if schedule(i); results[i].err != nil {
+				return results[:i+1]
+			}
+		}
+		return results
+	}
+
+	var mu sync.Mutex
+	failed := false
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < s.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				schedule(i)
+				if results[i].err != nil {
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range times {
+		mu.Lock()
+		stop := failed
+		mu.Unlock()
+		if stop {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// channelLabelFor shows a channel as the user would write it: IDs and @users as
+// given, names with a leading #
+func channelLabelFor(channel string) string {
+	if channel == "" || strings.HasPrefix(channel, "#") || slack.IsUserTarget(channel) {
+		return channel
+	}
+	if strings.ToUpper(channel) == channel && strings.ContainsAny(channel[:1], "CDG") {
+		return channel
+	}
+	return "#" + channel
+}
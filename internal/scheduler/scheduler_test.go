@@ -1,9 +1,17 @@
 package scheduler
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/message"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
 	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
 )
 
@@ -325,8 +333,8 @@ func TestScheduler_CalculateScheduleTimes_Monthly(t *testing.T) {
 			},
 			wantCount:   3,
 			wantFirstAt: "2025-01-31",
-			// Go's AddDate wraps, so Jan 31 + 1 month = Feb 28/Mar 3 depending on year
-			// This is expected Go behavior
+			// Feb is clamped to the 28th, March goes back to the 31st
+			wantLastAt: "2025-03-31",
 		},
 	}
 
@@ -447,3 +455,1672 @@ func TestScheduler_CalculateScheduleTimes_InvalidInputs(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduler_MessageText(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *types.ScheduleConfig
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "plain message unchanged",
+			config: &types.ScheduleConfig{Message: "Hello team!"},
+			want:   "Hello team!",
+		},
+		{
+			name:   "markdown link converted",
+			config: &types.ScheduleConfig{Message: "See [notes](https://example.com)"},
+			want:   "See <https://example.com|notes>",
+		},
+		{
+			name:   "links appended",
+			config: &types.ScheduleConfig{Message: "Retro today", Links: []string{"Board|https://example.com/board"}},
+			want:   "Retro today\n<https://example.com/board|Board>",
+		},
+		{
+			name:    "invalid link",
+			config:  &types.ScheduleConfig{Message: "Retro today", Links: []string{"Board|board"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestScheduler(tt.config).MessageText()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MessageText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MessageText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_MessageOptions(t *testing.T) {
+	s := newTestScheduler(&types.ScheduleConfig{Message: "hi"})
+	if got := len(s.messageOptions()); got != 1 {
+		t.Errorf("expected only the occurrence metadata, got %d options", got)
+	}
+	if got := len(s.postOptions()); got != 0 {
+		t.Errorf("expected no post options, got %d", got)
+	}
+
+	s = newTestScheduler(&types.ScheduleConfig{Message: "hi", Username: "Release Bot", IconEmoji: ":rocket:"})
+	if got := len(s.messageOptions()); got != 2 {
+		t.Errorf("expected a persona and metadata, got %d options", got)
+	}
+}
+
+func TestScheduler_Metadata(t *testing.T) {
+	meta, err := types.ParseMetadata("reminder", `{"team":"platform"}`)
+	if err != nil {
+		t.Fatalf("ParseMetadata() error = %v", err)
+	}
+	config := &types.ScheduleConfig{Name: "standup", Message: "hi", Metadata: meta}
+	s := newTestScheduler(config)
+
+	got := s.metadata(3)
+	if got.EventType != "reminder" || got.EventPayload["team"] != "platform" {
+		t.Errorf("metadata(3) = %+v, want the schedule's own metadata kept", got)
+	}
+	series, index, hash, ok := types.ParseOccurrenceMetadata(got)
+	if !ok || series != "standup" || index != 3 || hash != types.ConfigHash(*config) {
+		t.Errorf("ParseOccurrenceMetadata() = %q, %d, %q, %v", series, index, hash, ok)
+	}
+	if len(meta.EventPayload) != 1 {
+		t.Errorf("metadata() modified the config's payload: %v", meta.EventPayload)
+	}
+
+	s = newTestScheduler(&types.ScheduleConfig{Message: "hi", Channel: "#general"})
+	if got := s.metadata(0); got.EventType != types.OccurrenceEventType || got.EventPayload[types.MetadataSeries] == "" {
+		t.Errorf("metadata(0) without config metadata = %+v", got)
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_Every(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *types.ScheduleConfig
+		wantDays []string
+	}{
+		{
+			name: "every 3 days",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-15",
+				SendTime:    "09:00",
+				Interval:    types.IntervalDaily,
+				Every:       3,
+				RepeatCount: 3,
+			},
+			wantDays: []string{"2025-01-15", "2025-01-18", "2025-01-21"},
+		},
+		{
+			name: "biweekly same day",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-15",
+				SendTime:    "09:00",
+				Interval:    types.IntervalWeekly,
+				Every:       2,
+				RepeatCount: 3,
+			},
+			wantDays: []string{"2025-01-15", "2025-01-29", "2025-02-12"},
+		},
+		{
+			name: "biweekly on specific days",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-14", // Tuesday
+				SendTime:  "09:00",
+				Interval:  types.IntervalWeekly,
+				Every:     2,
+				Days:      []types.DayOfWeek{types.Monday, types.Thursday},
+				EndDate:   "2025-02-09",
+			},
+			// Week of Jan 13 (Mon already before start), skip week of Jan 20, week of Jan 27, skip Feb 3
+			wantDays: []string{"2025-01-16", "2025-01-27", "2025-01-30"},
+		},
+		{
+			name: "every 2 months",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-10",
+				SendTime:    "09:00",
+				Interval:    types.IntervalMonthly,
+				Every:       2,
+				RepeatCount: 3,
+			},
+			wantDays: []string{"2025-01-10", "2025-03-10", "2025-05-10"},
+		},
+		{
+			name: "every 1 is the same as unset",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-15",
+				SendTime:    "09:00",
+				Interval:    types.IntervalDaily,
+				Every:       1,
+				RepeatCount: 2,
+			},
+			wantDays: []string{"2025-01-15", "2025-01-16"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+
+			if len(times) != len(tt.wantDays) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.wantDays), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02"); got != tt.wantDays[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.wantDays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_NegativeEvery(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate: "2025-01-15",
+		SendTime:  "09:00",
+		Interval:  types.IntervalDaily,
+		Every:     -2,
+	}
+
+	if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for negative multiplier, got nil")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_MonthEndClamping(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *types.ScheduleConfig
+		wantDays []string
+	}{
+		{
+			name: "31st clamps in short months",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-31",
+				SendTime:    "10:00",
+				Interval:    types.IntervalMonthly,
+				RepeatCount: 4,
+			},
+			wantDays: []string{"2025-01-31", "2025-02-28", "2025-03-31", "2025-04-30"},
+		},
+		{
+			name: "31st in a leap year",
+			config: &types.ScheduleConfig{
+				StartDate:   "2024-01-31",
+				SendTime:    "10:00",
+				Interval:    types.IntervalMonthly,
+				RepeatCount: 2,
+			},
+			wantDays: []string{"2024-01-31", "2024-02-29"},
+		},
+		{
+			name: "last day of month",
+			config: &types.ScheduleConfig{
+				StartDate:   "2024-01-15",
+				SendTime:    "10:00",
+				Interval:    types.IntervalMonthly,
+				MonthDay:    "last",
+				RepeatCount: 4,
+			},
+			wantDays: []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name: "explicit month day before start skips first month",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-15",
+				SendTime:    "10:00",
+				Interval:    types.IntervalMonthly,
+				MonthDay:    "10",
+				RepeatCount: 2,
+			},
+			wantDays: []string{"2025-02-10", "2025-03-10"},
+		},
+		{
+			name: "month day 30 across february with end date",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-01",
+				SendTime:  "10:00",
+				Interval:  types.IntervalMonthly,
+				MonthDay:  "30",
+				EndDate:   "2025-03-31",
+			},
+			wantDays: []string{"2025-01-30", "2025-02-28", "2025-03-30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+
+			if len(times) != len(tt.wantDays) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.wantDays), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02"); got != tt.wantDays[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.wantDays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_InvalidMonthDay(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate: "2025-01-15",
+		SendTime:  "10:00",
+		Interval:  types.IntervalMonthly,
+		MonthDay:  "32",
+	}
+
+	if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for invalid month day, got nil")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_WeekendPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *types.ScheduleConfig
+		wantDays []string
+	}{
+		{
+			name: "daily weekdays only skips weekends",
+			config: &types.ScheduleConfig{
+				StartDate:    "2025-01-16", // Thursday
+				SendTime:     "09:00",
+				Interval:     types.IntervalDaily,
+				WeekdaysOnly: true,
+				RepeatCount:  4,
+			},
+			wantDays: []string{"2025-01-16", "2025-01-17", "2025-01-20", "2025-01-21"},
+		},
+		{
+			name: "daily with next policy does not duplicate monday",
+			config: &types.ScheduleConfig{
+				StartDate:     "2025-01-17", // Friday
+				SendTime:      "09:00",
+				Interval:      types.IntervalDaily,
+				WeekendPolicy: types.WeekendNext,
+				EndDate:       "2025-01-21",
+			},
+			wantDays: []string{"2025-01-17", "2025-01-20", "2025-01-21"},
+		},
+		{
+			name: "monthly skip drops weekend months",
+			config: &types.ScheduleConfig{
+				StartDate:     "2025-01-01", // Jan 1 Wed, Feb 1 Sat, Mar 1 Sat, Apr 1 Tue
+				SendTime:      "09:00",
+				Interval:      types.IntervalMonthly,
+				WeekendPolicy: types.WeekendSkip,
+				EndDate:       "2025-04-30",
+			},
+			wantDays: []string{"2025-01-01", "2025-04-01"},
+		},
+		{
+			name: "monthly previous moves to friday",
+			config: &types.ScheduleConfig{
+				StartDate:     "2025-01-15",
+				SendTime:      "09:00",
+				Interval:      types.IntervalMonthly,
+				MonthDay:      "last",
+				WeekendPolicy: types.WeekendPrevious,
+				RepeatCount:   3,
+			},
+			// Jan 31 Fri, Feb 28 Fri, Mar 31 Mon
+			wantDays: []string{"2025-01-31", "2025-02-28", "2025-03-31"},
+		},
+		{
+			name: "monthly previous on sunday",
+			config: &types.ScheduleConfig{
+				StartDate:     "2025-08-01",
+				SendTime:      "09:00",
+				Interval:      types.IntervalMonthly,
+				MonthDay:      "last",
+				WeekendPolicy: types.WeekendPrevious,
+				RepeatCount:   1,
+			},
+			// Aug 31 2025 is a Sunday
+			wantDays: []string{"2025-08-29"},
+		},
+		{
+			name: "monthly next moves to monday",
+			config: &types.ScheduleConfig{
+				StartDate:     "2025-02-01", // Saturday
+				SendTime:      "09:00",
+				Interval:      types.IntervalMonthly,
+				WeekendPolicy: types.WeekendNext,
+				RepeatCount:   2,
+			},
+			wantDays: []string{"2025-02-03", "2025-03-03"},
+		},
+		{
+			name: "shift outside end date is dropped",
+			config: &types.ScheduleConfig{
+				StartDate:     "2025-02-01", // Saturday
+				SendTime:      "09:00",
+				Interval:      types.IntervalMonthly,
+				WeekendPolicy: types.WeekendNext,
+				EndDate:       "2025-02-02",
+			},
+			wantDays: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+
+			if len(times) != len(tt.wantDays) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.wantDays), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02"); got != tt.wantDays[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.wantDays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_InvalidWeekendPolicy(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate:     "2025-01-15",
+		SendTime:      "09:00",
+		Interval:      types.IntervalDaily,
+		WeekendPolicy: types.WeekendPolicy("sometimes"),
+	}
+
+	if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for invalid weekend policy, got nil")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_ExcludeDates(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate:    "2025-03-07", // Friday
+		SendTime:     "16:00",
+		Interval:     types.IntervalWeekly,
+		RepeatCount:  4,
+		ExcludeDates: []string{"2025-03-14", "2025-03-21", "2025-12-25"},
+	}
+
+	s := newTestScheduler(config)
+	times, err := s.CalculateScheduleTimes()
+	if err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+
+	want := []string{"2025-03-07", "2025-03-28"}
+	if len(times) != len(want) {
+		t.Fatalf("expected %d times, got %d: %v", len(want), len(times), times)
+	}
+	for i, tm := range times {
+		if got := tm.Format("2006-01-02"); got != want[i] {
+			t.Errorf("time[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+
+	excluded := s.Excluded()
+	if len(excluded) != 2 {
+		t.Fatalf("expected 2 excluded times, got %d", len(excluded))
+	}
+	if got := excluded[0].Format("2006-01-02"); got != "2025-03-14" {
+		t.Errorf("excluded[0] = %s, want 2025-03-14", got)
+	}
+
+	// Recalculating resets the excluded list
+	s.config.ExcludeDates = nil
+	if _, err := s.CalculateScheduleTimes(); err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+	if len(s.Excluded()) != 0 {
+		t.Errorf("expected excluded list to be reset, got %v", s.Excluded())
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_InvalidExcludeDate(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate:    "2025-03-07",
+		SendTime:     "16:00",
+		Interval:     types.IntervalNone,
+		ExcludeDates: []string{"March 14"},
+	}
+
+	if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for invalid excluded date, got nil")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_QuietHours(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *types.ScheduleConfig
+		want   []string
+	}{
+		{
+			name: "overnight window postpones to the next morning",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-03-03", SendTime: "23:30", Interval: types.IntervalDaily, RepeatCount: 2,
+				QuietHours: "22:00-07:00",
+			},
+			want: []string{"2025-03-04 07:00", "2025-03-05 07:00"},
+		},
+		{
+			name: "early morning inside an overnight window",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-03-03", SendTime: "06:00", Interval: types.IntervalNone,
+				QuietHours: "22:00-07:00",
+			},
+			want: []string{"2025-03-03 07:00"},
+		},
+		{
+			name: "daytime window, outside it untouched",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-03-03", SendTime: "11:00", Interval: types.IntervalHourly, RepeatCount: 4,
+				QuietHours: "12:00-13:30",
+			},
+			// 12:00 and 13:00 both move to 13:30, which is kept once
+			want: []string{"2025-03-03 11:00", "2025-03-03 13:30", "2025-03-03 14:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+			var got []string
+			for _, tm := range times {
+				got = append(got, tm.Format("2006-01-02 15:04"))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("times = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	config := &types.ScheduleConfig{StartDate: "2025-03-03", SendTime: "09:00", QuietHours: "late"}
+	if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for invalid quiet hours, got nil")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_DayTimes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *types.ScheduleConfig
+		want   []string
+	}{
+		{
+			name: "per-day times without days or send time",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-13", // Monday
+				Interval:    types.IntervalWeekly,
+				DayTimes:    map[types.DayOfWeek]string{types.Monday: "09:00", types.Friday: "16:00"},
+				RepeatCount: 4,
+			},
+			want: []string{"2025-01-13 09:00", "2025-01-17 16:00", "2025-01-20 09:00", "2025-01-24 16:00"},
+		},
+		{
+			name: "per-day time overrides send time for listed days only",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-13",
+				SendTime:    "10:00",
+				Interval:    types.IntervalWeekly,
+				Days:        []types.DayOfWeek{types.Monday, types.Wednesday},
+				DayTimes:    map[types.DayOfWeek]string{types.Wednesday: "15:30"},
+				RepeatCount: 2,
+			},
+			want: []string{"2025-01-13 10:00", "2025-01-15 15:30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+			if len(times) != len(tt.want) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.want), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02 15:04"); got != tt.want[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_InvalidDayTime(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate: "2025-01-13",
+		Interval:  types.IntervalWeekly,
+		DayTimes:  map[types.DayOfWeek]string{types.Monday: "25:00"},
+	}
+
+	if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for invalid day time, got nil")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_DayWithoutTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *types.ScheduleConfig
+	}{
+		{
+			name: "selected day missing from per-day times",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13",
+				Interval:  types.IntervalWeekly,
+				Days:      []types.DayOfWeek{types.Monday, types.Tuesday},
+				DayTimes:  map[types.DayOfWeek]string{types.Monday: "09:00"},
+			},
+		},
+		{
+			name: "per-day times on a daily schedule",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13",
+				Interval:  types.IntervalDaily,
+				DayTimes:  map[types.DayOfWeek]string{types.Monday: "09:00"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without a send time to fall back on, these would post at midnight
+			if _, err := newTestScheduler(tt.config).CalculateScheduleTimes(); err == nil {
+				t.Error("CalculateScheduleTimes() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_Anchor(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *types.ScheduleConfig
+		wantDays []string
+	}{
+		{
+			name: "biweekly aligned to sprint start from a later start date",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-15", // Wednesday of an off week
+				SendTime:    "10:00",
+				Interval:    types.IntervalWeekly,
+				Every:       2,
+				Anchor:      "2025-01-06", // Monday sprint start
+				RepeatCount: 3,
+			},
+			wantDays: []string{"2025-01-20", "2025-02-03", "2025-02-17"},
+		},
+		{
+			name: "start date on an anchor week",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-20",
+				SendTime:    "10:00",
+				Interval:    types.IntervalWeekly,
+				Every:       2,
+				Anchor:      "2025-01-06",
+				RepeatCount: 2,
+			},
+			wantDays: []string{"2025-01-20", "2025-02-03"},
+		},
+		{
+			name: "anchor after start date",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-01",
+				SendTime:    "10:00",
+				Interval:    types.IntervalWeekly,
+				Every:       2,
+				Anchor:      "2025-02-03",
+				RepeatCount: 2,
+			},
+			wantDays: []string{"2025-01-06", "2025-01-20"},
+		},
+		{
+			name: "specific days use the anchor's week parity",
+			config: &types.ScheduleConfig{
+				StartDate:   "2025-01-13", // off week relative to anchor
+				SendTime:    "10:00",
+				Interval:    types.IntervalWeekly,
+				Every:       2,
+				Anchor:      "2025-01-06",
+				Days:        []types.DayOfWeek{types.Monday, types.Friday},
+				RepeatCount: 3,
+			},
+			wantDays: []string{"2025-01-20", "2025-01-24", "2025-02-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+			if len(times) != len(tt.wantDays) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.wantDays), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02"); got != tt.wantDays[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.wantDays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_InvalidAnchor(t *testing.T) {
+	config := &types.ScheduleConfig{
+		StartDate: "2025-01-13",
+		SendTime:  "10:00",
+		Interval:  types.IntervalWeekly,
+		Anchor:    "next monday",
+	}
+
+	if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for invalid anchor, got nil")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_RelativeIn(t *testing.T) {
+	now := time.Date(2025, 1, 13, 9, 15, 0, 0, LocalTZ)
+
+	tests := []struct {
+		name    string
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"hours and minutes", "2h30m", now.Add(2*time.Hour + 30*time.Minute), false},
+		{"minutes", "45m", now.Add(45 * time.Minute), false},
+		{"invalid duration", "an hour", time.Time{}, true},
+		{"zero", "0s", time.Time{}, true},
+		{"negative", "-1h", time.Time{}, true},
+		{"beyond slack limit", "3000h", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Date, time and interval are ignored when In is set
+			s := newTestScheduler(&types.ScheduleConfig{
+				StartDate: "2020-01-01",
+				SendTime:  "08:00",
+				Interval:  types.IntervalDaily,
+				In:        tt.in,
+			})
+			s.SetClock(NewFakeClock(now))
+
+			times, err := s.CalculateScheduleTimes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateScheduleTimes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(times) != 1 || !times[0].Equal(tt.want) {
+				t.Errorf("CalculateScheduleTimes() = %v, want [%v]", times, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_DSTWallClock(t *testing.T) {
+	tests := []struct {
+		name   string
+		zone   string
+		config *types.ScheduleConfig
+		want   []string
+	}{
+		{
+			name: "daily across US spring forward",
+			zone: "America/New_York",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-03-08", SendTime: "09:00",
+				Interval: types.IntervalDaily, RepeatCount: 3,
+			},
+			want: []string{"2025-03-08 09:00 EST", "2025-03-09 09:00 EDT", "2025-03-10 09:00 EDT"},
+		},
+		{
+			name: "daily across US fall back",
+			zone: "America/New_York",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-11-01", SendTime: "09:00",
+				Interval: types.IntervalDaily, RepeatCount: 3,
+			},
+			want: []string{"2025-11-01 09:00 EDT", "2025-11-02 09:00 EST", "2025-11-03 09:00 EST"},
+		},
+		{
+			name: "weekly across EU fall back",
+			zone: "Europe/Berlin",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-10-19", SendTime: "09:00",
+				Interval: types.IntervalWeekly, RepeatCount: 3,
+			},
+			want: []string{"2025-10-19 09:00 CEST", "2025-10-26 09:00 CET", "2025-11-02 09:00 CET"},
+		},
+		{
+			name: "specific days across EU spring forward",
+			zone: "Europe/Berlin",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-03-28", SendTime: "09:00",
+				Interval: types.IntervalWeekly, RepeatCount: 2,
+				Days: []types.DayOfWeek{types.Friday, types.Monday},
+			},
+			want: []string{"2025-03-28 09:00 CET", "2025-03-31 09:00 CEST"},
+		},
+		{
+			name: "monthly across US spring forward",
+			zone: "America/New_York",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-02-15", SendTime: "09:00",
+				Interval: types.IntervalMonthly, RepeatCount: 2,
+			},
+			want: []string{"2025-02-15 09:00 EST", "2025-03-15 09:00 EDT"},
+		},
+		{
+			name: "start in skipped hour keeps configured time afterwards",
+			zone: "America/New_York",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-03-09", SendTime: "02:30",
+				Interval: types.IntervalDaily, RepeatCount: 2,
+			},
+			want: []string{"2025-03-09 03:30 EDT", "2025-03-10 02:30 EDT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Skipf("time zone data unavailable: %v", err)
+			}
+			orig := LocalTZ
+			LocalTZ = loc
+			defer func() { LocalTZ = orig }()
+
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if err != nil {
+				t.Fatalf("CalculateScheduleTimes() error = %v", err)
+			}
+			if len(times) != len(tt.want) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.want), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02 15:04 MST"); got != tt.want[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_SetLocation(t *testing.T) {
+	loc, err := types.Defaults{Timezone: "Asia/Tokyo"}.Location()
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	s := newTestScheduler(&types.ScheduleConfig{
+		Channel:   "C123",
+		StartDate: "2025-01-13",
+		SendTime:  "09:00",
+		Interval:  types.IntervalNone,
+	})
+	s.SetLocation(loc)
+
+	times, err := s.CalculateScheduleTimes()
+	if err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+	if want := time.Date(2025, 1, 13, 9, 0, 0, 0, loc); len(times) != 1 || !times[0].Equal(want) {
+		t.Errorf("times = %v, want %v", times, want)
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_RecipientZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	s := newTestScheduler(&types.ScheduleConfig{
+		Channel:     "@alice",
+		StartDate:   "2025-01-13",
+		SendTime:    "09:00",
+		Interval:    types.IntervalDaily,
+		RepeatCount: 2,
+	})
+	s.loc = tokyo
+
+	times, err := s.CalculateScheduleTimes()
+	if err != nil {
+		t.Fatalf("CalculateScheduleTimes() error = %v", err)
+	}
+	want := []time.Time{
+		time.Date(2025, 1, 13, 9, 0, 0, 0, tokyo),
+		time.Date(2025, 1, 14, 9, 0, 0, 0, tokyo),
+	}
+	if len(times) != len(want) {
+		t.Fatalf("expected %d times, got %d: %v", len(want), len(times), times)
+	}
+	for i := range want {
+		if !times[i].Equal(want[i]) || times[i].Location() != tokyo {
+			t.Errorf("time[%d] = %v, want %v", i, times[i], want[i])
+		}
+	}
+}
+
+func TestScheduler_UseRecipientTimezone_RequiresUser(t *testing.T) {
+	s := newTestScheduler(&types.ScheduleConfig{Channel: "#general", RecipientLocal: true})
+
+	if err := s.useRecipientTimezone(); err == nil {
+		t.Error("useRecipientTimezone() expected error for a channel target")
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_Hourly(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *types.ScheduleConfig
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "count",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13", SendTime: "09:30",
+				Interval: types.IntervalHourly, RepeatCount: 3,
+			},
+			want: []string{"2025-01-13 09:30", "2025-01-13 10:30", "2025-01-13 11:30"},
+		},
+		{
+			name: "every 2 hours past midnight",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13", SendTime: "22:00",
+				Interval: types.IntervalHourly, Every: 2, RepeatCount: 3,
+			},
+			want: []string{"2025-01-13 22:00", "2025-01-14 00:00", "2025-01-14 02:00"},
+		},
+		{
+			name: "end date",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13", SendTime: "21:00", EndDate: "2025-01-13",
+				Interval: types.IntervalHourly,
+			},
+			want: []string{"2025-01-13 21:00", "2025-01-13 22:00", "2025-01-13 23:00"},
+		},
+		{
+			name: "end date beyond the cap",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13", SendTime: "09:00", EndDate: "2025-01-31",
+				Interval: types.IntervalHourly,
+			},
+			wantErr: true,
+		},
+		{
+			name: "count beyond the cap",
+			config: &types.ScheduleConfig{
+				StartDate: "2025-01-13", SendTime: "09:00",
+				Interval: types.IntervalHourly, RepeatCount: MaxHourlyOccurrences + 1,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTestScheduler(tt.config).CalculateScheduleTimes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateScheduleTimes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(times) != len(tt.want) {
+				t.Fatalf("expected %d times, got %d: %v", len(tt.want), len(times), times)
+			}
+			for i, tm := range times {
+				if got := tm.Format("2006-01-02 15:04"); got != tt.want[i] {
+					t.Errorf("time[%d] = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	first := time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC)
+	last := time.Date(2025, 1, 13, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		times []time.Time
+		want  string
+	}{
+		{"none", nil, "No messages to schedule"},
+		{"one", []time.Time{first}, "1 message at 2025-01-13 09:00 UTC"},
+		{"many", []time.Time{first, first.Add(time.Hour), last}, "3 messages from 2025-01-13 09:00 UTC to 2025-01-13 12:00 UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.times); got != tt.want {
+				t.Errorf("Summarize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Series(t *testing.T) {
+	now := time.Date(2025, 1, 13, 9, 0, 0, 0, LocalTZ)
+	occ := types.Occurrence{Channel: "C123", Message: "hi", PostAt: now.Add(time.Hour), ScheduledID: "Q1"}
+
+	tests := []struct {
+		name       string
+		configName string
+		want       string
+	}{
+		{"fallback name", "", "general-daily"},
+		{"configured name", "standup", "standup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScheduler(&types.ScheduleConfig{Name: tt.configName, Channel: "general", Message: "hi"})
+			s.SetClock(NewFakeClock(now))
+			s.scheduled = []types.Occurrence{occ}
+
+			series := s.Series("general-daily")
+			if series.Name != tt.want {
+				t.Errorf("Series().Name = %q, want %q", series.Name, tt.want)
+			}
+			if !series.CreatedAt.Equal(now) || series.Config.Message != "hi" {
+				t.Errorf("Series() = %+v, want creation time and config recorded", series)
+			}
+			if len(series.Occurrences) != 1 || series.Occurrences[0].ScheduledID != "Q1" {
+				t.Errorf("Series().Occurrences = %v, want [%v]", series.Occurrences, occ)
+			}
+		})
+	}
+}
+
+func TestScheduler_CalculateScheduleTimes_InvalidDay(t *testing.T) {
+	configs := []*types.ScheduleConfig{
+		{StartDate: "2025-01-13", SendTime: "09:00", Interval: types.IntervalWeekly, Days: []types.DayOfWeek{"someday"}},
+		{StartDate: "2025-01-13", Interval: types.IntervalWeekly, DayTimes: map[types.DayOfWeek]string{"mon": "09:00"}},
+	}
+
+	for _, config := range configs {
+		if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+			t.Errorf("CalculateScheduleTimes(%+v) expected error for invalid day", config)
+		}
+	}
+}
+
+func TestScheduler_Schedule_DryRun(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	config := &types.ScheduleConfig{
+		Message: "Standup", Channel: "C123",
+		StartDate: "2025-01-06", EndDate: "2025-01-08", SendTime: "09:00", Interval: types.IntervalDaily,
+	}
+	s := New(client, config)
+	s.SetClock(NewFakeClock(now))
+	s.SetDryRun(true)
+
+	ids, err := s.Schedule()
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if len(ids) != 0 || scheduleCalls(api) != 0 {
+		t.Errorf("dry run returned %d IDs and made %d scheduleMessage calls, want none", len(ids), scheduleCalls(api))
+	}
+	if series := s.Series("standup"); len(series.Occurrences) != 0 {
+		t.Errorf("dry run recorded %d occurrences, want none", len(series.Occurrences))
+	}
+
+	s.SetDryRun(false)
+	if ids, err = s.Schedule(); err != nil || len(ids) != 3 || scheduleCalls(api) != 3 {
+		t.Errorf("Schedule() = %d IDs, %v (%d calls), want 3", len(ids), err, scheduleCalls(api))
+	}
+}
+
+func TestScheduler_Schedule_ConfirmLargeBatch(t *testing.T) {
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	newConfig := func(end string) *types.ScheduleConfig {
+		return &types.ScheduleConfig{
+			Message: "Standup", Channel: "general",
+			StartDate: "2025-01-06", EndDate: end, SendTime: "09:00", Interval: types.IntervalDaily,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		end        string
+		answer     bool
+		noConfirm  bool
+		wantPrompt string
+		wantCalls  int
+		wantErr    bool
+	}{
+		{name: "small batch is not confirmed", end: "2025-01-10", wantCalls: 5},
+		{name: "large batch declined", end: "2025-01-31", wantPrompt: "Schedule 26 messages to #general?", wantErr: true},
+		{name: "large batch accepted", end: "2025-01-31", answer: true, wantPrompt: "Schedule 26 messages to #general?", wantCalls: 26},
+		{name: "yes skips the prompt", end: "2025-01-31", noConfirm: true, wantCalls: 26},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := slack.NewFakeAPI()
+			api.AddChannel("general")
+			client := slack.NewFakeClient(api)
+
+			s := New(client, newConfig(tt.end))
+			s.SetClock(NewFakeClock(now))
+			var prompt string
+			if !tt.noConfirm {
+				s.SetConfirm(func(p string) bool {
+					prompt = p
+					return tt.answer
+				})
+			}
+
+			_, err := s.Schedule()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Schedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if prompt != tt.wantPrompt {
+				t.Errorf("prompt = %q, want %q", prompt, tt.wantPrompt)
+			}
+			if scheduleCalls(api) != tt.wantCalls {
+				t.Errorf("made %d scheduleMessage calls, want %d", scheduleCalls(api), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestChannelLabelFor(t *testing.T) {
+	tests := map[string]string{
+		"general":  "#general",
+		"#general": "#general",
+		"C123ABC":  "C123ABC",
+		"@alex":    "@alex",
+		"U12345":   "U12345",
+		"design":   "#design",
+	}
+	for input, want := range tests {
+		if got := channelLabelFor(input); got != want {
+			t.Errorf("channelLabelFor(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestScheduler_SendNow(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/conversations.list":
+			w.Write([]byte(`{"ok":true,"channels":[{"id":"C777","name":"general"}],"response_metadata":{"next_cursor":""}}`))
+		case "/chat.postMessage":
+			r.ParseForm()
+			posted = append(posted, r.Form.Get("channel")+": "+r.Form.Get("text"))
+			w.Write([]byte(`{"ok":true,"channel":"C777","ts":"1736931600.000100"}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	s := New(client, &types.ScheduleConfig{Message: "See [the doc](https://example.com)", Channel: "#general"})
+	if err := s.SendNow(); err != nil {
+		t.Fatalf("SendNow() error = %v", err)
+	}
+	if want := "C777: See <https://example.com|the doc>"; len(posted) != 1 || posted[0] != want {
+		t.Errorf("posted %v, want [%s]", posted, want)
+	}
+
+	if err := New(client, &types.ScheduleConfig{Message: "hi", Channel: "random"}).SendNow(); err == nil {
+		t.Error("SendNow() to unknown channel expected error")
+	}
+}
+
+func TestScheduler_Schedule_Thread(t *testing.T) {
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/chat.scheduleMessage" {
+			r.ParseForm()
+			forms = append(forms, r.Form)
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	tests := []struct {
+		name          string
+		thread        string
+		alsoToChannel bool
+		wantErr       bool
+		wantTS        string
+		wantBroadcast string
+	}{
+		{name: "timestamp", thread: "1736931600.000100", wantTS: "1736931600.000100"},
+		{name: "permalink with broadcast", thread: "https://acme.slack.com/archives/C123/p1736931600000100", alsoToChannel: true, wantTS: "1736931600.000100", wantBroadcast: "true"},
+		{name: "permalink from another channel", thread: "https://acme.slack.com/archives/C999/p1736931600000100", wantErr: true},
+		{name: "invalid thread", thread: "yesterday", wantErr: true},
+		{name: "broadcast without thread", alsoToChannel: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms = nil
+			s := New(client, &types.ScheduleConfig{
+				Message: "Reply", Channel: "C123", StartDate: "2025-01-06", SendTime: "09:00",
+				Interval: types.IntervalNone, Thread: tt.thread, AlsoToChannel: tt.alsoToChannel,
+			})
+			s.SetClock(NewFakeClock(now))
+
+			_, err := s.Schedule()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Schedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(forms) != 0 {
+					t.Errorf("scheduled %d message(s) despite the error", len(forms))
+				}
+				return
+			}
+			if len(forms) != 1 {
+				t.Fatalf("scheduled %d messages, want 1", len(forms))
+			}
+			if got := forms[0].Get("thread_ts"); got != tt.wantTS {
+				t.Errorf("thread_ts = %q, want %q", got, tt.wantTS)
+			}
+			if got := forms[0].Get("reply_broadcast"); got != tt.wantBroadcast {
+				t.Errorf("reply_broadcast = %q, want %q", got, tt.wantBroadcast)
+			}
+		})
+	}
+}
+
+func TestScheduler_Schedule_Template(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	s := New(client, &types.ScheduleConfig{
+		Message:   "{{.team}} standup {{occurrence}}/{{total}} on {{weekday}}",
+		Channel:   "C123",
+		StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalDaily, RepeatCount: 2,
+		Template: true, Data: map[string]interface{}{"team": "Platform"},
+	})
+	s.SetClock(NewFakeClock(now))
+
+	if _, err := s.Schedule(); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	want := []string{"Platform standup 1/2 on Monday", "Platform standup 2/2 on Tuesday"}
+	occs := s.Series("").Occurrences
+	if len(occs) != len(want) {
+		t.Fatalf("scheduled %d messages, want %d", len(occs), len(want))
+	}
+	for i, occ := range occs {
+		if occ.Message != want[i] {
+			t.Errorf("message[%d] = %q, want %q", i, occ.Message, want[i])
+		}
+	}
+
+	// A missing data key fails before anything is sent
+	api.Calls = nil
+	s = New(client, &types.ScheduleConfig{
+		Message: "{{.missing}}", Channel: "C123",
+		StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalNone, Template: true,
+	})
+	s.SetClock(NewFakeClock(now))
+	if _, err := s.Schedule(); err == nil {
+		t.Error("Schedule() expected error for missing template data")
+	}
+	if scheduleCalls(api) != 0 {
+		t.Errorf("made %d API calls despite the template error", scheduleCalls(api))
+	}
+}
+
+func TestScheduler_Schedule_RotateMessages(t *testing.T) {
+	_, client := newFakeWorkspace("C123")
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	s := New(client, &types.ScheduleConfig{
+		RotateMessages: []string{"Favourite snack?", "Best trip?"},
+		Channel:        "C123",
+		StartDate:      "2025-01-06", SendTime: "09:00", Interval: types.IntervalWeekly, RepeatCount: 3,
+	})
+	s.SetClock(NewFakeClock(now))
+
+	if _, err := s.Schedule(); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	want := []string{"Favourite snack?", "Best trip?", "Favourite snack?"}
+	occs := s.Series("").Occurrences
+	if len(occs) != len(want) {
+		t.Fatalf("scheduled %d messages, want %d", len(occs), len(want))
+	}
+	for i, occ := range occs {
+		if occ.Message != want[i] {
+			t.Errorf("message[%d] = %q, want %q", i, occ.Message, want[i])
+		}
+	}
+
+	invalid := []*types.ScheduleConfig{
+		{Message: "hi", RotateMessages: []string{"a"}, Channel: "C123", StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalNone},
+		{RotateMessages: []string{"a", " "}, Channel: "C123", StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalNone},
+	}
+	for _, config := range invalid {
+		if _, err := newTestScheduler(config).CalculateScheduleTimes(); err == nil {
+			t.Errorf("CalculateScheduleTimes(%v) expected error", config.RotateMessages)
+		}
+	}
+}
+
+func TestScheduler_Schedule_RotateUsers(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users.list":
+			w.Write([]byte(`{"ok":true,"members":[{"id":"U1","name":"alice"},{"id":"U2","name":"bob","profile":{"display_name":"Bobby"}}],"response_metadata":{"next_cursor":""}}`))
+		case "/chat.scheduleMessage":
+			r.ParseForm()
+			texts = append(texts, r.Form.Get("text"))
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	tests := []struct {
+		name     string
+		template bool
+		message  string
+		users    []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "plain message", message: "{{assignee}} runs standup", users: []string{"@alice", "Bobby", "U3"},
+			want: []string{"<@U1> runs standup", "<@U2> runs standup", "<@U3> runs standup", "<@U1> runs standup"},
+		},
+		{
+			name: "template", template: true, message: "{{assignee}} runs standup {{occurrence}}", users: []string{"bob"},
+			want: []string{"<@U2> runs standup 1", "<@U2> runs standup 2", "<@U2> runs standup 3", "<@U2> runs standup 4"},
+		},
+		{name: "unknown user", message: "{{assignee}}", users: []string{"alice", "carol"}, wantErr: true},
+		{name: "blank user", message: "{{assignee}}", users: []string{"alice", "@"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts = nil
+			s := New(client, &types.ScheduleConfig{
+				Message: tt.message, Channel: "C123", Template: tt.template, RotateUsers: tt.users,
+				StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalWeekly, RepeatCount: 4,
+			})
+			s.SetClock(NewFakeClock(now))
+
+			_, err := s.Schedule()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Schedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(texts, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("scheduled %q, want %q", texts, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_SendNow_Mentions(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users.list":
+			w.Write([]byte(`{"ok":true,"members":[{"id":"U1","name":"alice","profile":{"email":"alice@example.com"}},{"id":"U2","name":"bob"}],"response_metadata":{"next_cursor":""}}`))
+		case "/usergroups.list":
+			w.Write([]byte(`{"ok":true,"usergroups":[{"id":"S1","handle":"engineering-team"}]}`))
+		case "/chat.postMessage":
+			r.ParseForm()
+			texts = append(texts, r.Form.Get("text"))
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	tests := []struct {
+		name    string
+		resolve bool
+		allow   bool
+		message string
+		want    string
+		wantErr bool
+	}{
+		{name: "resolved", resolve: true, message: "@alice@example.com and @bob, please review", want: "<@U1> and <@U2>, please review"},
+		{name: "user group", resolve: true, message: "@engineering-team release at 5", want: "<!subteam^S1> release at 5"},
+		{name: "off by default", message: "@bob, please review", want: "@bob, please review"},
+		{name: "broadcast escaped", resolve: true, message: "@here and <!channel>, standup", want: "@here and @channel, standup"},
+		{name: "broadcast allowed", resolve: true, allow: true, message: "@here and <!channel>, standup", want: "<!here> and <!channel>, standup"},
+		{name: "unknown user", resolve: true, message: "@carol, please review", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts = nil
+			s := New(client, &types.ScheduleConfig{Message: tt.message, Channel: "C123", ResolveMentions: tt.resolve, AllowBroadcast: tt.allow})
+
+			err := s.SendNow()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendNow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(texts) != 0 {
+					t.Errorf("sent %q despite the error", texts)
+				}
+				return
+			}
+			if len(texts) != 1 || texts[0] != tt.want {
+				t.Errorf("sent %q, want %q", texts, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Schedule_Raw(t *testing.T) {
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/chat.scheduleMessage" {
+			r.ParseForm()
+			forms = append(forms, r.Form)
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	s := New(client, &types.ScheduleConfig{
+		Message: "Run `a <b> *c*` per [docs](https://example.com) <!here>", Channel: "C123", Raw: true,
+		Links: []string{"Runbook|https://example.com/runbook"}, StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalNone,
+	})
+	s.SetClock(NewFakeClock(now))
+	if _, err := s.Schedule(); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if len(forms) != 1 {
+		t.Fatalf("scheduled %d messages, want 1", len(forms))
+	}
+	want := "Run `a &lt;b&gt; *c*` per [docs](https://example.com) &lt;!here&gt;\n<https://example.com/runbook|Runbook>"
+	if got := forms[0].Get("text"); got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if forms[0].Get("mrkdwn") != "false" || forms[0].Get("parse") != "none" {
+		t.Errorf("mrkdwn = %q, parse = %q, want false and none", forms[0].Get("mrkdwn"), forms[0].Get("parse"))
+	}
+
+	s = New(client, &types.ScheduleConfig{Message: "hi", Channel: "C123", Raw: true, ResolveMentions: true, StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalNone})
+	if _, err := s.CalculateScheduleTimes(); err == nil {
+		t.Error("CalculateScheduleTimes() expected error for raw message with mentions")
+	}
+}
+
+func TestScheduler_Split(t *testing.T) {
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/chat.scheduleMessage":
+			forms = append(forms, r.Form)
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+		case "/chat.postMessage":
+			forms = append(forms, r.Form)
+			w.Write([]byte(`{"ok":true,"channel":"C123","ts":"1736931600.000100"}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	long := strings.Repeat("a", message.MaxLength) + "\n\n" + "tail"
+
+	newSplit := func(split bool) *Scheduler {
+		s := New(client, &types.ScheduleConfig{
+			Message: long, Channel: "C123", Split: split,
+			StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalNone,
+		})
+		s.SetClock(NewFakeClock(now))
+		return s
+	}
+
+	if _, err := newSplit(false).Schedule(); err == nil {
+		t.Error("Schedule() expected error for a message over the limit")
+	}
+	if len(forms) != 0 {
+		t.Fatalf("scheduled %d messages despite the error", len(forms))
+	}
+
+	s := newSplit(true)
+	ids, err := s.Schedule()
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if len(ids) != 2 || len(forms) != 2 {
+		t.Fatalf("scheduled %d messages, want 2", len(forms))
+	}
+	if forms[1].Get("text") != "tail" {
+		t.Errorf("second part = %q, want tail", forms[1].Get("text"))
+	}
+	first, second := s.scheduled[0], s.scheduled[1]
+	if first.Continuation || !second.Continuation || second.PostAt.Sub(first.PostAt) != SplitSpacing {
+		t.Errorf("scheduled %+v then %+v, want a continuation %v later", first, second, SplitSpacing)
+	}
+
+	forms = nil
+	if err := newSplit(true).SendNow(); err != nil {
+		t.Fatalf("SendNow() error = %v", err)
+	}
+	if len(forms) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(forms))
+	}
+	if forms[0].Get("thread_ts") != "" || forms[1].Get("thread_ts") != "1736931600.000100" {
+		t.Errorf("thread_ts = %q, %q, want the second part to reply to the first", forms[0].Get("thread_ts"), forms[1].Get("thread_ts"))
+	}
+}
+
+func TestScheduler_CheckChannel(t *testing.T) {
+	var scheduled int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/conversations.list":
+			w.Write([]byte(`{"ok":true,"channels":[` +
+				`{"id":"C1","name":"general","is_member":true},` +
+				`{"id":"C2","name":"old-project","is_archived":true,"is_member":true},` +
+				`{"id":"C3","name":"random"},` +
+				`{"id":"G4","name":"secret","is_private":true}` +
+				`],"response_metadata":{"next_cursor":""}}`))
+		case "/conversations.info":
+			w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+		case "/chat.scheduleMessage":
+			atomic.AddInt32(&scheduled, 1)
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+
+	tests := []struct {
+		channel string
+		wantErr string
+	}{
+		{channel: "#general"},
+		{channel: "#random"},
+		{channel: "#old-project", wantErr: "archived"},
+		{channel: "#secret", wantErr: "/invite"},
+		{channel: "C999", wantErr: "not found or not visible"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			atomic.StoreInt32(&scheduled, 0)
+			s := New(client, &types.ScheduleConfig{
+				Message: "hi", Channel: tt.channel, StartDate: "2025-01-06", SendTime: "09:00", Interval: types.IntervalNone,
+			})
+			s.SetClock(NewFakeClock(now))
+
+			_, err := s.Schedule()
+			if tt.wantErr == "" {
+				if err != nil || scheduled != 1 {
+					t.Errorf("Schedule() error = %v, scheduled %d, want 1 message", err, scheduled)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Schedule() error = %v, want %q", err, tt.wantErr)
+			}
+			if scheduled != 0 {
+				t.Errorf("scheduled %d messages despite the error", scheduled)
+			}
+		})
+	}
+}
+
+func TestScheduler_Schedule_Concurrent(t *testing.T) {
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	failAt := fmt.Sprint(time.Date(2025, 1, 10, 9, 0, 0, 0, LocalTZ).Unix())
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/chat.scheduleMessage" {
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		r.ParseForm()
+		postAt := r.Form.Get("post_at")
+		if postAt == failAt && r.Form.Get("text") == "fail" {
+			w.Write([]byte(`{"ok":false,"error":"invalid_time"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"ok":true,"channel":"C123","scheduled_message_id":"Q%s"}`, postAt)
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	s := New(client, &types.ScheduleConfig{
+		Message: "standup", Channel: "C123", StartDate: "2025-01-06", SendTime: "09:00",
+		Interval: types.IntervalDaily, RepeatCount: 10,
+	})
+	s.SetClock(NewFakeClock(now))
+	s.SetConcurrency(4)
+	ids, err := s.Schedule()
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if calls != 10 || len(ids) != 10 {
+		t.Fatalf("made %d calls for %d IDs, want 10 each", calls, len(ids))
+	}
+	for i := 1; i < len(s.scheduled); i++ {
+		if !s.scheduled[i].PostAt.After(s.scheduled[i-1].PostAt) || ids[i] != fmt.Sprintf("Q%d", s.scheduled[i].PostAt.Unix()) {
+			t.Fatalf("results out of order at %d: %v", i, ids)
+		}
+	}
+
+	// A failure is reported, and whatever did get scheduled is still returned
+	s = New(client, &types.ScheduleConfig{
+		Message: "fail", Channel: "C123", StartDate: "2025-01-06", SendTime: "09:00",
+		Interval: types.IntervalDaily, RepeatCount: 10,
+	})
+	s.SetClock(NewFakeClock(now))
+	s.SetConcurrency(4)
+	ids, err = s.Schedule()
+	if err == nil {
+		t.Fatal("Schedule() expected the failing message's error")
+	}
+	if len(ids) < 4 || len(ids) != len(s.scheduled) {
+		t.Errorf("got %d IDs and %d recorded occurrences, want the messages before the failure at least", len(ids), len(s.scheduled))
+	}
+}
+
+func TestScheduler_Schedule_SkipsDuplicates(t *testing.T) {
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, LocalTZ)
+	first := time.Date(2025, 1, 6, 9, 0, 0, 0, LocalTZ).Unix()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chat.scheduledMessages.list":
+			fmt.Fprintf(w, `{"ok":true,"scheduled_messages":[{"id":"Q1","channel_id":"C123","post_at":%d,"text":"standup"},{"id":"Q2","channel_id":"C123","post_at":%d,"text":"other"}]}`, first, first+86400)
+		case "/chat.scheduleMessage":
+			atomic.AddInt32(&calls, 1)
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	client := slack.NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	config := &types.ScheduleConfig{
+		Message: "standup", Channel: "C123", StartDate: "2025-01-06", SendTime: "09:00",
+		Interval: types.IntervalDaily, RepeatCount: 3,
+	}
+	s := New(client, config)
+	s.SetClock(NewFakeClock(now))
+	if _, err := s.Schedule(); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("scheduled %d messages, want 2 (the 09:00 standup on the 6th already exists)", calls)
+	}
+
+	calls = 0
+	config.AllowDuplicates = true
+	s = New(client, config)
+	s.SetClock(NewFakeClock(now))
+	if _, err := s.Schedule(); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("scheduled %d messages with AllowDuplicates, want 3", calls)
+	}
+}
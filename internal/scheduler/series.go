@@ -0,0 +1,360 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// Shift moves an occurrence by whole calendar days plus a duration, or to a new
+// time of day
+type Shift struct {
+	Days   int
+	Offset time.Duration
+
+	// Replacement time of day (HH:MM); when set, Days and Offset are ignored
+	To string
+}
+
+// ParseShift parses a shift such as "1h", "-30m", "1d" or "2d3h". Days are calendar
+// days, so a 1d shift keeps the wall-clock time across DST transitions.
+func ParseShift(s string) (Shift, error) {
+	rest := strings.TrimSpace(s)
+	sign := 1
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	} else {
+		rest = strings.TrimPrefix(rest, "+")
+	}
+
+	var shift Shift
+	if dayStr, after, ok := strings.Cut(rest, "d"); ok {
+		days, err := strconv.Atoi(dayStr)
+		if err != nil || days < 0 {
+			return Shift{}, fmt.Errorf("invalid shift: %s (use e.g. 1h, 30m, 1d, 2d3h)", s)
+		}
+		shift.Days = sign * days
+		rest = after
+	}
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil || d < 0 {
+			return Shift{}, fmt.Errorf("invalid shift: %s (use e.g. 1h, 30m, 1d, 2d3h)", s)
+		}
+		shift.Offset = time.Duration(sign) * d
+	}
+
+	if shift.Days == 0 && shift.Offset == 0 {
+		return Shift{}, fmt.Errorf("invalid shift: %s (must move by a non-zero amount)", s)
+	}
+	return shift, nil
+}
+
+// Apply returns t moved by the shift, in t's location
+func (sh Shift) Apply(t time.Time) (time.Time, error) {
+	if sh.To != "" {
+		clock, err := time.Parse("15:04", sh.To)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time: %s (use HH:MM, 24-hour)", sh.To)
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), clock.Hour(), clock.Minute(), 0, 0, t.Location()), nil
+	}
+	return t.AddDate(0, 0, sh.Days).Add(sh.Offset), nil
+}
+
+// upcoming returns the indexes of the series' occurrences still scheduled in Slack
+// and not yet posted
+func upcoming(series *types.Series, now time.Time) []int {
+	var idx []int
+	for i, occ := range series.Occurrences {
+		if occ.ScheduledID != "" && occ.PostAt.After(now) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Reschedule moves every upcoming occurrence of the series by the shift,
+// scheduling each message at its new time before deleting the original. The
+// series' start date and send time are shifted too, so later extensions follow
+// the new time. If a message can't be moved, the ones already moved are put back
+// and the series is left as it was. It returns the number of occurrences moved.
+func (s *Scheduler) Reschedule(series *types.Series, shift Shift) (int, error) {
+	now := s.currentTime()
+	maxFuture := now.AddDate(0, 0, MaxScheduleDays)
+
+	// Validate every new time up front so a bad shift doesn't leave the series half-moved
+	pending := upcoming(series, now)
+	moved := make(map[int]time.Time, len(pending))
+	for _, i := range pending {
+		t, err := shift.Apply(series.Occurrences[i].PostAt.In(s.location()))
+		if err != nil {
+			return 0, err
+		}
+		if !t.After(now) || t.After(maxFuture) {
+			return 0, fmt.Errorf("shifted time %s is outside Slack's scheduling window", t.Format("2006-01-02 15:04 MST"))
+		}
+		moved[i] = t
+	}
+	config := *s.config
+	if err := s.shiftConfig(shift); err != nil {
+		*s.config = config
+		return 0, err
+	}
+
+	var done []int
+	original := make(map[int]time.Time, len(pending))
+	for _, i := range pending {
+		occ := &series.Occurrences[i]
+		slog.Info(fmt.Sprintf("Rescheduling %s -> %s", occ.PostAt.In(s.location()).Format("2006-01-02 15:04 MST"), moved[i].Format("2006-01-02 15:04 MST")), "post_at", occ.PostAt, "new_post_at", moved[i])
+		if err := s.moveOccurrence(occ, moved[i]); err != nil {
+			s.moveBack(series, done, original)
+			*s.config = config
+			return 0, err
+		}
+		original[i], occ.PostAt = occ.PostAt, moved[i]
+		done = append(done, i)
+	}
+
+	series.Config = *s.config
+	return len(done), nil
+}
+
+// moveOccurrence schedules occ's message at postAt and then deletes the original,
+// pointing occ at the replacement. If the original can't be deleted, the
+// replacement is kept and the leftover reported.
+func (s *Scheduler) moveOccurrence(occ *types.Occurrence, postAt time.Time) error {
+	id, err := s.client.ScheduleMessage(occ.Channel, occ.Message, postAt, s.messageOptions()...)
+	if err != nil {
+		return err
+	}
+	if err := s.client.DeleteScheduledMessage(occ.Channel, occ.ScheduledID); err != nil {
+		slog.Warn(fmt.Sprintf("Warning: rescheduled, but the original message %s could not be deleted: %v", occ.ScheduledID, err), "scheduled_id", occ.ScheduledID, "error", err)
+	}
+	occ.ScheduledID = id
+	return nil
+}
+
+// moveBack returns the occurrences at indexes done to their original times after
+// a failed Reschedule. Failures are logged, as the original error is what gets
+// reported.
+func (s *Scheduler) moveBack(series *types.Series, done []int, original map[int]time.Time) {
+	for _, i := range done {
+		occ := &series.Occurrences[i]
+		if err := s.moveOccurrence(occ, original[i]); err != nil {
+			slog.Warn(fmt.Sprintf("Warning: could not move %s back to %s: %v", occ.PostAt.In(s.location()).Format("2006-01-02 15:04 MST"), original[i].In(s.location()).Format("2006-01-02 15:04 MST"), err), "post_at", occ.PostAt, "original_post_at", original[i], "error", err)
+			continue
+		}
+		occ.PostAt = original[i]
+	}
+}
+
+// shiftConfig moves the series definition's start date and send time by the shift
+func (s *Scheduler) shiftConfig(shift Shift) error {
+	if s.config.StartDate == "" || s.config.SendTime == "" {
+		return nil
+	}
+	start, err := s.parseDateTime(s.config.StartDate, s.config.SendTime)
+	if err != nil {
+		return err
+	}
+	shifted, err := shift.Apply(start)
+	if err != nil {
+		return err
+	}
+	s.config.StartDate = shifted.Format("2006-01-02")
+	s.config.SendTime = shifted.Format("15:04")
+	return nil
+}
+
+// Pause deletes the series' upcoming Slack scheduled messages, keeping the
+// occurrences (without scheduled IDs) so Resume can recreate them. It returns the
+// number of messages removed.
+func (s *Scheduler) Pause(series *types.Series) (int, error) {
+	if series.Paused {
+		return 0, fmt.Errorf("series %s is already paused", series.Name)
+	}
+
+	count, err := s.deleteUpcoming(series)
+	if err != nil {
+		return count, err
+	}
+
+	series.Paused = true
+	return count, nil
+}
+
+// deleteUpcoming deletes the series' upcoming Slack scheduled messages, clearing
+// their scheduled IDs
+func (s *Scheduler) deleteUpcoming(series *types.Series) (int, error) {
+	count := 0
+	for _, i := range upcoming(series, s.currentTime()) {
+		occ := &series.Occurrences[i]
+		if err := s.client.DeleteScheduledMessage(occ.Channel, occ.ScheduledID); err != nil {
+			return count, err
+		}
+		occ.ScheduledID = ""
+		count++
+	}
+	return count, nil
+}
+
+// Resume re-schedules the paused occurrences that are still in the future. It
+// returns the number of messages scheduled.
+func (s *Scheduler) Resume(series *types.Series) (int, error) {
+	if !series.Paused {
+		return 0, fmt.Errorf("series %s is not paused", series.Name)
+	}
+
+	now := s.currentTime()
+	count := 0
+	for i := range series.Occurrences {
+		occ := &series.Occurrences[i]
+		if occ.ScheduledID != "" || occ.Skipped {
+			continue
+		}
+		if !occ.PostAt.After(now) {
+			slog.Info(fmt.Sprintf("Skipping past time: %s", occ.PostAt.In(s.location()).Format("2006-01-02 15:04 MST")), "post_at", occ.PostAt)
+			continue
+		}
+
+		id, err := s.client.ScheduleMessage(occ.Channel, occ.Message, occ.PostAt, s.messageOptions()...)
+		if err != nil {
+			return count, err
+		}
+		occ.ScheduledID = id
+		count++
+	}
+
+	series.Paused = false
+	return count, nil
+}
+
+// SkipNext cancels only the series' next upcoming occurrence, leaving the rest
+// scheduled. It returns the skipped occurrence.
+func (s *Scheduler) SkipNext(series *types.Series) (types.Occurrence, error) {
+	next := -1
+	for _, i := range upcoming(series, s.currentTime()) {
+		if next < 0 || series.Occurrences[i].PostAt.Before(series.Occurrences[next].PostAt) {
+			next = i
+		}
+	}
+	if next < 0 {
+		return types.Occurrence{}, fmt.Errorf("series %s has no upcoming occurrences", series.Name)
+	}
+
+	occ := &series.Occurrences[next]
+	if err := s.client.DeleteScheduledMessage(occ.Channel, occ.ScheduledID); err != nil {
+		return types.Occurrence{}, err
+	}
+	occ.ScheduledID = ""
+	occ.Skipped = true
+	return *occ, nil
+}
+
+// Clone re-creates the series' remaining occurrences in another channel as a new
+// series called name. A non-empty message overrides the original text.
+func (s *Scheduler) Clone(series *types.Series, name, channel, message string) (types.Series, error) {
+	config := series.Config
+	config.Name = name
+	config.Channel = channel
+	if message != "" {
+		config.Message = message
+		config.RotateMessages = nil
+	}
+	target := &Scheduler{client: s.client, config: &config, clock: s.clock, loc: s.loc}
+
+	clone := types.Series{
+		Name:      name,
+		CreatedAt: s.currentTime(),
+		Config:    config,
+		Managed:   series.Managed,
+	}
+
+	channelID, err := s.client.GetChannelID(channel)
+	if err != nil {
+		return clone, err
+	}
+	text := ""
+	if message != "" {
+		if text, err = target.MessageText(); err != nil {
+			return clone, err
+		}
+	}
+
+	pending := upcoming(series, s.currentTime())
+	if len(pending) == 0 {
+		return clone, fmt.Errorf("series %s has no upcoming occurrences to clone", series.Name)
+	}
+
+	for _, i := range pending {
+		occ := series.Occurrences[i]
+		occ.Channel = channelID
+		if text != "" {
+			occ.Message = text
+		}
+
+		slog.Info(fmt.Sprintf("Scheduling message for: %s", occ.PostAt.In(s.location()).Format("2006-01-02 15:04 MST")), "post_at", occ.PostAt)
+		id, err := s.client.ScheduleMessage(occ.Channel, occ.Message, occ.PostAt, target.messageOptions()...)
+		if err != nil {
+			return clone, err
+		}
+		occ.ScheduledID = id
+		clone.Occurrences = append(clone.Occurrences, occ)
+	}
+
+	return clone, nil
+}
+
+// AddOccurrence schedules a one-off extra occurrence of the series on the given
+// date (YYYY-MM-DD) and time (HH:MM), with the series' text and channel
+func (s *Scheduler) AddOccurrence(series *types.Series, date, timeStr string) (types.Occurrence, error) {
+	t, err := s.parseDateTime(date, timeStr)
+	if err != nil {
+		return types.Occurrence{}, err
+	}
+
+	now := s.currentTime()
+	if !t.After(now) {
+		return types.Occurrence{}, fmt.Errorf("time %s is in the past", t.Format("2006-01-02 15:04 MST"))
+	}
+	if t.After(now.AddDate(0, 0, MaxScheduleDays)) {
+		return types.Occurrence{}, fmt.Errorf("time %s is more than %d days in the future", t.Format("2006-01-02 15:04 MST"), MaxScheduleDays)
+	}
+	for _, occ := range series.Occurrences {
+		if occ.PostAt.Equal(t) && !occ.Skipped {
+			return types.Occurrence{}, fmt.Errorf("series %s already has an occurrence at %s", series.Name, t.Format("2006-01-02 15:04 MST"))
+		}
+	}
+
+	channelID, err := s.client.GetChannelID(s.config.Channel)
+	if err != nil {
+		return types.Occurrence{}, err
+	}
+	// Extras sit outside the recurrence, so they have no occurrence number
+	text, err := s.textAt(t, 0, 0)
+	if err != nil {
+		return types.Occurrence{}, err
+	}
+
+	slog.Info(fmt.Sprintf("Scheduling message for: %s", t.Format("2006-01-02 15:04 MST")), "post_at", t)
+	occurrences, err := s.scheduleText(channelID, text, t, 0)
+	for i := range occurrences {
+		occurrences[i].Extra = true
+	}
+	series.Occurrences = append(series.Occurrences, occurrences...)
+	if err != nil {
+		return types.Occurrence{}, err
+	}
+
+	occ := occurrences[0]
+	sort.Slice(series.Occurrences, func(i, j int) bool {
+		return series.Occurrences[i].PostAt.Before(series.Occurrences[j].PostAt)
+	})
+	return occ, nil
+}
@@ -0,0 +1,356 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	goslack "github.com/slack-go/slack"
+)
+
+func TestParseShift(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Shift
+		wantErr bool
+	}{
+		{"1h", Shift{Offset: time.Hour}, false},
+		{"-30m", Shift{Offset: -30 * time.Minute}, false},
+		{"1d", Shift{Days: 1}, false},
+		{"+2d3h", Shift{Days: 2, Offset: 3 * time.Hour}, false},
+		{"-1d2h", Shift{Days: -1, Offset: -2 * time.Hour}, false},
+		{"0h", Shift{}, true},
+		{"", Shift{}, true},
+		{"1w", Shift{}, true},
+		{"xd", Shift{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseShift(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseShift(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseShift(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShift_Apply(t *testing.T) {
+	base := time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		shift   Shift
+		want    string
+		wantErr bool
+	}{
+		{"offset", Shift{Offset: 90 * time.Minute}, "2025-01-13 10:30", false},
+		{"days", Shift{Days: -1}, "2025-01-12 09:00", false},
+		{"to time of day", Shift{Days: 3, To: "14:15"}, "2025-01-13 14:15", false},
+		{"invalid to", Shift{To: "2pm"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.shift.Apply(base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Format("2006-01-02 15:04") != tt.want {
+				t.Errorf("Apply() = %s, want %s", got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
+
+// newTestSeries returns a daily series with one posted and two upcoming occurrences
+func newTestSeries() *types.Series {
+	day := func(n int) time.Time { return time.Date(2025, 1, 12+n, 9, 0, 0, 0, LocalTZ) }
+	return &types.Series{
+		Name: "standup",
+		Config: types.ScheduleConfig{
+			Message: "Standup", Channel: "C123",
+			StartDate: "2025-01-12", SendTime: "09:00", Interval: types.IntervalDaily, RepeatCount: 3,
+		},
+		Occurrences: []types.Occurrence{
+			{Channel: "C123", Message: "Standup", PostAt: day(0), ScheduledID: "Q0"},
+			{Channel: "C123", Message: "Standup", PostAt: day(1), ScheduledID: "Q1"},
+			{Channel: "C123", Message: "Standup", PostAt: day(2), ScheduledID: "Q2"},
+		},
+	}
+}
+
+func TestScheduler_Reschedule(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+	series := newTestSeries()
+	seedScheduled(api, series.Occurrences)
+	s := New(client, &series.Config)
+	s.SetClock(NewFakeClock(now))
+
+	n, err := s.Reschedule(series, Shift{Offset: time.Hour})
+	if err != nil {
+		t.Fatalf("Reschedule() error = %v", err)
+	}
+	if n != 2 || scheduleCalls(api) != 2 {
+		t.Fatalf("Reschedule() moved %d (%d schedule calls), want 2", n, scheduleCalls(api))
+	}
+
+	want := []string{"2025-01-12 09:00", "2025-01-13 10:00", "2025-01-14 10:00"}
+	for i, occ := range series.Occurrences {
+		if got := occ.PostAt.Format("2006-01-02 15:04"); got != want[i] {
+			t.Errorf("occurrence[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+	if series.Config.SendTime != "10:00" || series.Config.StartDate != "2025-01-12" {
+		t.Errorf("config start = %s %s, want 2025-01-12 10:00", series.Config.StartDate, series.Config.SendTime)
+	}
+}
+
+// failingAPI fails the failAt'th chat.scheduleMessage call, counting from 1, and
+// records the thread each message was scheduled in
+type failingAPI struct {
+	*slack.FakeAPI
+	failAt, calls int
+	threads       []string
+}
+
+func (f *failingAPI) ScheduleMessageIDContext(ctx context.Context, channelID, postAt string, options ...goslack.MsgOption) (string, string, error) {
+	f.calls++
+	if f.calls == f.failAt {
+		return "", "", goslack.SlackErrorResponse{Err: "is_archived"}
+	}
+	_, values, err := goslack.UnsafeApplyMsgOptions("fake-token", channelID, "https://slack.invalid/api/", options...)
+	if err != nil {
+		return "", "", err
+	}
+	f.threads = append(f.threads, values.Get("thread_ts"))
+	return f.FakeAPI.ScheduleMessageIDContext(ctx, channelID, postAt, options...)
+}
+
+func TestScheduler_Reschedule_FailureMovesBack(t *testing.T) {
+	api, _ := newFakeWorkspace("C123")
+	client := slack.NewClientWithAPI(&failingAPI{FakeAPI: api, failAt: 2})
+
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+	series := newTestSeries()
+	seedScheduled(api, series.Occurrences)
+	s := New(client, &series.Config)
+	s.SetClock(NewFakeClock(now))
+
+	// Jan 13 moves, Jan 14 fails, so Jan 13 goes back to 09:00
+	if _, err := s.Reschedule(series, Shift{Offset: time.Hour}); err == nil {
+		t.Fatal("Reschedule() expected error")
+	}
+	if len(api.Scheduled) != 3 {
+		t.Fatalf("Slack holds %d messages, want 3", len(api.Scheduled))
+	}
+	inSlack := make(map[string]string)
+	for _, msg := range api.Scheduled {
+		inSlack[msg.ID] = time.Unix(int64(msg.PostAt), 0).In(LocalTZ).Format("2006-01-02 15:04")
+	}
+	want := []string{"2025-01-12 09:00", "2025-01-13 09:00", "2025-01-14 09:00"}
+	for i, occ := range series.Occurrences {
+		if got := occ.PostAt.Format("2006-01-02 15:04"); got != want[i] || inSlack[occ.ScheduledID] != want[i] {
+			t.Errorf("occurrence[%d] = %s (%s in Slack), want %s", i, got, inSlack[occ.ScheduledID], want[i])
+		}
+	}
+	if series.Config.SendTime != "09:00" || s.config.SendTime != "09:00" {
+		t.Errorf("send time = %s, want 09:00 unchanged", series.Config.SendTime)
+	}
+}
+
+func TestScheduler_Reschedule_IntoPast(t *testing.T) {
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+	series := newTestSeries()
+	s := newTestScheduler(&series.Config)
+	s.SetClock(NewFakeClock(now))
+
+	// Moving Jan 13 09:00 back two days would land before now; nothing is touched
+	if _, err := s.Reschedule(series, Shift{Days: -2}); err == nil {
+		t.Fatal("Reschedule() expected error for a shift into the past")
+	}
+	if series.Occurrences[1].ScheduledID != "Q1" || series.Config.StartDate != "2025-01-12" {
+		t.Error("Reschedule() modified the series despite failing validation")
+	}
+}
+
+func TestScheduler_PauseResume(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+	series := newTestSeries()
+	seedScheduled(api, series.Occurrences)
+	s := New(client, &series.Config)
+	clock := NewFakeClock(now)
+	s.SetClock(clock)
+
+	n, err := s.Pause(series)
+	if err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if n != 2 || !series.Paused {
+		t.Fatalf("Pause() removed %d, paused = %v, want 2 and true", n, series.Paused)
+	}
+	if series.Occurrences[0].ScheduledID != "Q0" || series.Occurrences[1].ScheduledID != "" {
+		t.Errorf("Pause() should only clear upcoming occurrences: %+v", series.Occurrences)
+	}
+	if _, err := s.Pause(series); err == nil {
+		t.Error("Pause() twice expected error")
+	}
+	if _, err := s.Extend(series, 0); err == nil {
+		t.Error("Extend() expected error for a paused series")
+	}
+
+	// By the time the series resumes, Jan 13's occurrence has passed
+	clock.Set(time.Date(2025, 1, 13, 12, 0, 0, 0, LocalTZ))
+	n, err = s.Resume(series)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if n != 1 || scheduleCalls(api) != 1 || series.Paused {
+		t.Fatalf("Resume() scheduled %d (%d API calls), paused = %v, want 1 and false", n, scheduleCalls(api), series.Paused)
+	}
+	if series.Occurrences[2].ScheduledID == "" {
+		t.Error("Resume() should reschedule the future occurrence")
+	}
+	if _, err := s.Resume(series); err == nil {
+		t.Error("Resume() of an active series expected error")
+	}
+}
+
+func TestScheduler_SkipNext(t *testing.T) {
+	api, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+	series := newTestSeries()
+	seedScheduled(api, series.Occurrences)
+	s := New(client, &series.Config)
+	s.SetClock(NewFakeClock(now))
+
+	skipped, err := s.SkipNext(series)
+	if err != nil {
+		t.Fatalf("SkipNext() error = %v", err)
+	}
+	if got := skipped.PostAt.Format("2006-01-02"); got != "2025-01-13" {
+		t.Errorf("SkipNext() skipped %s, want 2025-01-13", got)
+	}
+	if !series.Occurrences[1].Skipped || series.Occurrences[2].ScheduledID != "Q2" {
+		t.Errorf("SkipNext() should only cancel the next occurrence: %+v", series.Occurrences)
+	}
+
+	// Pausing and resuming doesn't bring the skipped occurrence back
+	if _, err := s.Pause(series); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.Resume(series); err != nil || n != 1 {
+		t.Errorf("Resume() = %d, %v, want only the unskipped occurrence", n, err)
+	}
+	if series.Occurrences[1].ScheduledID != "" {
+		t.Error("Resume() rescheduled a skipped occurrence")
+	}
+
+	// Skipping again moves on to the following occurrence, then there are none left
+	if skipped, err = s.SkipNext(series); err != nil || skipped.PostAt.Day() != 14 {
+		t.Errorf("second SkipNext() = %v, %v, want Jan 14", skipped.PostAt, err)
+	}
+	if _, err := s.SkipNext(series); err == nil {
+		t.Error("SkipNext() expected error with no upcoming occurrences")
+	}
+}
+
+func TestScheduler_Clone(t *testing.T) {
+	_, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+	series := newTestSeries()
+	s := New(client, &series.Config)
+	s.SetClock(NewFakeClock(now))
+
+	tests := []struct {
+		name        string
+		message     string
+		wantMessage string
+	}{
+		{"same text", "", "Standup"},
+		{"message override", "Standup (EU) [notes](https://example.com)", "Standup (EU) <https://example.com|notes>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clone, err := s.Clone(series, "standup-eu", "C999", tt.message)
+			if err != nil {
+				t.Fatalf("Clone() error = %v", err)
+			}
+			if clone.Name != "standup-eu" || clone.Config.Channel != "C999" {
+				t.Errorf("Clone() = %q in %q, want standup-eu in C999", clone.Name, clone.Config.Channel)
+			}
+			if len(clone.Occurrences) != 2 {
+				t.Fatalf("Clone() created %d occurrences, want the 2 upcoming ones", len(clone.Occurrences))
+			}
+			for _, occ := range clone.Occurrences {
+				if occ.Channel != "C999" || occ.Message != tt.wantMessage || occ.ScheduledID == "" {
+					t.Errorf("cloned occurrence = %+v, want scheduled in C999 with %q", occ, tt.wantMessage)
+				}
+			}
+		})
+	}
+
+	// The source series is untouched
+	if series.Config.Channel != "C123" || series.Occurrences[1].Channel != "C123" {
+		t.Error("Clone() modified the source series")
+	}
+}
+
+func TestScheduler_AddOccurrence(t *testing.T) {
+	_, client := newFakeWorkspace("C123")
+
+	now := time.Date(2025, 1, 12, 12, 0, 0, 0, LocalTZ)
+
+	tests := []struct {
+		name    string
+		date    string
+		time    string
+		wantErr bool
+	}{
+		{"extra occurrence", "2025-02-28", "10:00", false},
+		{"in the past", "2025-01-12", "08:00", true},
+		{"beyond slack limit", "2025-06-01", "10:00", true},
+		{"duplicate of existing", "2025-01-13", "09:00", true},
+		{"invalid date", "2025-02-29", "10:00", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := newTestSeries()
+			s := New(client, &series.Config)
+			s.SetClock(NewFakeClock(now))
+
+			occ, err := s.AddOccurrence(series, tt.date, tt.time)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddOccurrence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(series.Occurrences) != 3 {
+					t.Errorf("AddOccurrence() changed the series on error")
+				}
+				return
+			}
+			if !occ.Extra || occ.Message != "Standup" || occ.ScheduledID == "" {
+				t.Errorf("AddOccurrence() = %+v, want a scheduled extra with the series text", occ)
+			}
+			if last := series.Occurrences[len(series.Occurrences)-1]; !last.PostAt.Equal(occ.PostAt) {
+				t.Errorf("occurrences not kept in order: %+v", series.Occurrences)
+			}
+			if last, _ := lastOccurrence(series); last.Day() != 14 {
+				t.Errorf("lastOccurrence() = %v, extras shouldn't move the recurrence forward", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/config"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/message"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// MaxMessageLength is the longest message text Slack accepts
+const MaxMessageLength = 40000
+
+// ValidationError is a problem with one entry of a schedule file
+type ValidationError struct {
+	Path string
+
+	// Line of the offending field (or entry); 0 when unknown, e.g. for JSON files
+	Line int
+
+	// 1-based position of the entry in the file
+	Entry int
+
+	// Field name as written in the file (empty for problems with the entry as a whole)
+	Field string
+
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	location := e.Path
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", e.Path, e.Line)
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("%s: entry %d: %s", location, e.Entry, e.Message)
+	}
+	return fmt.Sprintf("%s: entry %d: %s: %s", location, e.Entry, e.Field, e.Message)
+}
+
+// ValidateScheduleFile checks every entry of a schedule file without scheduling
+// anything. resolveChannel (typically Client.GetChannelID, which only reads) checks
+// that channels exist; pass nil to skip that check. A non-nil error means the file
+// couldn't be read or parsed at all.
+func ValidateScheduleFile(path string, resolveChannel func(string) (string, error)) ([]ValidationError, error) {
+	file, err := config.LoadScheduleFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := config.ScheduleFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []ValidationError
+	names := make(map[string]int)
+	for i := range file.Schedules {
+		entry := &file.Schedules[i]
+
+		problems := validateEntry(entry, resolveChannel)
+		if entry.Name != "" {
+			if first, dup := names[entry.Name]; dup {
+				problems = append(problems, fieldProblem{"name", fmt.Sprintf("duplicate name %q (also used by entry %d)", entry.Name, first)})
+			} else {
+				names[entry.Name] = i + 1
+			}
+		}
+
+		for _, p := range problems {
+			ve := ValidationError{Path: path, Entry: i + 1, Field: p.field, Message: p.message}
+			if i < len(lines) {
+				ve.Line = lines[i].Line
+				if line, ok := lines[i].Fields[p.field]; ok {
+					ve.Line = line
+				}
+			}
+			errs = append(errs, ve)
+		}
+	}
+	return errs, nil
+}
+
+type fieldProblem struct {
+	field   string
+	message string
+}
+
+// validateEntry checks a single schedule, field by field, then (if the fields look
+// right) by calculating its occurrences
+func validateEntry(entry *types.ScheduleConfig, resolveChannel func(string) (string, error)) []fieldProblem {
+	var problems []fieldProblem
+	add := func(field, format string, args ...interface{}) {
+		problems = append(problems, fieldProblem{field, fmt.Sprintf(format, args...)})
+	}
+	checkDate := func(field, value string) {
+		if value == "" {
+			return
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			add(field, "invalid date %q (use YYYY-MM-DD)", value)
+		}
+	}
+
+	if len(entry.RotateMessages) > 0 {
+		if entry.Message != "" {
+			add("message", "cannot be combined with rotate_messages")
+		}
+		for i, body := range entry.RotateMessages {
+			if n := utf8.RuneCountInString(body); n > MaxMessageLength {
+				add("rotate_messages", "entry %d is %d characters long (Slack allows at most %d)", i+1, n, MaxMessageLength)
+			}
+		}
+	} else if entry.Message == "" {
+		add("message", "is required")
+	} else if n := utf8.RuneCountInString(entry.Message); n > MaxMessageLength {
+		add("message", "is %d characters long (Slack allows at most %d)", n, MaxMessageLength)
+	}
+	for _, link := range entry.Links {
+		if _, err := message.FormatLink(link); err != nil {
+			add("links", "%v", err)
+		}
+	}
+
+	if entry.Channel == "" {
+		add("channel", "is required")
+	} else if resolveChannel != nil {
+		if _, err := resolveChannel(entry.Channel); err != nil {
+			add("channel", "%v", err)
+		}
+	}
+
+	if !entry.Interval.IsValid() {
+		add("interval", "invalid interval %q (use: none, hourly, daily, weekly, monthly)", entry.Interval)
+	}
+	if entry.Every < 0 {
+		add("every", "must be 1 or greater")
+	}
+
+	// Cron expressions and relative delays carry their own start
+	needsStart := entry.Cron == "" && entry.In == ""
+	if needsStart && entry.StartDate == "" {
+		add("start_date", "is required")
+	}
+	if needsStart && entry.SendTime == "" && len(entry.DayTimes) == 0 {
+		add("send_time", "is required")
+	}
+	checkDate("start_date", entry.StartDate)
+	checkDate("end_date", entry.EndDate)
+	checkDate("anchor", entry.Anchor)
+	for _, d := range entry.ExcludeDates {
+		checkDate("exclude_dates", d)
+	}
+	if entry.SendTime != "" {
+		if _, err := time.Parse("15:04", entry.SendTime); err != nil {
+			add("send_time", "invalid time %q (use HH:MM, 24-hour)", entry.SendTime)
+		}
+	}
+
+	if entry.QuietHours != "" {
+		if _, _, err := types.ParseQuietHours(entry.QuietHours); err != nil {
+			add("quiet_hours", "%v", err)
+		}
+	}
+
+	for _, d := range entry.Days {
+		if !d.IsValid() {
+			add("days", "invalid day of week %q (use: mon,tue,wed,thu,fri,sat,sun)", d)
+		}
+	}
+	for d, tm := range entry.DayTimes {
+		if !d.IsValid() {
+			add("day_times", "invalid day of week %q (use: mon,tue,wed,thu,fri,sat,sun)", d)
+		}
+		if _, err := time.Parse("15:04", tm); err != nil {
+			add("day_times", "invalid time %q for %s (use HH:MM, 24-hour)", tm, d)
+		}
+	}
+	if needsStart && entry.SendTime == "" && len(entry.DayTimes) > 0 {
+		if err := checkDayTimes(entry); err != nil {
+			add("day_times", "%v", err)
+		}
+	}
+	if !entry.WeekendPolicy.IsValid() {
+		add("weekend_policy", "invalid weekend policy %q (use: skip, previous, next)", entry.WeekendPolicy)
+	}
+	if _, err := types.ParseMonthDay(entry.MonthDay); err != nil {
+		add("month_day", "%v", err)
+	}
+
+	// Anything the field checks can't see (cron/rrule syntax, hourly caps, ...)
+	if len(problems) == 0 {
+		if _, err := New(nil, entry).CalculateScheduleTimes(); err != nil {
+			add("", "%v", err)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateScheduleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.yaml")
+	data := `schedules:
+  - name: standup
+    message: Standup
+    channel: engineering
+    start_date: 2025-01-13
+    send_time: "09:00"
+    interval: weekly
+    days: [mon, fri]
+  - name: standup
+    message: ""
+    channel: missing-channel
+    start_date: 2025-13-01
+    send_time: "9am"
+    interval: fortnightly
+    days: [someday]
+  - message: Bad cron
+    channel: engineering
+    cron: "61 * * * *"
+  - message: ` + strings.Repeat("x", MaxMessageLength+1) + `
+    channel: engineering
+    start_date: 2025-01-13
+    send_time: "09:00"
+  - message: Office hours
+    channel: engineering
+    start_date: 2025-01-13
+    interval: weekly
+    days: [mon, tue]
+    day_times: {mon: "09:00"}
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolve := func(channel string) (string, error) {
+		if channel == "engineering" {
+			return "C123", nil
+		}
+		return "", fmt.Errorf("channel not found: %s", channel)
+	}
+
+	errs, err := ValidateScheduleFile(path, resolve)
+	if err != nil {
+		t.Fatalf("ValidateScheduleFile() error = %v", err)
+	}
+
+	want := []string{
+		path + ":10: entry 2: message: is required",
+		path + ":11: entry 2: channel: channel not found: missing-channel",
+		path + ":14: entry 2: interval: invalid interval \"fortnightly\"",
+		path + ":12: entry 2: start_date: invalid date \"2025-13-01\"",
+		path + ":13: entry 2: send_time: invalid time \"9am\"",
+		path + ":15: entry 2: days: invalid day of week \"someday\"",
+		path + ":9: entry 2: name: duplicate name \"standup\" (also used by entry 1)",
+		path + ":16: entry 3: invalid cron minute field",
+		path + ":19: entry 4: message: is 40001 characters long",
+		path + ":28: entry 5: day_times: no time given for tue",
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got %d errors, want %d:", len(errs), len(want))
+		for _, e := range errs {
+			t.Log(e)
+		}
+	}
+	for i, w := range want {
+		if i < len(errs) && !strings.HasPrefix(errs[i].Error(), w) {
+			t.Errorf("error[%d] = %q, want prefix %q", i, errs[i].Error(), w)
+		}
+	}
+}
+
+func TestValidateScheduleFile_JSONWithoutChannelCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	data := `{"schedules":[{"message":"hi","channel":"anything","start_date":"2025-01-13","send_time":"09:00","interval":"daily","repeat_count":3}]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, err := ValidateScheduleFile(path, nil)
+	if err != nil || len(errs) != 0 {
+		t.Errorf("ValidateScheduleFile() = %v, %v, want no errors", errs, err)
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	tests := []struct {
+		err  ValidationError
+		want string
+	}{
+		{ValidationError{Path: "a.yaml", Line: 3, Entry: 1, Field: "send_time", Message: "is required"}, "a.yaml:3: entry 1: send_time: is required"},
+		{ValidationError{Path: "a.json", Entry: 2, Message: "bad cron"}, "a.json: entry 2: bad cron"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
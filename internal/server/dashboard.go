@@ -0,0 +1,375 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// indexPage lists scheduled messages grouped by series
+type indexPage struct {
+	Groups []scheduler.ListGroup
+	Now    time.Time
+	Notice string
+	Error  string
+}
+
+// calendarPage is a month grid of scheduled messages, weeks starting Monday
+type calendarPage struct {
+	Month      time.Time
+	Prev, Next string
+	Weeks      [][]calendarDay
+	Error      string
+}
+
+// calendarDay is one cell of the grid; Day is 0 for padding outside the month
+type calendarDay struct {
+	Day      int
+	Messages []scheduler.ListedMessage
+}
+
+// formPage is the create form, refilled with the submitted values after an error
+type formPage struct {
+	Config    types.ScheduleConfig
+	Intervals []types.Interval
+	Error     string
+}
+
+// editPage edits one scheduled message
+type editPage struct {
+	Message scheduler.ListedMessage
+	Error   string
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	page := indexPage{Now: s.clock.Now(), Notice: r.URL.Query().Get("notice")}
+	messages, err := s.listMessages("")
+	if err != nil {
+		page.Error = err.Error()
+	}
+	page.Groups = scheduler.GroupMessages(messages, scheduler.GroupByText)
+	s.render(w, "index", page)
+}
+
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	month, err := scheduler.ParseMonth(r.URL.Query().Get("month"), s.clock.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page := calendarPage{
+		Month: month,
+		Prev:  month.AddDate(0, -1, 0).Format("2006-01"),
+		Next:  month.AddDate(0, 1, 0).Format("2006-01"),
+	}
+	messages, err := s.listMessages("")
+	if err != nil {
+		page.Error = err.Error()
+	}
+	page.Weeks = calendarWeeks(month, messages)
+	s.render(w, "calendar", page)
+}
+
+// calendarWeeks lays out a month as weeks of seven days starting Monday, with the
+// month's messages on their days
+func calendarWeeks(month time.Time, messages []scheduler.ListedMessage) [][]calendarDay {
+	last := month.AddDate(0, 1, -1).Day()
+	byDay := make(map[int][]scheduler.ListedMessage)
+	for _, msg := range messages {
+		t := msg.PostAt.In(month.Location())
+		if t.Year() == month.Year() && t.Month() == month.Month() {
+			byDay[t.Day()] = append(byDay[t.Day()], msg)
+		}
+	}
+
+	offset := (int(month.Weekday()) + 6) % 7
+	var weeks [][]calendarDay
+	week := make([]calendarDay, offset)
+	for day := 1; day <= last; day++ {
+		week = append(week, calendarDay{Day: day, Messages: byDay[day]})
+		if len(week) == 7 {
+			weeks = append(weeks, week)
+			week = nil
+		}
+	}
+	if len(week) > 0 {
+		week = append(week, make([]calendarDay, 7-len(week))...)
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+func (s *Server) handleNew(w http.ResponseWriter, r *http.Request) {
+	now := s.clock.Now().In(scheduler.LocalTZ)
+	s.render(w, "form", formPage{
+		Config:    types.ScheduleConfig{StartDate: now.Format("2006-01-02"), SendTime: "09:00", Interval: types.IntervalWeekly},
+		Intervals: types.ValidIntervals,
+	})
+}
+
+// handleSchedule creates a series from the submitted form and records it in the
+// store, like the schedule command
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	if !s.checkPost(w, r) {
+		return
+	}
+	config, err := configFromForm(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		s.render(w, "form", formPage{Config: config, Intervals: types.ValidIntervals, Error: err.Error()})
+		return
+	}
+
+	name, ids, err := s.scheduleSeries(config)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		s.render(w, "form", formPage{Config: config, Intervals: types.ValidIntervals, Error: err.Error()})
+		return
+	}
+	redirect(w, r, "/", fmt.Sprintf("Scheduled %d message(s) as %s", len(ids), name))
+}
+
+// scheduleSeries schedules config and records the series in the store, keeping
+// whatever was scheduled even if the rest failed or the server stopped waiting.
+// It returns the series name.
+func (s *Server) scheduleSeries(config types.ScheduleConfig) (string, []string, error) {
+	unlock, err := s.lockStore()
+	if err != nil {
+		return "", nil, err
+	}
+	defer unlock()
+	st, err := s.openStore()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Name the series up front so it doesn't replace one of the same name
+	if config.Name == "" {
+		config.Name = store.DefaultName(&config)
+	}
+	config.Name = st.UniqueName(config.Name)
+
+	sched := scheduler.New(s.client, &config)
+	sched.SetClock(s.clock)
+	sched.SetNotifier(s.notifier)
+	ids, scheduleErr := sched.ScheduleContext(s.ctx)
+	if len(ids) > 0 {
+		err := st.Put(sched.Series(config.Name))
+		if err == nil {
+			err = st.Save()
+		}
+		if err != nil && scheduleErr == nil {
+			scheduleErr = fmt.Errorf("scheduled %d message(s) but could not record them: %w", len(ids), err)
+		}
+	}
+	return config.Name, ids, scheduleErr
+}
+
+// configFromForm reads a schedule from the create form
+func configFromForm(r *http.Request) (types.ScheduleConfig, error) {
+	if err := r.ParseForm(); err != nil {
+		return types.ScheduleConfig{}, err
+	}
+	config := types.ScheduleConfig{
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		Channel:   strings.TrimSpace(r.FormValue("channel")),
+		Message:   r.FormValue("message"),
+		StartDate: r.FormValue("start_date"),
+		SendTime:  r.FormValue("send_time"),
+		EndDate:   r.FormValue("end_date"),
+		Interval:  types.Interval(r.FormValue("interval")),
+	}
+	if n := strings.TrimSpace(r.FormValue("repeat_count")); n != "" {
+		count, err := strconv.Atoi(n)
+		if err != nil || count < 0 {
+			return config, fmt.Errorf("invalid repeat count: %s", n)
+		}
+		config.RepeatCount = count
+	}
+
+	switch {
+	case config.Channel == "":
+		return config, fmt.Errorf("a channel is required")
+	case strings.TrimSpace(config.Message) == "":
+		return config, fmt.Errorf("a message is required")
+	case !config.Interval.IsValid():
+		return config, fmt.Errorf("invalid interval: %s", config.Interval)
+	}
+	return config, nil
+}
+
+// handleEdit shows (GET) or applies (POST) an edit of one scheduled message.
+// Slack can't change a scheduled message, so it is deleted and scheduled again;
+// the store's record of it follows.
+func (s *Server) handleEdit(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	channelID, id := r.FormValue("channel"), r.FormValue("id")
+	msg, err := s.findMessage(channelID, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		s.render(w, "edit", editPage{Message: msg})
+		return
+	}
+	if !s.checkPost(w, r) {
+		return
+	}
+
+	text := r.FormValue("text")
+	postAt, err := time.ParseInLocation("2006-01-02T15:04", r.FormValue("post_at"), scheduler.LocalTZ)
+	if err == nil && !postAt.After(s.clock.Now()) {
+		err = fmt.Errorf("the new time has already passed")
+	}
+	if err == nil && strings.TrimSpace(text) == "" {
+		err = fmt.Errorf("a message is required")
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		s.render(w, "edit", editPage{Message: msg, Error: err.Error()})
+		return
+	}
+
+	unlock, err := s.lockStore()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		s.render(w, "edit", editPage{Message: msg, Error: err.Error()})
+		return
+	}
+	defer unlock()
+	newID, err := s.client.ScheduleMessage(channelID, text, postAt)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		s.render(w, "edit", editPage{Message: msg, Error: err.Error()})
+		return
+	}
+	if err := s.client.DeleteScheduledMessage(channelID, id); err != nil {
+		// The replacement exists, so report the leftover rather than undo it
+		redirect(w, r, "/", fmt.Sprintf("Rescheduled, but the original could not be deleted: %v", err))
+		return
+	}
+
+	if err := s.updateOccurrence(msg, newID, text, postAt); err != nil {
+		redirect(w, r, "/", fmt.Sprintf("Message updated, but the store could not be: %v", err))
+		return
+	}
+	redirect(w, r, "/", "Message updated")
+}
+
+// updateOccurrence points the stored occurrence of msg, if it belongs to a
+// series, at its replacement
+func (s *Server) updateOccurrence(msg scheduler.ListedMessage, newID, text string, postAt time.Time) error {
+	st, err := s.openStore()
+	if err != nil {
+		return err
+	}
+	for _, series := range st.List() {
+		for i, occ := range series.Occurrences {
+			if occ.ScheduledID != msg.SlackID {
+				continue
+			}
+			series.Occurrences[i].ScheduledID = newID
+			series.Occurrences[i].Message = text
+			series.Occurrences[i].PostAt = postAt
+			if err := st.Put(series); err != nil {
+				return err
+			}
+			return st.Save()
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.checkPost(w, r) {
+		return
+	}
+	if err := s.client.DeleteScheduledMessage(r.FormValue("channel"), r.FormValue("id")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	redirect(w, r, "/", "Message deleted")
+}
+
+// listMessages lists the scheduled messages in a channel (all if empty),
+// annotated with their series from the store
+func (s *Server) listMessages(channelID string) ([]scheduler.ListedMessage, error) {
+	st, err := s.openStore()
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.ListMessages(s.client, st, channelID, nil)
+}
+
+// findMessage looks up one scheduled message by channel and Slack ID
+func (s *Server) findMessage(channelID, id string) (scheduler.ListedMessage, error) {
+	messages, err := s.listMessages(channelID)
+	if err != nil {
+		return scheduler.ListedMessage{}, err
+	}
+	for _, msg := range messages {
+		if msg.SlackID == id {
+			return msg, nil
+		}
+	}
+	return scheduler.ListedMessage{}, fmt.Errorf("scheduled message %s not found in %s", id, channelID)
+}
+
+// checkPost lets a form post through only if it came from the dashboard itself:
+// the browser must not mark it cross-site and it must carry the CSRF token the
+// dashboard's forms do. Otherwise any page the user visits could post to the
+// localhost server and schedule or delete messages with their token.
+func (s *Server) checkPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin form posts are not allowed", http.StatusForbidden)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(r.FormValue("csrf_token")), []byte(s.csrfToken)) != 1 {
+		http.Error(w, "missing or invalid CSRF token; reload the page and try again", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// sameOrigin reports whether a request wasn't sent from another site, going by
+// Sec-Fetch-Site where the browser sends it and the Origin header otherwise.
+// Requests with neither (curl, tests) pass; the CSRF token still guards them.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return false
+		}
+	}
+	return true
+}
+
+// redirect sends the browser to path after a form post, with a notice to show
+func redirect(w http.ResponseWriter, r *http.Request, path, notice string) {
+	http.Redirect(w, r, path+"?notice="+url.QueryEscape(notice), http.StatusSeeOther)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// handleMetrics serves Slack API and store metrics in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	snap := s.metrics.Snapshot()
+
+	methods := make([]string, 0, len(snap.Calls))
+	for method := range snap.Calls {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	metricHeader(w, "slack_scheduler_messages_scheduled_total", "counter", "Messages scheduled in Slack.")
+	fmt.Fprintf(w, "slack_scheduler_messages_scheduled_total %d\n", snap.Scheduled)
+	metricHeader(w, "slack_scheduler_messages_deleted_total", "counter", "Scheduled messages deleted from Slack.")
+	fmt.Fprintf(w, "slack_scheduler_messages_deleted_total %d\n", snap.Deleted)
+	metricHeader(w, "slack_scheduler_messages_sent_total", "counter", "Messages sent immediately.")
+	fmt.Fprintf(w, "slack_scheduler_messages_sent_total %d\n", snap.Sent)
+
+	metricHeader(w, "slack_scheduler_api_requests_total", "counter", "Slack API requests, counting each retry.")
+	for _, method := range methods {
+		fmt.Fprintf(w, "slack_scheduler_api_requests_total{method=%s} %d\n", label(method), snap.Calls[method].Calls)
+	}
+	metricHeader(w, "slack_scheduler_api_errors_total", "counter", "Slack API calls that failed after any retries.")
+	for _, method := range methods {
+		fmt.Fprintf(w, "slack_scheduler_api_errors_total{method=%s} %d\n", label(method), snap.Errors[method])
+	}
+	metricHeader(w, "slack_scheduler_api_rate_limited_total", "counter", "Slack API responses that were rate limited.")
+	for _, method := range methods {
+		fmt.Fprintf(w, "slack_scheduler_api_rate_limited_total{method=%s} %d\n", label(method), snap.RateLimited[method])
+	}
+	metricHeader(w, "slack_scheduler_api_request_duration_seconds", "summary", "Time spent in Slack API requests.")
+	for _, method := range methods {
+		stats := snap.Calls[method]
+		fmt.Fprintf(w, "slack_scheduler_api_request_duration_seconds_sum{method=%s} %g\n", label(method), stats.Total.Seconds())
+		fmt.Fprintf(w, "slack_scheduler_api_request_duration_seconds_count{method=%s} %d\n", label(method), stats.Calls)
+	}
+
+	st, err := s.openStore()
+	if err != nil {
+		// The API counters are still worth scraping
+		fmt.Fprintf(w, "# store unavailable: %s\n", strings.ReplaceAll(err.Error(), "\n", " "))
+		return
+	}
+	now := s.clock.Now()
+	var total, managed, paused, exhausted int
+	next := make(map[string]time.Duration)
+	for _, series := range st.List() {
+		total++
+		if series.Paused {
+			paused++
+		}
+		active := series.Managed && !series.Paused
+		if active {
+			managed++
+		}
+		upcoming := false
+		for _, occ := range series.Occurrences {
+			if occ.Skipped || !occ.PostAt.After(now) {
+				continue
+			}
+			if d, seen := next[series.Name]; !seen || occ.PostAt.Sub(now) < d {
+				next[series.Name] = occ.PostAt.Sub(now)
+			}
+			upcoming = true
+		}
+		if active && !upcoming {
+			exhausted++
+		}
+	}
+
+	metricHeader(w, "slack_scheduler_series", "gauge", "Series in the local store.")
+	fmt.Fprintf(w, "slack_scheduler_series %d\n", total)
+	metricHeader(w, "slack_scheduler_series_managed", "gauge", "Series the daemon keeps extending (excluding paused ones).")
+	fmt.Fprintf(w, "slack_scheduler_series_managed %d\n", managed)
+	metricHeader(w, "slack_scheduler_series_paused", "gauge", "Paused series.")
+	fmt.Fprintf(w, "slack_scheduler_series_paused %d\n", paused)
+	metricHeader(w, "slack_scheduler_series_exhausted", "gauge", "Managed series with nothing left scheduled, i.e. the daemon has fallen behind.")
+	fmt.Fprintf(w, "slack_scheduler_series_exhausted %d\n", exhausted)
+
+	names := make([]string, 0, len(next))
+	for name := range next {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metricHeader(w, "slack_scheduler_series_next_occurrence_seconds", "gauge", "Seconds until each series' next scheduled message.")
+	for _, name := range names {
+		fmt.Fprintf(w, "slack_scheduler_series_next_occurrence_seconds{series=%s} %g\n", label(name), next[name].Seconds())
+	}
+}
+
+func metricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labelEscaper escapes a label value for the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func label(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
@@ -0,0 +1,256 @@
+// Package server implements serve mode: a small HTTP server that exposes the
+// scheduler to people who won't use the CLI
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/notify"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Server serves the web dashboard. It has no authentication of its own, so it
+// should only listen on localhost or behind a proxy that adds it, and it only
+// answers requests addressed to the hosts SetAllowedHosts lists.
+type Server struct {
+	client    *slack.Client
+	storePath string
+	clock     scheduler.Clock
+
+	// Signing secret of the Slack app whose slash commands are served (empty
+	// disables them)
+	signingSecret string
+
+	// Messages the trigger webhook can send, by name, and the token callers must
+	// present (empty disables the webhook)
+	triggers     map[string]types.ScheduleConfig
+	triggerToken string
+
+	// Told about series the server schedules (nil for none)
+	notifier notify.Notifier
+
+	// API counters for /metrics
+	metrics *slack.Metrics
+
+	// Random per process and embedded in the dashboard's forms, so other sites
+	// can't post them from the user's browser
+	csrfToken string
+
+	// Host header values answered, so a page on another name resolving to this
+	// machine (DNS rebinding) can't read the dashboard as same-origin
+	allowedHosts []string
+
+	pages map[string]*template.Template
+	mux   *http.ServeMux
+
+	// Serializes this server's changes to the series store, which is loaded and
+	// saved whole; lockStore also holds the lock file shared with other processes
+	storeMu sync.Mutex
+
+	// Series still being scheduled after their request was answered (slash
+	// commands), which Shutdown waits for. ctx is cancelled if it gives up.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	tasks    sync.WaitGroup
+	tasksMu  sync.Mutex
+	stopping bool
+}
+
+// New creates a server for the workspace behind client and the series store at
+// storePath
+func New(client *slack.Client, storePath string) *Server {
+	csrfToken := newCSRFToken()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		client:    client,
+		storePath: storePath,
+		clock:     scheduler.SystemClock{},
+		csrfToken: csrfToken,
+		pages:     parsePages(csrfToken),
+		mux:       http.NewServeMux(),
+		metrics:   client.EnableMetrics(),
+		ctx:       ctx,
+		cancel:    cancel,
+
+		allowedHosts: []string{"localhost", "127.0.0.1", "::1"},
+	}
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/calendar", s.handleCalendar)
+	s.mux.HandleFunc("/new", s.handleNew)
+	s.mux.HandleFunc("/schedule", s.handleSchedule)
+	s.mux.HandleFunc("/messages/edit", s.handleEdit)
+	s.mux.HandleFunc("/messages/delete", s.handleDelete)
+	s.mux.HandleFunc("/slack/commands", s.handleSlashCommand)
+	s.mux.HandleFunc("/trigger/", s.handleTrigger)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+	return s
+}
+
+// SetClock replaces the wall clock, e.g. with a scheduler.FakeClock in tests
+func (s *Server) SetClock(clock scheduler.Clock) {
+	s.clock = clock
+}
+
+// SetSigningSecret enables the /schedule slash command at /slack/commands for the
+// Slack app with this signing secret
+func (s *Server) SetSigningSecret(secret string) {
+	s.signingSecret = secret
+}
+
+// SetNotifier reports the outcome of each series the server schedules
+func (s *Server) SetNotifier(n notify.Notifier) {
+	s.notifier = n
+}
+
+// SetAllowedHosts replaces the Host header values the server answers to, by
+// default localhost, 127.0.0.1 and [::1] on any port. An entry with a port
+// (e.g. "localhost:8080") only matches that port. Behind a proxy or tunnel, list
+// the name it forwards under too.
+func (s *Server) SetAllowedHosts(hosts ...string) {
+	s.allowedHosts = hosts
+}
+
+// LoopbackHosts returns the loopback host names with port, for SetAllowedHosts
+// when the server listens locally on that port
+func LoopbackHosts(port string) []string {
+	return []string{net.JoinHostPort("localhost", port), net.JoinHostPort("127.0.0.1", port), net.JoinHostPort("::1", port)}
+}
+
+// ServeHTTP implements http.Handler, refusing requests for hosts not allowed
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedHost(r.Host) {
+		http.Error(w, fmt.Sprintf("host %q is not allowed", r.Host), http.StatusMisdirectedRequest)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// allowedHost reports whether host (a Host header, with or without a port)
+// matches one of the allowed hosts
+func (s *Server) allowedHost(host string) bool {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = strings.Trim(host, "[]"), ""
+	}
+	for _, allowed := range s.allowedHosts {
+		if allowedName, allowedPort, err := net.SplitHostPort(allowed); err == nil {
+			if strings.EqualFold(allowedName, name) && allowedPort == port {
+				return true
+			}
+		} else if strings.EqualFold(strings.Trim(allowed, "[]"), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Shutdown waits for series still being scheduled in the background, so stopping
+// the server doesn't leave one half done. If ctx ends first, their remaining
+// Slack calls are cancelled (what was scheduled is still recorded) and ctx's
+// error is returned. Call it after http.Server.Shutdown.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.tasksMu.Lock()
+	s.stopping = true
+	s.tasksMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.tasks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// background runs task on its own goroutine with the server's context, unless
+// the server is shutting down
+func (s *Server) background(task func(ctx context.Context)) bool {
+	s.tasksMu.Lock()
+	defer s.tasksMu.Unlock()
+	if s.stopping {
+		return false
+	}
+	s.tasks.Add(1)
+	go func() {
+		defer s.tasks.Done()
+		task(s.ctx)
+	}()
+	return true
+}
+
+// newCSRFToken returns an unguessable token for the dashboard's forms
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate CSRF token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// parsePages parses each page template together with the shared layout; forms
+// include csrfToken
+func parsePages(csrfToken string) map[string]*template.Template {
+	funcs := template.FuncMap{
+		"csrfToken": func() string { return csrfToken },
+		"datetime":  func(t time.Time) string { return t.In(scheduler.LocalTZ).Format("Mon Jan 2 15:04") },
+		"inputTime": func(t time.Time) string {
+			return t.In(scheduler.LocalTZ).Format("2006-01-02T15:04")
+		},
+	}
+	pages := make(map[string]*template.Template)
+	for _, page := range []string{"index", "calendar", "form", "edit"} {
+		pages[page] = template.Must(template.New("layout.html").Funcs(funcs).ParseFS(templateFS, "templates/layout.html", "templates/"+page+".html"))
+	}
+	return pages
+}
+
+// render writes a page, or a 500 if its template fails
+func (s *Server) render(w http.ResponseWriter, page string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.pages[page].Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// openStore loads the series store; callers changing it hold lockStore
+func (s *Server) openStore() (*store.Store, error) {
+	return store.Open(s.storePath)
+}
+
+// lockStore locks the series store against other requests and other processes
+// (the daemon, the CLI) until the returned function is called
+func (s *Server) lockStore() (func(), error) {
+	s.storeMu.Lock()
+	unlock, err := store.Lock(s.storePath)
+	if err != nil {
+		s.storeMu.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		s.storeMu.Unlock()
+	}, nil
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/store"
+)
+
+func newTestServer(t *testing.T) (*Server, *slack.FakeAPI, string) {
+	t.Helper()
+	api := slack.NewFakeAPI()
+	api.AddChannel("general")
+	storePath := filepath.Join(t.TempDir(), store.FileName)
+	s := New(slack.NewFakeClient(api), storePath)
+	s.SetClock(scheduler.NewFakeClock(time.Date(2030, 1, 1, 8, 0, 0, 0, scheduler.LocalTZ)))
+	return s, api, storePath
+}
+
+// post submits a dashboard form, with the CSRF token its pages would include
+func post(s *Server, path string, form url.Values) *httptest.ResponseRecorder {
+	if form.Get("csrf_token") == "" {
+		form.Set("csrf_token", s.csrfToken)
+	}
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080"+path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func get(s *Server, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://localhost:8080"+path, nil))
+	return rec
+}
+
+func TestServer_ScheduleListEditDelete(t *testing.T) {
+	s, api, storePath := newTestServer(t)
+
+	rec := post(s, "/schedule", url.Values{
+		"name":         {"standup"},
+		"channel":      {"#general"},
+		"message":      {"Standup time"},
+		"start_date":   {"2030-01-02"},
+		"send_time":    {"09:00"},
+		"interval":     {"daily"},
+		"repeat_count": {"3"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("POST /schedule status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if len(api.Scheduled) != 3 {
+		t.Fatalf("scheduled %d messages, want 3", len(api.Scheduled))
+	}
+	st, _ := store.Open(storePath)
+	if series, ok := st.Get("standup"); !ok || len(series.Occurrences) != 3 {
+		t.Fatalf("store series = %+v, %v, want 3 occurrences", series, ok)
+	}
+
+	rec = get(s, "/")
+	if rec.Code != http.StatusOK || strings.Count(rec.Body.String(), "<td>Standup time</td>") != 3 {
+		t.Errorf("GET / = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	rec = get(s, "/calendar?month=2030-01")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "January 2030") || strings.Count(rec.Body.String(), "09:00") != 3 {
+		t.Errorf("GET /calendar = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	first := api.Scheduled[0]
+	rec = get(s, "/messages/edit?channel="+first.Channel+"&id="+first.ID)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "2030-01-02T09:00") {
+		t.Errorf("GET /messages/edit = %d, body = %s", rec.Code, rec.Body)
+	}
+	rec = post(s, "/messages/edit", url.Values{
+		"channel": {first.Channel},
+		"id":      {first.ID},
+		"text":    {"Standup moved"},
+		"post_at": {"2030-01-02T10:30"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("POST /messages/edit status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if len(api.Scheduled) != 3 || api.Scheduled[2].Text != "Standup moved" {
+		t.Errorf("after edit scheduled = %+v", api.Scheduled)
+	}
+	st, _ = store.Open(storePath)
+	if series, _ := st.Get("standup"); series.Occurrences[0].Message != "Standup moved" {
+		t.Errorf("store occurrence after edit = %+v", series.Occurrences[0])
+	}
+
+	rec = post(s, "/messages/delete", url.Values{"channel": {first.Channel}, "id": {api.Scheduled[0].ID}})
+	if rec.Code != http.StatusSeeOther || len(api.Scheduled) != 2 {
+		t.Errorf("POST /messages/delete = %d, %d left", rec.Code, len(api.Scheduled))
+	}
+}
+
+func TestServer_ScheduleRejectsInvalidForm(t *testing.T) {
+	s, api, _ := newTestServer(t)
+
+	rec := post(s, "/schedule", url.Values{"channel": {"#general"}, "message": {"Hi"}, "interval": {"fortnightly"}})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid interval") {
+		t.Errorf("POST /schedule = %d, body = %s", rec.Code, rec.Body)
+	}
+	if len(api.Scheduled) != 0 {
+		t.Errorf("scheduled %d messages, want 0", len(api.Scheduled))
+	}
+	if rec := get(s, "/schedule"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /schedule status = %d, want 405", rec.Code)
+	}
+}
+
+func TestServer_RejectsCrossSiteForms(t *testing.T) {
+	s, api, _ := newTestServer(t)
+	id, err := slack.NewFakeClient(api).ScheduleMessage("C0000001", "Standup", time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := func() url.Values { return url.Values{"channel": {"C0000001"}, "id": {id}} }
+
+	if rec := get(s, "/"); !strings.Contains(rec.Body.String(), `name="csrf_token" value="`+s.csrfToken+`"`) {
+		t.Errorf("GET / forms lack the CSRF token: %s", rec.Body)
+	}
+
+	bad := form()
+	bad.Set("csrf_token", "guessed")
+	if rec := post(s, "/messages/delete", bad); rec.Code != http.StatusForbidden {
+		t.Errorf("POST with a wrong token status = %d, want 403", rec.Code)
+	}
+
+	for _, header := range [][2]string{{"Sec-Fetch-Site", "cross-site"}, {"Origin", "https://evil.example"}} {
+		values := form()
+		values.Set("csrf_token", s.csrfToken)
+		req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/messages/delete", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(header[0], header[1])
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("POST with %s: %s status = %d, want 403", header[0], header[1], rec.Code)
+		}
+	}
+	if len(api.Scheduled) != 1 {
+		t.Fatalf("%d messages left, want the forged deletes refused", len(api.Scheduled))
+	}
+
+	if rec := post(s, "/messages/delete", form()); rec.Code != http.StatusSeeOther || len(api.Scheduled) != 0 {
+		t.Errorf("POST with the token = %d, %d left", rec.Code, len(api.Scheduled))
+	}
+}
+
+func TestServer_RejectsUnknownHosts(t *testing.T) {
+	s, _, _ := newTestServer(t)
+	request := func(host, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A rebound name reaches the server but mustn't get the page or its token
+	for _, path := range []string{"/", "/metrics", "/trigger/standup"} {
+		if rec := request("attacker.example:8080", path); rec.Code != http.StatusMisdirectedRequest || strings.Contains(rec.Body.String(), s.csrfToken) {
+			t.Errorf("GET %s for another host status = %d, want 421", path, rec.Code)
+		}
+	}
+	for _, host := range []string{"localhost:8080", "127.0.0.1:8080", "[::1]:8080", "LOCALHOST"} {
+		if rec := request(host, "/"); rec.Code != http.StatusOK {
+			t.Errorf("GET / for %s status = %d, want 200", host, rec.Code)
+		}
+	}
+
+	s.SetAllowedHosts(LoopbackHosts("8080")...)
+	if rec := request("localhost:9090", "/"); rec.Code != http.StatusMisdirectedRequest {
+		t.Errorf("GET / on another port status = %d, want 421", rec.Code)
+	}
+	if rec := request("[::1]:8080", "/"); rec.Code != http.StatusOK {
+		t.Errorf("GET / on the listen port status = %d, want 200", rec.Code)
+	}
+}
+
+func TestCalendarWeeks(t *testing.T) {
+	// March 2030 starts on a Friday and has 31 days
+	month := time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+	msg := scheduler.ListedMessage{Text: "hi", PostAt: time.Date(2030, 3, 18, 9, 0, 0, 0, time.UTC)}
+	weeks := calendarWeeks(month, []scheduler.ListedMessage{msg})
+
+	if len(weeks) != 5 {
+		t.Fatalf("len(weeks) = %d, want 5", len(weeks))
+	}
+	if weeks[0][3].Day != 0 || weeks[0][4].Day != 1 {
+		t.Errorf("first week = %+v, want the 1st on Friday", weeks[0])
+	}
+	if weeks[3][0].Day != 18 || len(weeks[3][0].Messages) != 1 {
+		t.Errorf("weeks[3][0] = %+v, want the 18th with one message", weeks[3][0])
+	}
+	if weeks[4][6].Day != 31 {
+		t.Errorf("last day = %+v, want the 31st on Sunday", weeks[4][6])
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	s, _, _ := newTestServer(t)
+	post(s, "/schedule", url.Values{
+		"name": {"standup"}, "channel": {"#general"}, "message": {"Standup time"},
+		"start_date": {"2030-01-02"}, "send_time": {"09:00"}, "interval": {"daily"}, "repeat_count": {"2"},
+	})
+
+	rec := get(s, "/metrics")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE slack_scheduler_messages_scheduled_total counter\n",
+		"slack_scheduler_messages_scheduled_total 2\n",
+		`slack_scheduler_api_requests_total{method="chat.scheduleMessage"} 2` + "\n",
+		`slack_scheduler_api_errors_total{method="chat.scheduleMessage"} 0` + "\n",
+		"slack_scheduler_series 1\n",
+		// The first standup is 25 hours after the test clock's 8:00
+		`slack_scheduler_series_next_occurrence_seconds{series="standup"} 90000` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET /metrics missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	if got := label("a \"b\"\\c\nd"); got != `"a \"b\"\\c\nd"` {
+		t.Errorf("label() = %s", got)
+	}
+}
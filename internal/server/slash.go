@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/slack"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// slashUsage is the reply to /schedule help, or to a command that can't be read
+const slashUsage = "Usage: `/schedule \"message\" <when> [in #channel]`, e.g.\n" +
+	"• `/schedule \"Standup in 5\" every weekday 9:55am`\n" +
+	"• `/schedule \"Retro notes due\" every other week on friday 2pm in #team`\n" +
+	"• `/schedule \"Release freeze starts\" tomorrow at noon`\n" +
+	"Without a channel, messages go to the channel the command was run in; any other\n" +
+	"channel has to be one you're in."
+
+// slashChannelPattern matches a trailing "in #channel" or "to #channel", including
+// the <#C123|name> form Slack sends when it escapes channel mentions
+var slashChannelPattern = regexp.MustCompile(`\s+(?:in|to)\s+(<#([A-Z0-9]+)(?:\|[^>]*)?>|#([\w.-]+))\s*$`)
+
+// handleSlashCommand answers /schedule. The request is acknowledged straight
+// away, since Slack gives up after three seconds, and the outcome is sent to the
+// command's response URL once the series is scheduled.
+func (s *Server) handleSlashCommand(w http.ResponseWriter, r *http.Request) {
+	if s.signingSecret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	cmd, err := slack.ParseSlashCommand(r, s.signingSecret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if text := strings.TrimSpace(cmd.Text); text == "" || text == "help" {
+		reply(w, slashUsage)
+		return
+	}
+	config, err := parseSlashText(cmd.Text, cmd.ChannelID, s.clock.Now().In(scheduler.LocalTZ))
+	if err != nil {
+		reply(w, fmt.Sprintf("Couldn't read that: %v\n\n%s", err, slashUsage))
+		return
+	}
+	if !s.background(func(ctx context.Context) { s.runSlashCommand(ctx, cmd, config) }) {
+		reply(w, "The scheduler is shutting down, try again in a minute.")
+		return
+	}
+	reply(w, fmt.Sprintf("Scheduling %q…", config.Message))
+}
+
+// runSlashCommand schedules a series for a slash command and sends the outcome
+// to its response URL. Messages only go to another channel than the one the
+// command was run in if its user is a member, since they're posted as the
+// token's user.
+func (s *Server) runSlashCommand(ctx context.Context, cmd slack.SlashCommand, config types.ScheduleConfig) {
+	var text string
+	if channelID, err := s.slashChannel(ctx, cmd, config.Channel); err != nil {
+		text = fmt.Sprintf("Couldn't schedule that: %v", err)
+	} else {
+		config.Channel = channelID
+		name, ids, err := s.scheduleSeries(config)
+		text = fmt.Sprintf("Scheduled %d message(s) as `%s`", len(ids), name)
+		if err != nil {
+			text = fmt.Sprintf("Scheduled %d message(s), then failed: %v", len(ids), err)
+		}
+	}
+	// The reply still goes out if the server stopped waiting for the series
+	if err := slack.Respond(context.WithoutCancel(ctx), cmd.ResponseURL, text); err != nil {
+		slog.Warn(err.Error(), "command", cmd.Command, "error", err)
+	}
+}
+
+// slashChannel resolves the channel a slash command named to its ID, after
+// checking the command's user is in it
+func (s *Server) slashChannel(ctx context.Context, cmd slack.SlashCommand, channel string) (string, error) {
+	if channel == cmd.ChannelID {
+		return channel, nil
+	}
+	client := s.client.WithContext(ctx)
+	channelID, err := client.GetChannelID(channel)
+	if err != nil {
+		return "", err
+	}
+	if channelID == cmd.ChannelID {
+		return channelID, nil
+	}
+	member, err := client.IsChannelMember(channelID, cmd.UserID)
+	if err != nil {
+		return "", err
+	}
+	if !member {
+		return "", fmt.Errorf("you can only schedule messages into channels you're in")
+	}
+	return channelID, nil
+}
+
+// parseSlashText reads `"message" <when> [in #channel]` into a schedule for
+// channelID unless another channel is named
+func parseSlashText(text, channelID string, now time.Time) (types.ScheduleConfig, error) {
+	text = strings.TrimSpace(text)
+
+	// The message is quoted; the schedule after it can't contain quotes, so the
+	// last one closes it
+	open := strings.IndexAny(text, `"“`)
+	end := strings.LastIndexAny(text, `"”`)
+	if open != 0 || end <= 0 {
+		return types.ScheduleConfig{}, fmt.Errorf("put the message in quotes")
+	}
+	_, size := utf8.DecodeRuneInString(text)
+	message := strings.TrimSpace(text[size:end])
+	_, size = utf8.DecodeRuneInString(text[end:])
+	rest := text[end+size:]
+	if message == "" {
+		return types.ScheduleConfig{}, fmt.Errorf("the message is empty")
+	}
+
+	if m := slashChannelPattern.FindStringSubmatch(rest); m != nil {
+		channelID = m[2]
+		if channelID == "" {
+			channelID = m[3]
+		}
+		rest = rest[:len(rest)-len(m[0])]
+	}
+
+	config, err := scheduler.ParsePhrase(rest, now)
+	if err != nil {
+		return config, err
+	}
+	config.Message = message
+	config.Channel = channelID
+	return config, nil
+}
+
+// reply answers a slash command with a message only its user sees
+func reply(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(slack.EphemeralResponse(text))
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// postSlashCommand sends a slash command signed the way Slack signs them
+func postSlashCommand(s *Server, form url.Values, secret string) *httptest.ResponseRecorder {
+	body := form.Encode()
+	ts := fmt.Sprint(time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/slack/commands", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+// newResponder stands in for a slash command's response URL, passing on the
+// text of each reply
+func newResponder(t *testing.T) (*httptest.Server, chan string) {
+	responses := make(chan string, 1)
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct{ Text string }
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &msg)
+		responses <- msg.Text
+	}))
+	t.Cleanup(responder.Close)
+	return responder, responses
+}
+
+func TestServer_SlashCommand(t *testing.T) {
+	s, api, _ := newTestServer(t)
+	s.SetSigningSecret(testSigningSecret)
+	channelID := api.Channels[0].ID
+	responder, responses := newResponder(t)
+
+	form := url.Values{
+		"command":      {"/schedule"},
+		"text":         {`"Retro notes due" every friday 2pm 3 times`},
+		"channel_id":   {channelID},
+		"user_id":      {"U0SELF"},
+		"response_url": {responder.URL},
+	}
+	rec := postSlashCommand(s, form, testSigningSecret)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Scheduling") {
+		t.Fatalf("slash command = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	select {
+	case text := <-responses:
+		if !strings.Contains(text, "Scheduled 3 message(s)") {
+			t.Errorf("response = %q, want 3 scheduled", text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response sent to response_url")
+	}
+	if len(api.Scheduled) != 3 || api.Scheduled[0].Text != "Retro notes due" || api.Scheduled[0].Channel != channelID {
+		t.Errorf("scheduled = %+v", api.Scheduled)
+	}
+
+	if rec := postSlashCommand(s, form, "wrong-secret"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("badly signed command status = %d, want 401", rec.Code)
+	}
+	form.Set("text", "every friday")
+	if rec := postSlashCommand(s, form, testSigningSecret); !strings.Contains(rec.Body.String(), "quotes") {
+		t.Errorf("unquoted command reply = %s", rec.Body)
+	}
+}
+
+func TestServer_SlashCommand_OtherChannel(t *testing.T) {
+	s, api, _ := newTestServer(t)
+	s.SetSigningSecret(testSigningSecret)
+	here := api.Channels[0].ID
+	team := api.AddChannel("team")
+	responder, responses := newResponder(t)
+
+	form := url.Values{
+		"command":      {"/schedule"},
+		"text":         {`"Retro notes due" tomorrow 2pm in #team`},
+		"channel_id":   {here},
+		"user_id":      {"U0OUTSIDER"},
+		"response_url": {responder.URL},
+	}
+	run := func() string {
+		t.Helper()
+		postSlashCommand(s, form, testSigningSecret)
+		select {
+		case text := <-responses:
+			return text
+		case <-time.After(5 * time.Second):
+			t.Fatal("no response sent to response_url")
+			return ""
+		}
+	}
+
+	if text := run(); !strings.Contains(text, "channels you're in") {
+		t.Errorf("response for a non-member = %q", text)
+	}
+	if len(api.Scheduled) != 0 {
+		t.Fatalf("scheduled into a channel the user isn't in: %+v", api.Scheduled)
+	}
+
+	api.Members = map[string][]string{team: {"U0SELF", "U0OUTSIDER"}}
+	if text := run(); !strings.Contains(text, "Scheduled 1 message(s)") {
+		t.Errorf("response for a member = %q", text)
+	}
+	if len(api.Scheduled) != 1 || api.Scheduled[0].Channel != team {
+		t.Errorf("scheduled = %+v, want one message in %s", api.Scheduled, team)
+	}
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	s, api, _ := newTestServer(t)
+	s.SetSigningSecret(testSigningSecret)
+	responder, responses := newResponder(t)
+
+	form := url.Values{
+		"command":      {"/schedule"},
+		"text":         {`"Retro notes due" every friday 2pm 3 times`},
+		"channel_id":   {api.Channels[0].ID},
+		"user_id":      {"U0SELF"},
+		"response_url": {responder.URL},
+	}
+	postSlashCommand(s, form, testSigningSecret)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	// The series in flight was finished before Shutdown returned
+	if len(api.Scheduled) != 3 {
+		t.Errorf("scheduled %d message(s) by shutdown, want 3", len(api.Scheduled))
+	}
+	<-responses
+
+	if rec := postSlashCommand(s, form, testSigningSecret); !strings.Contains(rec.Body.String(), "shutting down") {
+		t.Errorf("command after shutdown reply = %s", rec.Body)
+	}
+}
+
+func TestParseSlashText(t *testing.T) {
+	now := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	config, err := parseSlashText(`“Deploy window opens” every weekday 9am to <#C0TEAM|team>`, "C0HERE", now)
+	if err != nil {
+		t.Fatalf("parseSlashText() error = %v", err)
+	}
+	if config.Message != "Deploy window opens" || config.Channel != "C0TEAM" || config.Interval != types.IntervalWeekly || config.SendTime != "09:00" {
+		t.Errorf("parseSlashText() = %+v", config)
+	}
+
+	config, err = parseSlashText(`"Say "hi" to the new folks" tomorrow in #general`, "C0HERE", now)
+	if err != nil || config.Message != `Say "hi" to the new folks` || config.Channel != "general" || config.StartDate != "2030-01-02" {
+		t.Errorf("parseSlashText() = %+v, %v", config, err)
+	}
+
+	if config, err := parseSlashText(`"Lunch" noon`, "C0HERE", now); err != nil || config.Channel != "C0HERE" {
+		t.Errorf("parseSlashText() channel = %+v, %v, want the current channel", config, err)
+	}
+	for _, text := range []string{`Lunch noon`, `"" noon`, `"Lunch" whenever`} {
+		if _, err := parseSlashText(text, "C0HERE", now); err == nil {
+			t.Errorf("parseSlashText(%q) expected an error", text)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/scheduler"
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// maxTriggerPayload bounds the JSON body a trigger accepts
+const maxTriggerPayload = 1 << 20
+
+// triggerResult is the JSON reply to a trigger
+type triggerResult struct {
+	Sent      bool   `json:"sent,omitempty"`
+	Series    string `json:"series,omitempty"`
+	Scheduled int    `json:"scheduled,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SetTriggers enables the /trigger/{name} webhook for the given schedules, keyed
+// by their names, e.g. as loaded from a schedule file. Callers must present token
+// as a bearer token or a token query parameter.
+func (s *Server) SetTriggers(schedules []types.ScheduleConfig, token string) error {
+	if token == "" {
+		return fmt.Errorf("triggers need a token")
+	}
+	triggers := make(map[string]types.ScheduleConfig, len(schedules))
+	for i, config := range schedules {
+		if config.Name == "" {
+			return fmt.Errorf("trigger %d has no name", i+1)
+		}
+		if _, dup := triggers[config.Name]; dup {
+			return fmt.Errorf("duplicate trigger name: %s", config.Name)
+		}
+		triggers[config.Name] = config
+	}
+	s.triggers = triggers
+	s.triggerToken = token
+	return nil
+}
+
+// handleTrigger sends or schedules a predefined message when CI or monitoring
+// POSTs to /trigger/{name}. For a templated message, a JSON object body is
+// merged into its template data, so {{.version}} can come from the caller. A trigger without a
+// start date is sent straight away; ?in=2h delays it and ?send=now forces it.
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	if s.triggerToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.triggerAuthorized(r) {
+		writeTrigger(w, http.StatusUnauthorized, triggerResult{Error: "invalid or missing token"})
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/trigger/")
+	trigger, ok := s.triggers[name]
+	if !ok {
+		writeTrigger(w, http.StatusNotFound, triggerResult{Error: fmt.Sprintf("no trigger named %q", name)})
+		return
+	}
+
+	config, err := triggerConfig(trigger, r)
+	if err != nil {
+		writeTrigger(w, http.StatusBadRequest, triggerResult{Error: err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("send") == "now" || (config.In == "" && config.StartDate == "" && config.Cron == "" && config.RRule == "") {
+		sched := scheduler.New(s.client, &config)
+		sched.SetClock(s.clock)
+		if err := sched.SendNow(); err != nil {
+			writeTrigger(w, http.StatusBadGateway, triggerResult{Error: err.Error()})
+			return
+		}
+		writeTrigger(w, http.StatusOK, triggerResult{Sent: true})
+		return
+	}
+
+	series, ids, err := s.scheduleSeries(config)
+	if err != nil {
+		writeTrigger(w, http.StatusBadGateway, triggerResult{Series: series, Scheduled: len(ids), Error: err.Error()})
+		return
+	}
+	writeTrigger(w, http.StatusOK, triggerResult{Series: series, Scheduled: len(ids)})
+}
+
+// triggerAuthorized reports whether the request carries the trigger token
+func (s *Server) triggerAuthorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.triggerToken)) == 1
+}
+
+// triggerConfig is a trigger's schedule with ?in applied and, for a template, the
+// request's payload merged into its template data. A plain message is sent as
+// written, so braces in it stay literal and a payload is ignored.
+func triggerConfig(trigger types.ScheduleConfig, r *http.Request) (types.ScheduleConfig, error) {
+	config := trigger
+	if config.Template {
+		config.Data = make(map[string]interface{}, len(trigger.Data))
+		for k, v := range trigger.Data {
+			config.Data[k] = v
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxTriggerPayload))
+		if err != nil {
+			return config, err
+		}
+		if len(strings.TrimSpace(string(body))) > 0 {
+			var payload map[string]interface{}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				return config, fmt.Errorf("payload must be a JSON object: %w", err)
+			}
+			for k, v := range payload {
+				config.Data[k] = v
+			}
+		}
+	}
+
+	if in := r.URL.Query().Get("in"); in != "" {
+		config.In = in
+	}
+	return config, nil
+}
+
+func writeTrigger(w http.ResponseWriter, status int, result triggerResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
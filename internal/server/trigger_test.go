@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func postTrigger(s *Server, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080"+path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Trigger(t *testing.T) {
+	s, api, _ := newTestServer(t)
+	err := s.SetTriggers([]types.ScheduleConfig{
+		{Name: "deploy", Channel: "general", Message: "Deploying {{.version}} to {{.env}}", Template: true, Data: map[string]interface{}{"env": "prod"}},
+		{Name: "window", Channel: "general", Message: "Deploy window opens for {{.version}}", Template: true, In: "1h"},
+		{Name: "plain", Channel: "general", Message: "Use {{braces}} as written"},
+	}, "s3cret")
+	if err != nil {
+		t.Fatalf("SetTriggers() error = %v", err)
+	}
+
+	rec := postTrigger(s, "/trigger/deploy", "s3cret", `{"version":"1.2.3"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"sent":true`) {
+		t.Fatalf("trigger deploy = %d, body = %s", rec.Code, rec.Body)
+	}
+	if len(api.Posted) != 1 || api.Posted[0].Text != "Deploying 1.2.3 to prod" {
+		t.Errorf("posted = %+v", api.Posted)
+	}
+
+	rec = postTrigger(s, "/trigger/window?in=2h", "s3cret", `{"version":"1.2.3"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"scheduled":1`) {
+		t.Fatalf("trigger window = %d, body = %s", rec.Code, rec.Body)
+	}
+	if len(api.Scheduled) != 1 || api.Scheduled[0].Text != "Deploy window opens for 1.2.3" {
+		t.Errorf("scheduled = %+v", api.Scheduled)
+	}
+	if at := s.clock.Now().Unix() + 2*3600; int64(api.Scheduled[0].PostAt) != at {
+		t.Errorf("scheduled post_at = %d, want %d", api.Scheduled[0].PostAt, at)
+	}
+
+	// A trigger that isn't a template is sent as written, payload or not
+	rec = postTrigger(s, "/trigger/plain", "s3cret", `{"braces":"x"}`)
+	if rec.Code != http.StatusOK || len(api.Posted) != 2 || api.Posted[1].Text != "Use {{braces}} as written" {
+		t.Errorf("trigger plain = %d, posted = %+v", rec.Code, api.Posted)
+	}
+
+	tests := []struct {
+		path, token, body string
+		want              int
+	}{
+		{"/trigger/deploy", "", `{}`, http.StatusUnauthorized},
+		{"/trigger/deploy", "wrong", `{}`, http.StatusUnauthorized},
+		{"/trigger/missing", "s3cret", `{}`, http.StatusNotFound},
+		{"/trigger/deploy", "s3cret", `["not", "an", "object"]`, http.StatusBadRequest},
+		// The template needs a version
+		{"/trigger/deploy", "s3cret", ``, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		if rec := postTrigger(s, tt.path, tt.token, tt.body); rec.Code != tt.want {
+			t.Errorf("POST %s (token %q) = %d, want %d: %s", tt.path, tt.token, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
+
+func TestServer_TriggerDisabled(t *testing.T) {
+	s, _, _ := newTestServer(t)
+	if rec := postTrigger(s, "/trigger/deploy", "", `{}`); rec.Code != http.StatusNotFound {
+		t.Errorf("trigger without SetTriggers = %d, want 404", rec.Code)
+	}
+	if err := s.SetTriggers([]types.ScheduleConfig{{Name: "a"}, {Name: "a"}}, "t"); err == nil {
+		t.Error("SetTriggers() with duplicate names expected an error")
+	}
+	if err := s.SetTriggers([]types.ScheduleConfig{{Name: "a"}}, ""); err == nil {
+		t.Error("SetTriggers() without a token expected an error")
+	}
+}
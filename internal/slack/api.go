@@ -0,0 +1,122 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// API is the part of the Slack Web API the client uses. webAPI implements it
+// over slack-go; FakeAPI is an in-memory stand-in for tests.
+type API interface {
+	AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error)
+	SendAuthRevokeContext(ctx context.Context, token string) (*slack.AuthRevokeResponse, error)
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	// Schedules a message and returns its channel and scheduled_message_id
+	ScheduleMessageIDContext(ctx context.Context, channelID, postAt string, options ...slack.MsgOption) (string, string, error)
+	GetScheduledMessagesContext(ctx context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error)
+	DeleteScheduledMessageContext(ctx context.Context, params *slack.DeleteScheduledMessageParameters) (bool, error)
+	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
+	GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error)
+	GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error)
+	OpenConversationContext(ctx context.Context, params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error)
+	GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error)
+	GetUserInfoContext(ctx context.Context, user string) (*slack.User, error)
+	GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+	GetEmojiContext(ctx context.Context) (map[string]string, error)
+}
+
+// NewClientWithAPI creates a client on top of any API implementation, e.g. a
+// FakeAPI in tests
+func NewClientWithAPI(api API) *Client {
+	return &Client{
+		api:   api,
+		cache: NewCache(DefaultCacheConfig()),
+		retry: DefaultRetryPolicy,
+	}
+}
+
+var (
+	_ API = (*webAPI)(nil)
+	_ API = (*FakeAPI)(nil)
+)
+
+// webAPI is the real Slack Web API: slack-go's client, plus the calls it
+// doesn't return everything from
+type webAPI struct {
+	*slack.Client
+
+	token  string
+	apiURL string
+	http   *http.Client
+}
+
+// newWebAPI connects to the Slack API at apiURL (slack.APIURL if empty) through
+// httpClient (a plain one if nil)
+func newWebAPI(token, apiURL string, httpClient *http.Client) *webAPI {
+	if apiURL == "" {
+		apiURL = slack.APIURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &webAPI{
+		Client: slack.New(token, slack.OptionAPIURL(apiURL), slack.OptionHTTPClient(httpClient)),
+		token:  token,
+		apiURL: apiURL,
+		http:   httpClient,
+	}
+}
+
+// scheduleResponse is chat.scheduleMessage's answer; slack-go drops the
+// scheduled_message_id, which is the only handle for deleting the message later
+type scheduleResponse struct {
+	OK                 bool                   `json:"ok"`
+	Error              string                 `json:"error"`
+	Channel            string                 `json:"channel"`
+	ScheduledMessageID string                 `json:"scheduled_message_id"`
+	ResponseMetadata   slack.ResponseMetadata `json:"response_metadata"`
+}
+
+// ScheduleMessageIDContext sends chat.scheduleMessage with the same form slack-go
+// would, reporting errors as slack-go's types so retries treat them alike
+func (w *webAPI) ScheduleMessageIDContext(ctx context.Context, channelID, postAt string, options ...slack.MsgOption) (string, string, error) {
+	endpoint, values, err := slack.UnsafeApplyMsgOptions(w.token, channelID, w.apiURL,
+		slack.MsgOptionSchedule(postAt), slack.MsgOptionCompose(options...))
+	if err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := w.http.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return "", "", &slack.RateLimitedError{RetryAfter: time.Duration(retryAfter) * time.Second}
+	case resp.StatusCode != http.StatusOK:
+		return "", "", slack.StatusCodeError{Code: resp.StatusCode, Status: resp.Status}
+	}
+
+	var result scheduleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", "", err
+	}
+	if !result.OK {
+		return "", "", slack.SlackErrorResponse{Err: result.Error, ResponseMetadata: result.ResponseMetadata}
+	}
+	return result.Channel, result.ScheduledMessageID, nil
+}
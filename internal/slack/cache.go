@@ -0,0 +1,243 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API methods whose responses are cached
+const (
+	EndpointConversations = "conversations.list"
+	EndpointUsers         = "users.list"
+	EndpointScheduled     = "chat.scheduledMessages.list"
+	EndpointEmoji         = "emoji.list"
+	EndpointUserGroups    = "usergroups.list"
+)
+
+// CacheConfig controls how API responses are cached
+type CacheConfig struct {
+	// Disabled turns caching off entirely (every lookup hits the API)
+	Disabled bool
+
+	// Dir, if set, persists cached responses to disk so they survive between runs
+	Dir string
+
+	// Team and User are the IDs of the workspace and user whose token the
+	// responses were fetched with. Disk files are kept under Dir/Team/User, since
+	// another workspace or user sees other channels; without a Team nothing is
+	// read from or written to disk. Client.SetCacheConfig fills them in.
+	Team string
+	User string
+
+	// TTLs sets the maximum age per endpoint. Endpoints without an entry never
+	// expire.
+	TTLs map[string]time.Duration
+
+	// Refresh ignores responses saved on disk by earlier runs; fresh responses are
+	// still saved for the next run
+	Refresh bool
+}
+
+// DefaultMemoryTTLs keep a long-running process (serve mode) from acting on a
+// stale workspace: the scheduled message list, which other clients change too,
+// is refetched after seconds and everything else after a few minutes
+var DefaultMemoryTTLs = map[string]time.Duration{
+	EndpointConversations: 5 * time.Minute,
+	EndpointUsers:         10 * time.Minute,
+	EndpointScheduled:     10 * time.Second,
+	EndpointEmoji:         10 * time.Minute,
+	EndpointUserGroups:    10 * time.Minute,
+}
+
+// DefaultDiskTTLs are sensible freshness limits when caching to disk.
+// Scheduled messages change with every write, so they are never persisted.
+var DefaultDiskTTLs = map[string]time.Duration{
+	EndpointConversations: 24 * time.Hour,
+	EndpointUsers:         24 * time.Hour,
+	EndpointScheduled:     DefaultMemoryTTLs[EndpointScheduled],
+	EndpointEmoji:         24 * time.Hour,
+	EndpointUserGroups:    24 * time.Hour,
+}
+
+type cacheEntry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// DefaultCacheConfig caches responses in memory with DefaultMemoryTTLs
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{TTLs: copyTTLs(DefaultMemoryTTLs)}
+}
+
+// DiskCacheConfig persists responses to dir with DefaultDiskTTLs, keeping the
+// channel list for channelTTL instead when it is positive
+func DiskCacheConfig(dir string, channelTTL time.Duration) CacheConfig {
+	ttls := copyTTLs(DefaultDiskTTLs)
+	if channelTTL > 0 {
+		ttls[EndpointConversations] = channelTTL
+	}
+	return CacheConfig{Dir: dir, TTLs: ttls}
+}
+
+func copyTTLs(ttls map[string]time.Duration) map[string]time.Duration {
+	copied := make(map[string]time.Duration, len(ttls))
+	for endpoint, ttl := range ttls {
+		copied[endpoint] = ttl
+	}
+	return copied
+}
+
+// Cache stores API responses in memory and optionally on disk
+type Cache struct {
+	config  CacheConfig
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache creates a cache with the given configuration
+func NewCache(config CacheConfig) *Cache {
+	return &Cache{
+		config:  config,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get loads a fresh cached response for endpoint/key into dst, reporting whether one was found
+func (c *Cache) Get(endpoint, key string, dst interface{}) bool {
+	if c.config.Disabled {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := cacheID(endpoint, key)
+	entry, ok := c.entries[id]
+	if !ok && c.onDisk() && !c.config.Refresh {
+		entry, ok = c.readDisk(id)
+		if ok {
+			c.entries[id] = entry
+		}
+	}
+	if !ok || c.expired(endpoint, entry) {
+		return false
+	}
+
+	return json.Unmarshal(entry.Data, dst) == nil
+}
+
+// Set stores a response for endpoint/key
+func (c *Cache) Set(endpoint, key string, value interface{}) {
+	if c.config.Disabled {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := cacheID(endpoint, key)
+	entry := cacheEntry{StoredAt: time.Now(), Data: data}
+	c.entries[id] = entry
+
+	if c.onDisk() && c.persisted(endpoint) {
+		c.writeDisk(id, entry)
+	}
+}
+
+// Invalidate drops every cached response for an endpoint
+func (c *Cache) Invalidate(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prefix := cacheID(endpoint, "")
+	for id := range c.entries {
+		if strings.HasPrefix(id, prefix) {
+			delete(c.entries, id)
+		}
+	}
+
+	if c.onDisk() {
+		matches, _ := filepath.Glob(strings.TrimSuffix(c.diskPath(prefix), ".json") + "*.json")
+		for _, path := range matches {
+			os.Remove(path)
+		}
+	}
+}
+
+func (c *Cache) expired(endpoint string, entry cacheEntry) bool {
+	ttl, ok := c.config.TTLs[endpoint]
+	if !ok || ttl <= 0 {
+		return false
+	}
+	return time.Since(entry.StoredAt) > ttl
+}
+
+// persisted reports whether an endpoint's responses may be written to disk.
+// Scheduled messages never are, and an explicit zero TTL keeps others in memory
+// only too.
+func (c *Cache) persisted(endpoint string) bool {
+	ttl, ok := c.config.TTLs[endpoint]
+	return endpoint != EndpointScheduled && (!ok || ttl > 0)
+}
+
+// onDisk reports whether responses are saved to disk
+func (c *Cache) onDisk() bool {
+	return c.config.Dir != "" && c.config.Team != ""
+}
+
+func (c *Cache) readDisk(id string) (cacheEntry, bool) {
+	data, err := os.ReadFile(c.diskPath(id))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *Cache) writeDisk(id string, entry cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.diskDir(), 0700); err != nil {
+		slog.Warn(fmt.Sprintf("Warning: could not create cache directory: %v", err), "error", err)
+		return
+	}
+	if err := os.WriteFile(c.diskPath(id), data, 0600); err != nil {
+		slog.Warn(fmt.Sprintf("Warning: could not write cache file: %v", err), "error", err)
+	}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// diskDir is where this workspace and user's responses are saved
+func (c *Cache) diskDir() string {
+	dir := filepath.Join(c.config.Dir, unsafeFileChars.ReplaceAllString(c.config.Team, "_"))
+	if c.config.User != "" {
+		dir = filepath.Join(dir, unsafeFileChars.ReplaceAllString(c.config.User, "_"))
+	}
+	return dir
+}
+
+func (c *Cache) diskPath(id string) string {
+	return filepath.Join(c.diskDir(), unsafeFileChars.ReplaceAllString(id, "_")+".json")
+}
+
+func cacheID(endpoint, key string) string {
+	return endpoint + "#" + key
+}
@@ -0,0 +1,173 @@
+package slack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_MemoryRoundTrip(t *testing.T) {
+	cache := NewCache(CacheConfig{})
+	cache.Set(EndpointConversations, "", []string{"general", "random"})
+
+	var got []string
+	if !cache.Get(EndpointConversations, "", &got) {
+		t.Fatal("Get() = false, want cached value")
+	}
+	if len(got) != 2 || got[0] != "general" {
+		t.Errorf("Get() = %v, want [general random]", got)
+	}
+
+	if cache.Get(EndpointUsers, "", &got) {
+		t.Error("Get() for uncached endpoint = true, want false")
+	}
+}
+
+func TestCache_Disabled(t *testing.T) {
+	cache := NewCache(CacheConfig{Disabled: true})
+	cache.Set(EndpointConversations, "", []string{"general"})
+
+	var got []string
+	if cache.Get(EndpointConversations, "", &got) {
+		t.Error("Get() on disabled cache = true, want false")
+	}
+}
+
+func TestCache_TTLExpiry(t *testing.T) {
+	cache := NewCache(CacheConfig{TTLs: map[string]time.Duration{EndpointUsers: time.Minute}})
+	cache.Set(EndpointUsers, "", []string{"alice"})
+
+	// Age the entry past its TTL
+	id := cacheID(EndpointUsers, "")
+	entry := cache.entries[id]
+	entry.StoredAt = time.Now().Add(-2 * time.Minute)
+	cache.entries[id] = entry
+
+	var got []string
+	if cache.Get(EndpointUsers, "", &got) {
+		t.Error("Get() on expired entry = true, want false")
+	}
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	cache := NewCache(CacheConfig{})
+	cache.Set(EndpointScheduled, "", []string{"a"})
+	cache.Set(EndpointScheduled, "C123", []string{"b"})
+	cache.Set(EndpointConversations, "", []string{"c"})
+
+	cache.Invalidate(EndpointScheduled)
+
+	var got []string
+	if cache.Get(EndpointScheduled, "", &got) || cache.Get(EndpointScheduled, "C123", &got) {
+		t.Error("scheduled entries should be invalidated")
+	}
+	if !cache.Get(EndpointConversations, "", &got) {
+		t.Error("other endpoints should be left alone")
+	}
+}
+
+func TestCache_Disk(t *testing.T) {
+	dir := t.TempDir()
+	config := CacheConfig{Dir: dir, Team: "T123", User: "U123", TTLs: DefaultDiskTTLs}
+
+	first := NewCache(config)
+	first.Set(EndpointConversations, "", []string{"general"})
+	first.Set(EndpointScheduled, "", []string{"never persisted"})
+
+	// A new cache (i.e. a later run) picks the entry up from disk
+	second := NewCache(config)
+	var got []string
+	if !second.Get(EndpointConversations, "", &got) || len(got) != 1 {
+		t.Errorf("Get() from disk = %v, want [general]", got)
+	}
+	if second.Get(EndpointScheduled, "", &got) {
+		t.Error("scheduled messages should not be persisted to disk")
+	}
+
+	// Another workspace or user has its own
+	for _, other := range []CacheConfig{
+		{Dir: dir, Team: "T456", User: "U123", TTLs: DefaultDiskTTLs},
+		{Dir: dir, Team: "T123", User: "U456", TTLs: DefaultDiskTTLs},
+	} {
+		if NewCache(other).Get(EndpointConversations, "", &got) {
+			t.Errorf("Get() for %s/%s served another user's response", other.Team, other.User)
+		}
+	}
+
+	second.Invalidate(EndpointConversations)
+	files, _ := filepath.Glob(filepath.Join(dir, "T123", "U123", "*.json"))
+	if len(files) != 0 {
+		t.Errorf("expected cache files to be removed, found %v", files)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("cache dir should still exist: %v", err)
+	}
+}
+
+func TestCache_DiskRefresh(t *testing.T) {
+	dir := t.TempDir()
+	config := DiskCacheConfig(dir, time.Hour)
+	config.Team = "T123"
+	if config.TTLs[EndpointConversations] != time.Hour || config.TTLs[EndpointUsers] != DefaultDiskTTLs[EndpointUsers] {
+		t.Errorf("DiskCacheConfig() TTLs = %v", config.TTLs)
+	}
+	if DefaultDiskTTLs[EndpointConversations] == time.Hour {
+		t.Error("DiskCacheConfig() should not modify DefaultDiskTTLs")
+	}
+
+	NewCache(config).Set(EndpointConversations, "", []string{"general"})
+
+	// A refreshing run ignores what earlier runs saved...
+	config.Refresh = true
+	refreshing := NewCache(config)
+	var got []string
+	if refreshing.Get(EndpointConversations, "", &got) {
+		t.Error("Get() with Refresh = true served a saved response")
+	}
+
+	// ...but saves what it fetches for the next one
+	refreshing.Set(EndpointConversations, "", []string{"general", "random"})
+	if !refreshing.Get(EndpointConversations, "", &got) || len(got) != 2 {
+		t.Errorf("Get() after Set = %v, want the fresh response", got)
+	}
+	config.Refresh = false
+	if !NewCache(config).Get(EndpointConversations, "", &got) || len(got) != 2 {
+		t.Errorf("Get() on the next run = %v, want the refreshed response", got)
+	}
+}
+
+func TestCache_DiskNeedsTeam(t *testing.T) {
+	dir := t.TempDir()
+	NewCache(CacheConfig{Dir: dir}).Set(EndpointConversations, "", []string{"general"})
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("cache without a team wrote %v", files)
+	}
+}
+
+func TestClient_SetCacheConfig_KeysDiskByTeam(t *testing.T) {
+	dir := t.TempDir()
+	api := NewFakeAPI()
+	api.AddChannel("general")
+	client := NewFakeClient(api)
+	client.SetCacheConfig(DiskCacheConfig(dir, 0))
+
+	if _, err := client.GetChannelID("general"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "T0FAKE", api.SelfID, "conversations.list_.json")); err != nil {
+		t.Errorf("channel list not cached under the team and user: %v", err)
+	}
+}
+
+func TestDefaultCacheConfig(t *testing.T) {
+	config := DefaultCacheConfig()
+	if ttl := config.TTLs[EndpointScheduled]; ttl <= 0 || ttl > time.Minute {
+		t.Errorf("scheduled message TTL = %s, want a few seconds", ttl)
+	}
+	for endpoint, ttl := range config.TTLs {
+		if ttl <= 0 {
+			t.Errorf("%s never expires", endpoint)
+		}
+	}
+}
@@ -1,123 +1,428 @@
 package slack
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
 	"github.com/slack-go/slack"
 )
 
-// Client wraps the Slack API client
+// Client wraps the Slack API client.
+// Channel, user and scheduled message lists are cached (by default in memory for
+// the lifetime of the client, so a single command invocation only fetches them once).
 type Client struct {
-	api *slack.Client
+	api   API
+	cache *Cache
+
+	// Cancels API calls and retry waits (context.Background if nil)
+	ctx context.Context
+
+	timings *Timings
+	metrics *Metrics
+
+	retry RetryPolicy
+
+	// Replaces retry waits in tests
+	sleep func(time.Duration)
+
+	audit        AuditRecorder
+	auditCommand string
+}
+
+// AuditRecorder receives a record of every change the client makes in Slack
+type AuditRecorder interface {
+	Record(entry types.AuditEntry) error
 }
 
 // NewClient creates a new Slack client with the given token
 func NewClient(token string) *Client {
+	return newClient(token, "", nil)
+}
+
+// NewClientWithAPIURL creates a Slack client that talks to a different API base URL,
+// e.g. a local mock server for benchmarks
+func NewClientWithAPIURL(token, apiURL string) *Client {
+	return newClient(token, apiURL, nil)
+}
+
+// newClient creates a client for the API at apiURL (Slack's if empty), sending
+// requests through httpClient (a plain one if nil)
+func newClient(token, apiURL string, httpClient *http.Client) *Client {
 	return &Client{
-		api: slack.New(token),
+		api:   newWebAPI(token, apiURL, httpClient),
+		cache: NewCache(DefaultCacheConfig()),
+		retry: DefaultRetryPolicy,
+	}
+}
+
+// SetCacheConfig replaces the response cache, e.g. to disable it or persist it
+// to disk. A disk cache without a Team is keyed by the token's team and user,
+// looked up with auth.test; if that fails, responses are only cached in memory.
+func (c *Client) SetCacheConfig(config CacheConfig) {
+	if config.Dir != "" && config.Team == "" && !config.Disabled {
+		if id, err := c.Identity(); err != nil {
+			slog.Warn(fmt.Sprintf("Warning: not caching to disk, since the token's workspace is unknown: %v", err), "error", err)
+		} else {
+			config.Team, config.User = id.TeamID, id.UserID
+		}
+	}
+	c.cache = NewCache(config)
+}
+
+// WithContext returns a client whose API calls are cancelled with ctx, e.g. on
+// Ctrl-C or when a --timeout expires. It shares the original's cache, timings,
+// metrics and audit log.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// EnableTimings starts recording per-method API latency and returns the recorder
+func (c *Client) EnableTimings() *Timings {
+	c.timings = NewTimings()
+	return c.timings
+}
+
+// EnableAudit records every message scheduled, deleted or sent from now on,
+// attributed to command
+func (c *Client) EnableAudit(recorder AuditRecorder, command string) {
+	c.audit = recorder
+	c.auditCommand = command
+}
+
+// record adds an entry to the audit log, if enabled. A failed write is only a
+// warning: the change has already happened in Slack.
+func (c *Client) record(entry types.AuditEntry) {
+	if c.metrics != nil {
+		c.metrics.changed(entry.Action)
+	}
+	if c.audit == nil {
+		return
+	}
+	entry.Time = time.Now()
+	entry.Command = c.auditCommand
+	if err := c.audit.Record(entry); err != nil {
+		slog.Warn(fmt.Sprintf("Warning: failed to write audit log: %v", err), "error", err)
+	}
+}
+
+// track records the time elapsed since start for an API method, if timings are enabled
+func (c *Client) track(method string, start time.Time) {
+	if c.timings != nil {
+		c.timings.Record(method, time.Since(start))
 	}
 }
 
-// SendMessage sends a message to the specified channel
-func (c *Client) SendMessage(channel, message string) error {
-	_, _, err := c.api.PostMessage(
-		channel,
-		slack.MsgOptionText(message, false), // false = parse markdown/mentions
-		slack.MsgOptionAsUser(true),         // Send as the authenticated user
-	)
+// SendMessage sends a message to the specified channel and returns its timestamp
+func (c *Client) SendMessage(channel, message string, opts ...MessageOption) (string, error) {
+	var ts string
+	err := c.call("chat.postMessage", func() (err error) {
+		_, ts, err = c.api.PostMessageContext(c.context(), channel, msgOptions(message, opts)...)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return "", fmt.Errorf("failed to send message: %w", err)
 	}
-	return nil
+	c.record(types.AuditEntry{Action: "send", Channel: channel, Message: message})
+	return ts, nil
+}
+
+// MessageOption customizes a scheduled or sent message
+type MessageOption struct {
+	apply slack.MsgOption
+
+	// Posts under a custom name or icon, which Slack ignores for as_user messages
+	persona bool
+}
+
+// msgOptions builds the Slack options for a message: its text (parsed for
+// markdown and mentions), the given options and, unless one sets a persona,
+// posting as the authenticated user
+func msgOptions(message string, opts []MessageOption) []slack.MsgOption {
+	options := []slack.MsgOption{slack.MsgOptionText(message, false)}
+	asUser := true
+	for _, opt := range opts {
+		options = append(options, opt.apply)
+		if opt.persona {
+			asUser = false
+		}
+	}
+	if asUser {
+		options = append(options, slack.MsgOptionAsUser(true))
+	}
+	return options
+}
+
+// WithMetadata attaches Slack message metadata to a message
+func WithMetadata(meta *types.MessageMetadata) MessageOption {
+	return MessageOption{apply: slack.MsgOptionMetadata(slack.SlackMetadata{
+		EventType:    meta.EventType,
+		EventPayload: meta.EventPayload,
+	})}
+}
+
+// Verbatim posts a message exactly as written: no mrkdwn formatting and no
+// link, mention or channel parsing
+func Verbatim() MessageOption {
+	return MessageOption{apply: slack.MsgOptionCompose(slack.MsgOptionDisableMarkdown(), slack.MsgOptionParse(false))}
 }
 
-// ScheduleMessage schedules a message to be sent at a specific time
-func (c *Client) ScheduleMessage(channel, message string, postAt time.Time) (string, error) {
+// WithPersona posts a message under a custom username and/or icon emoji instead
+// of as the authenticated user. Requires a bot token with chat:write.customize.
+func WithPersona(username, iconEmoji string) MessageOption {
+	var options []slack.MsgOption
+	if username != "" {
+		options = append(options, slack.MsgOptionUsername(username))
+	}
+	if iconEmoji != "" {
+		options = append(options, slack.MsgOptionIconEmoji(":"+strings.Trim(iconEmoji, ":")+":"))
+	}
+	return MessageOption{apply: slack.MsgOptionCompose(options...), persona: true}
+}
+
+// WithSendOptions returns the message options opts records
+func WithSendOptions(opts types.SendOptions) []MessageOption {
+	var options []MessageOption
+	if opts.Raw {
+		options = append(options, Verbatim())
+	}
+	if opts.Username != "" || opts.IconEmoji != "" {
+		options = append(options, WithPersona(opts.Username, opts.IconEmoji))
+	}
+	if opts.ThreadTS != "" {
+		options = append(options, InThread(opts.ThreadTS, opts.AlsoToChannel))
+	}
+	if opts.Metadata != nil {
+		options = append(options, WithMetadata(opts.Metadata))
+	}
+	return options
+}
+
+// ScheduleMessage schedules a message to be sent at a specific time and returns
+// its scheduled_message_id, which deleting it later takes
+func (c *Client) ScheduleMessage(channel, message string, postAt time.Time, opts ...MessageOption) (string, error) {
 	// Slack API expects Unix timestamp as string (UTC)
 	// Convert local time to UTC for the API call
 	postAtUTC := postAt.UTC()
 	postAtUnix := postAtUTC.Unix()
 
-	respChannel, scheduledTime, err := c.api.ScheduleMessage(
-		channel,
-		fmt.Sprintf("%d", postAtUnix),
-		slack.MsgOptionText(message, false),
-		slack.MsgOptionAsUser(true),
-	)
+	var respChannel, id string
+	var lastErr error
+	attempts, err := c.callAttempts("chat.scheduleMessage", func() (err error) {
+		// A timeout or 5xx doesn't say whether Slack scheduled the message, so
+		// look for it before trying again rather than schedule it twice
+		if lastErr != nil && isTransient(lastErr) {
+			existing, found, listErr := c.findScheduled(channel, postAtUnix, message)
+			if listErr != nil {
+				return fmt.Errorf("%v (not retried, since checking whether it was scheduled anyway failed: %v)", lastErr, listErr)
+			}
+			if found {
+				respChannel, id = channel, existing
+				return nil
+			}
+		}
+		respChannel, id, err = c.api.ScheduleMessageIDContext(c.context(),
+			channel,
+			fmt.Sprintf("%d", postAtUnix),
+			msgOptions(message, opts)...,
+		)
+		lastErr = err
+		return err
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to schedule message: %w", err)
 	}
+	c.invalidateScheduled()
+	if id == "" {
+		return "", fmt.Errorf("failed to schedule message: slack returned no scheduled_message_id")
+	}
 
 	// Log the scheduling result
-	fmt.Printf("Scheduled message for: %s (UTC: %s) in channel: %s\n",
+	slog.Info(fmt.Sprintf("Scheduled message for: %s (UTC: %s) in channel: %s",
 		postAt.Format("2006-01-02 15:04 MST"),
 		postAtUTC.Format("2006-01-02 15:04 UTC"),
-		respChannel)
+		respChannel),
+		"channel", respChannel, "post_at", postAtUTC, "attempts", attempts, "scheduled_message_id", id)
 
-	if scheduledTime != "" {
-		fmt.Printf("Scheduled message timestamp: %s\n", scheduledTime)
+	if attempts > 1 {
+		slog.Info(fmt.Sprintf("  (needed %d attempts)", attempts), "attempts", attempts)
 	}
 
-	// Return the scheduled timestamp (or postAt timestamp if empty) as identifier
-	if scheduledTime != "" {
-		return scheduledTime, nil
+	c.record(types.AuditEntry{Action: "schedule", Channel: channel, SlackID: id, PostAt: postAt, Message: message})
+	return id, nil
+}
+
+// findScheduled looks for a message scheduled in the channel for postAt with
+// this text, bypassing the cache, and returns its ID
+func (c *Client) findScheduled(channelID string, postAt int64, text string) (string, bool, error) {
+	c.invalidateScheduled()
+	messages, err := c.ListScheduledMessages(channelID)
+	if err != nil {
+		return "", false, err
 	}
-	// Return the postAt timestamp as a fallback identifier
-	return fmt.Sprintf("%d", postAtUnix), nil
+	for _, msg := range messages {
+		if int64(msg.PostAt) == postAt && msg.Text == text {
+			return msg.ID, true, nil
+		}
+	}
+	return "", false, nil
 }
 
 // ListScheduledMessages lists all scheduled messages, optionally filtered by channel
 func (c *Client) ListScheduledMessages(channelID string) ([]slack.ScheduledMessage, error) {
+	return c.ListScheduledMessagesLimit(channelID, 0)
+}
+
+// ListScheduledMessagesLimit lists scheduled messages like ListScheduledMessages,
+// stopping once limit messages have been fetched (no limit when zero). A limited
+// list is not cached, since it may be incomplete.
+func (c *Client) ListScheduledMessagesLimit(channelID string, limit int) ([]slack.ScheduledMessage, error) {
+	if messages, ok := c.cachedScheduled(channelID); ok {
+		if limit > 0 && len(messages) > limit {
+			messages = messages[:limit]
+		}
+		return messages, nil
+	}
+
+	var messages []slack.ScheduledMessage
 	params := &slack.GetScheduledMessagesParameters{
-		Limit: 100,
+		Channel: channelID,
 	}
-	if channelID != "" {
-		params.Channel = channelID
+	for {
+		params.Limit = scheduledPageSize
+		if remaining := limit - len(messages); limit > 0 && remaining < params.Limit {
+			params.Limit = remaining
+		}
+
+		var page []slack.ScheduledMessage
+		var next string
+		err := c.call("chat.scheduledMessages.list", func() (err error) {
+			page, next, err = c.api.GetScheduledMessagesContext(c.context(), params)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list scheduled messages: %w", err)
+		}
+
+		messages = append(messages, page...)
+		if next == "" {
+			break
+		}
+		if limit > 0 && len(messages) >= limit {
+			return messages[:limit], nil
+		}
+		params.Cursor = next
 	}
 
-	messages, _, err := c.api.GetScheduledMessages(params)
+	if limit > 0 && len(messages) > limit {
+		return messages[:limit], nil
+	}
+	c.cache.Set(EndpointScheduled, channelID, messages)
+	return messages, nil
+}
+
+// scheduledPageSize is how many scheduled messages are requested per page
+const scheduledPageSize = 100
+
+// FindStaleScheduledMessages lists scheduled messages whose post time has already passed.
+// These are leftovers that Slack failed to post or clean up and can be safely deleted.
+func (c *Client) FindStaleScheduledMessages(channelID string) ([]slack.ScheduledMessage, error) {
+	messages, err := c.ListScheduledMessages(channelID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list scheduled messages: %w", err)
+		return nil, err
 	}
+	return StaleScheduledMessages(messages, time.Now()), nil
+}
 
-	return messages, nil
+// StaleScheduledMessages filters messages down to those with a post time at or before now
+func StaleScheduledMessages(messages []slack.ScheduledMessage, now time.Time) []slack.ScheduledMessage {
+	var stale []slack.ScheduledMessage
+	for _, msg := range messages {
+		if !time.Unix(int64(msg.PostAt), 0).After(now) {
+			stale = append(stale, msg)
+		}
+	}
+	return stale
 }
 
 // DeleteScheduledMessage deletes a scheduled message by its ID
 func (c *Client) DeleteScheduledMessage(channelID, scheduledMsgID string) error {
-	_, err := c.api.DeleteScheduledMessage(&slack.DeleteScheduledMessageParameters{
-		Channel:            channelID,
-		ScheduledMessageID: scheduledMsgID,
-		AsUser:             true,
+	err := c.call("chat.deleteScheduledMessage", func() error {
+		_, err := c.api.DeleteScheduledMessageContext(c.context(), &slack.DeleteScheduledMessageParameters{
+			Channel:            channelID,
+			ScheduledMessageID: scheduledMsgID,
+			AsUser:             true,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete scheduled message: %w", err)
 	}
+	c.invalidateScheduled()
+	c.record(types.AuditEntry{Action: "delete", Channel: channelID, SlackID: scheduledMsgID})
 	return nil
 }
 
 // ValidateCredentials checks if the token is valid by testing auth
 func (c *Client) ValidateCredentials() error {
-	resp, err := c.api.AuthTest()
+	var resp *slack.AuthTestResponse
+	err := c.call("auth.test", func() (err error) {
+		resp, err = c.api.AuthTestContext(c.context())
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("invalid credentials: %w", err)
 	}
 
-	// Print auth info for debugging
-	fmt.Printf("  Authenticated as: %s\n", resp.User)
-	fmt.Printf("  Team: %s\n", resp.Team)
+	// Log auth info for debugging
+	slog.Info(fmt.Sprintf("  Authenticated as: %s", resp.User), "user", resp.User, "team", resp.Team)
+	slog.Info(fmt.Sprintf("  Team: %s", resp.Team))
 	if resp.BotID != "" {
-		fmt.Printf("  ⚠️  WARNING: This is a BOT token (Bot ID: %s)\n", resp.BotID)
-		fmt.Printf("     Scheduled messages from bot tokens WON'T appear in your Slack UI!\n")
-		fmt.Printf("     Use a User OAuth Token (xoxp-...) instead of a Bot Token (xoxb-...)\n")
+		slog.Warn(fmt.Sprintf("WARNING: This is a BOT token (Bot ID: %s)\n"+
+			"     Scheduled messages from bot tokens WON'T appear in your Slack UI!\n"+
+			"     Use a User OAuth Token (xoxp-...) instead of a Bot Token (xoxb-...)", resp.BotID),
+			"bot_id", resp.BotID)
 	} else {
-		fmt.Printf("  Token type: User token ✓\n")
+		slog.Info("  Token type: User token ✓")
 	}
 
 	return nil
 }
 
+// GetSelfID returns the user ID the token belongs to (auth.test)
+func (c *Client) GetSelfID() (string, error) {
+	var id string
+	if c.cache.Get(EndpointUsers, "self", &id) {
+		return id, nil
+	}
+
+	var resp *slack.AuthTestResponse
+	err := c.call("auth.test", func() (err error) {
+		resp, err = c.api.AuthTestContext(c.context())
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to identify the token's user: %w", err)
+	}
+
+	c.cache.Set(EndpointUsers, "self", resp.UserID)
+	return resp.UserID, nil
+}
+
 // GetChannelID resolves a channel name to its ID
 func (c *Client) GetChannelID(channelName string) (string, error) {
 	// If it already looks like an ID, return it
@@ -125,18 +430,23 @@ func (c *Client) GetChannelID(channelName string) (string, error) {
 		return channelName, nil
 	}
 
+	// Users are reached through the DM with them
+	if IsUserTarget(channelName) {
+		userID, err := c.GetUserID(channelName)
+		if err != nil {
+			return "", err
+		}
+		return c.OpenDM(userID)
+	}
+
 	// Remove # prefix if present
 	if len(channelName) > 0 && channelName[0] == '#' {
 		channelName = channelName[1:]
 	}
 
-	// List channels to find the ID
-	channels, _, err := c.api.GetConversations(&slack.GetConversationsParameters{
-		Types: []string{"public_channel", "private_channel"},
-		Limit: 1000,
-	})
+	channels, err := c.listChannels()
 	if err != nil {
-		return "", fmt.Errorf("failed to list channels: %w", err)
+		return "", err
 	}
 
 	for _, ch := range channels {
@@ -145,18 +455,109 @@ func (c *Client) GetChannelID(channelName string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("channel not found: %s", channelName)
+	return "", channelNotFoundError{fmt.Sprintf("channel not found: %s", channelName)}
+}
+
+// OpenDM returns the ID of the direct message channel with a user, opening it
+// with conversations.open if needed (requires the im:write scope)
+func (c *Client) OpenDM(userID string) (string, error) {
+	var id string
+	if c.cache.Get(EndpointConversations, "im-"+userID, &id) {
+		return id, nil
+	}
+
+	var channel *slack.Channel
+	err := c.call("conversations.open", func() (err error) {
+		channel, _, _, err = c.api.OpenConversationContext(c.context(), &slack.OpenConversationParameters{Users: []string{userID}})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open DM with %s: %w", userID, err)
+	}
+	if channel == nil || channel.ID == "" {
+		return "", fmt.Errorf("failed to open DM with %s: no channel returned", userID)
+	}
+
+	c.cache.Set(EndpointConversations, "im-"+userID, channel.ID)
+	return channel.ID, nil
+}
+
+// ChannelStatus is what matters about a channel before posting to it
+type ChannelStatus struct {
+	Name     string
+	Archived bool
+	Private  bool
+
+	// Whether the token's user (or bot) is in the channel
+	Member bool
+}
+
+// GetChannelStatus looks a channel up in the cached channel list, falling back to
+// conversations.info for channels the list doesn't include
+func (c *Client) GetChannelStatus(channelID string) (ChannelStatus, error) {
+	channels, err := c.listChannels()
+	if err != nil {
+		return ChannelStatus{}, err
+	}
+	for _, ch := range channels {
+		if ch.ID == channelID {
+			return channelStatus(ch), nil
+		}
+	}
+
+	var ch *slack.Channel
+	err = c.call("conversations.info", func() (err error) {
+		ch, err = c.api.GetConversationInfoContext(c.context(), &slack.GetConversationInfoInput{ChannelID: channelID})
+		return err
+	})
+	if err != nil && strings.Contains(err.Error(), "channel_not_found") {
+		return ChannelStatus{}, channelNotFoundError{fmt.Sprintf("channel %s not found or not visible to this token (private channels need an /invite first)", channelID)}
+	}
+	if err != nil {
+		return ChannelStatus{}, fmt.Errorf("failed to get channel info for %s: %w", channelID, err)
+	}
+	return channelStatus(*ch), nil
+}
+
+// IsChannelMember reports whether a user is in a channel, per
+// conversations.members. It isn't cached, since it guards what the user may do.
+func (c *Client) IsChannelMember(channelID, userID string) (bool, error) {
+	cursor := ""
+	for {
+		var members []string
+		var next string
+		err := c.call("conversations.members", func() (err error) {
+			members, next, err = c.api.GetUsersInConversationContext(c.context(), &slack.GetUsersInConversationParameters{
+				ChannelID: channelID,
+				Limit:     1000,
+				Cursor:    cursor,
+			})
+			return err
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to list members of %s: %w", channelID, err)
+		}
+		for _, id := range members {
+			if id == userID {
+				return true, nil
+			}
+		}
+		if next == "" {
+			return false, nil
+		}
+		cursor = next
+	}
+}
+
+func channelStatus(ch slack.Channel) ChannelStatus {
+	return ChannelStatus{Name: ch.Name, Archived: ch.IsArchived, Private: ch.IsPrivate, Member: ch.IsMember}
 }
 
 // GetChannelName resolves a channel ID to its human-readable name
 func (c *Client) GetChannelName(channelID string) (string, error) {
-	// List channels to find the name
-	channels, _, err := c.api.GetConversations(&slack.GetConversationsParameters{
-		Types: []string{"public_channel", "private_channel"},
-		Limit: 1000,
-	})
+	channels, err := c.listChannels()
 	if err != nil {
-		return "", fmt.Errorf("failed to list channels: %w", err)
+		return "", err
 	}
 
 	for _, ch := range channels {
@@ -171,12 +572,9 @@ func (c *Client) GetChannelName(channelID string) (string, error) {
 
 // GetChannelNameMap returns a map of channel IDs to names
 func (c *Client) GetChannelNameMap() (map[string]string, error) {
-	channels, _, err := c.api.GetConversations(&slack.GetConversationsParameters{
-		Types: []string{"public_channel", "private_channel"},
-		Limit: 1000,
-	})
+	channels, err := c.listChannels()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list channels: %w", err)
+		return nil, err
 	}
 
 	nameMap := make(map[string]string)
@@ -186,7 +584,323 @@ func (c *Client) GetChannelNameMap() (map[string]string, error) {
 	return nameMap, nil
 }
 
-// API returns the underlying slack.Client for advanced usage
-func (c *Client) API() *slack.Client {
+// listChannels returns public and private channels, served from cache when possible
+func (c *Client) listChannels() ([]slack.Channel, error) {
+	var cached []slack.Channel
+	if c.cache.Get(EndpointConversations, "", &cached) {
+		return cached, nil
+	}
+
+	var channels []slack.Channel
+	cursor := ""
+	for {
+		var page []slack.Channel
+		var next string
+		err := c.call("conversations.list", func() (err error) {
+			page, next, err = c.api.GetConversationsContext(c.context(), &slack.GetConversationsParameters{
+				Types:  []string{"public_channel", "private_channel"},
+				Limit:  1000,
+				Cursor: cursor,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list channels: %w", err)
+		}
+
+		channels = append(channels, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	c.cache.Set(EndpointConversations, "", channels)
+	return channels, nil
+}
+
+// invalidateScheduled drops cached scheduled message lists after a write
+func (c *Client) invalidateScheduled() {
+	c.cache.Invalidate(EndpointScheduled)
+}
+
+// cachedScheduled returns a cached scheduled message list for the channel, if any
+func (c *Client) cachedScheduled(channelID string) ([]slack.ScheduledMessage, bool) {
+	var messages []slack.ScheduledMessage
+	if c.cache.Get(EndpointScheduled, channelID, &messages) {
+		return messages, true
+	}
+	// A cached unfiltered list already contains this channel's messages
+	var all []slack.ScheduledMessage
+	if channelID != "" && c.cache.Get(EndpointScheduled, "", &all) {
+		for _, msg := range all {
+			if msg.Channel == channelID {
+				messages = append(messages, msg)
+			}
+		}
+		return messages, true
+	}
+	return nil, false
+}
+
+// listUsers returns all workspace users, served from cache when possible
+func (c *Client) listUsers() ([]slack.User, error) {
+	var cached []slack.User
+	if c.cache.Get(EndpointUsers, "", &cached) {
+		return cached, nil
+	}
+
+	var users []slack.User
+	err := c.call("users.list", func() (err error) {
+		users, err = c.api.GetUsersContext(c.context())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	c.cache.Set(EndpointUsers, "", users)
+	return users, nil
+}
+
+// IsUserTarget reports whether a channel argument names a user (@name or a user ID)
+// rather than a conversation
+func IsUserTarget(target string) bool {
+	return strings.HasPrefix(target, "@") || isUserID(target)
+}
+
+func isUserID(s string) bool {
+	return len(s) > 1 && (s[0] == 'U' || s[0] == 'W') && strings.ToUpper(s) == s
+}
+
+// GetUserID resolves a user ID or @username (or display name or email) to a user ID
+func (c *Client) GetUserID(name string) (string, error) {
+	if isUserID(name) {
+		return name, nil
+	}
+	name = strings.TrimPrefix(name, "@")
+
+	users, err := c.listUsers()
+	if err != nil {
+		return "", err
+	}
+
+	for _, u := range users {
+		if u.Name == name || u.Profile.DisplayName == name || (u.Profile.Email != "" && strings.EqualFold(u.Profile.Email, name)) {
+			return u.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("user not found: %s", name)
+}
+
+// FindUser resolves a username, display name or email (case-insensitive) to a
+// user ID, failing unless exactly one active user matches. Emails are only
+// visible with the users:read.email scope.
+func (c *Client) FindUser(query string) (string, error) {
+	query = strings.TrimPrefix(query, "@")
+	users, err := c.listUsers()
+	if err != nil {
+		return "", err
+	}
+
+	var ids []string
+	for _, u := range users {
+		if u.Deleted {
+			continue
+		}
+		if strings.EqualFold(u.Name, query) || strings.EqualFold(u.Profile.DisplayName, query) || strings.EqualFold(u.Profile.Email, query) {
+			ids = append(ids, u.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("user not found: @%s", query)
+	case 1:
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("@%s matches %d users (%s); use their username or email", query, len(ids), strings.Join(ids, ", "))
+}
+
+// listUserGroups returns the workspace's enabled user groups, served from cache when possible
+func (c *Client) listUserGroups() ([]slack.UserGroup, error) {
+	var cached []slack.UserGroup
+	if c.cache.Get(EndpointUserGroups, "", &cached) {
+		return cached, nil
+	}
+
+	var groups []slack.UserGroup
+	err := c.call(EndpointUserGroups, func() (err error) {
+		groups, err = c.api.GetUserGroupsContext(c.context())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user groups: %w", err)
+	}
+
+	c.cache.Set(EndpointUserGroups, "", groups)
+	return groups, nil
+}
+
+// GetUserGroupMap returns a map of user group IDs to handles
+func (c *Client) GetUserGroupMap() (map[string]string, error) {
+	groups, err := c.listUserGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	handleMap := make(map[string]string)
+	for _, g := range groups {
+		handleMap[g.ID] = g.Handle
+	}
+	return handleMap, nil
+}
+
+// FindUserGroup resolves a user group @handle (case-insensitive) to its subteam ID.
+// Listing user groups requires the usergroups:read scope.
+func (c *Client) FindUserGroup(handle string) (string, error) {
+	handle = strings.TrimPrefix(handle, "@")
+	groups, err := c.listUserGroups()
+	if err != nil {
+		return "", err
+	}
+
+	for _, g := range groups {
+		if strings.EqualFold(g.Handle, handle) {
+			return g.ID, nil
+		}
+	}
+	return "", fmt.Errorf("user group not found: @%s", handle)
+}
+
+// GetUserTimezone returns the time zone set in a user's Slack profile (users.info tz)
+func (c *Client) GetUserTimezone(userID string) (*time.Location, error) {
+	var tz string
+	if !c.cache.Get(EndpointUsers, "tz-"+userID, &tz) {
+		var user *slack.User
+		err := c.call("users.info", func() (err error) {
+			user, err = c.api.GetUserInfoContext(c.context(), userID)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user info for %s: %w", userID, err)
+		}
+		tz = user.TZ
+		c.cache.Set(EndpointUsers, "tz-"+userID, tz)
+	}
+
+	if tz == "" {
+		return nil, fmt.Errorf("user %s has no time zone set", userID)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %s for user %s: %w", tz, userID, err)
+	}
+	return loc, nil
+}
+
+// API returns the underlying API for advanced usage
+func (c *Client) API() API {
 	return c.api
 }
+
+// ToOccurrences converts Slack scheduled messages into occurrences
+func ToOccurrences(messages []slack.ScheduledMessage) []types.Occurrence {
+	occurrences := make([]types.Occurrence, 0, len(messages))
+	for _, msg := range messages {
+		occurrences = append(occurrences, types.Occurrence{
+			Channel:     msg.Channel,
+			Message:     msg.Text,
+			PostAt:      time.Unix(int64(msg.PostAt), 0),
+			ScheduledID: msg.ID,
+		})
+	}
+	return occurrences
+}
+
+// GetUserNameMap returns a map of user IDs to display names,
+// falling back to the username when no display name is set
+func (c *Client) GetUserNameMap() (map[string]string, error) {
+	users, err := c.listUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	nameMap := make(map[string]string)
+	for _, u := range users {
+		name := u.Profile.DisplayName
+		if name == "" {
+			name = u.Name
+		}
+		nameMap[u.ID] = name
+	}
+	return nameMap, nil
+}
+
+// GetCustomEmoji returns the workspace's custom emoji, mapping name to image URL or alias
+func (c *Client) GetCustomEmoji() (map[string]string, error) {
+	var cached map[string]string
+	if c.cache.Get(EndpointEmoji, "", &cached) {
+		return cached, nil
+	}
+
+	var emoji map[string]string
+	err := c.call(EndpointEmoji, func() (err error) {
+		emoji, err = c.api.GetEmojiContext(c.context())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom emoji: %w", err)
+	}
+
+	c.cache.Set(EndpointEmoji, "", emoji)
+	return emoji, nil
+}
+
+// Prefetch loads the channel list, user list and scheduled messages concurrently
+// so that later lookups on this client are served from cache
+func (c *Client) Prefetch() error {
+	fetches := []func() error{
+		func() error { _, err := c.listChannels(); return err },
+		func() error { _, err := c.listUsers(); return err },
+		func() error { _, err := c.ListScheduledMessages(""); return err },
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(fetches))
+	for i, fetch := range fetches {
+		wg.Add(1)
+		go func(i int, fetch func() error) {
+			defer wg.Done()
+			errs[i] = fetch()
+		}(i, fetch)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TakeSnapshot captures the channel map and scheduled messages for offline use
+func (c *Client) TakeSnapshot() (*types.Snapshot, error) {
+	channels, err := c.GetChannelNameMap()
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := c.ListScheduledMessages("")
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Snapshot{
+		TakenAt:   time.Now(),
+		Channels:  channels,
+		Scheduled: ToOccurrences(messages),
+	}, nil
+}
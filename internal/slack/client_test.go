@@ -1,7 +1,16 @@
 package slack
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+	"github.com/slack-go/slack"
 )
 
 func TestNewClient(t *testing.T) {
@@ -46,37 +55,128 @@ func TestGetChannelID_AlreadyID(t *testing.T) {
 	}
 }
 
-// TestGetChannelID_ChannelNameResolution documents behavior that requires API calls.
-// These tests would need a mock Slack API interface to test properly.
-//
-// Note: There's a subtle behavior in GetChannelID where the ID check happens
-// before the hash stripping. This means "#general" gets the hash stripped
-// then looks up "general", but "#C123..." also strips the hash and then
-// tries to look up "C123..." (which would require an API call).
-//
-// For proper testing, we'd refactor to use an interface:
-//
-//   type SlackAPI interface {
-//       GetConversations(params *GetConversationsParameters) ([]Channel, string, error)
-//       // ...
-//   }
-//
-// Then inject a mock in tests.
-
-// Note: Testing functions that require Slack API calls (ValidateCredentials,
-// ListScheduledMessages, etc.) would require either:
-// 1. A mock/fake Slack client interface
-// 2. Integration tests with a real Slack token
-//
-// For a production app, consider refactoring Client to use an interface:
-//
-// type SlackAPI interface {
-//     AuthTest() (*slack.AuthTestResponse, error)
-//     PostMessage(channel string, options ...slack.MsgOption) (string, string, error)
-//     // ... etc
-// }
-//
-// This would allow injecting a mock for testing.
+func TestStaleScheduledMessages(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	messages := []slack.ScheduledMessage{
+		{ID: "Q1", PostAt: int(now.Add(-24 * time.Hour).Unix())},
+		{ID: "Q2", PostAt: int(now.Unix())},
+		{ID: "Q3", PostAt: int(now.Add(time.Hour).Unix())},
+	}
+
+	stale := StaleScheduledMessages(messages, now)
+	if len(stale) != 2 {
+		t.Fatalf("expected 2 stale messages, got %d", len(stale))
+	}
+	if stale[0].ID != "Q1" || stale[1].ID != "Q2" {
+		t.Errorf("stale IDs = %s, %s, want Q1, Q2", stale[0].ID, stale[1].ID)
+	}
+
+	if got := StaleScheduledMessages(nil, now); len(got) != 0 {
+		t.Errorf("expected no stale messages for empty input, got %d", len(got))
+	}
+}
+
+// TestClient_CachedChannels verifies channel lookups are served from the
+// per-client cache without making API calls
+func TestClient_CachedChannels(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointConversations, "", []slack.Channel{
+		{GroupConversation: slack.GroupConversation{Name: "general", Conversation: slack.Conversation{ID: "C111"}}},
+		{GroupConversation: slack.GroupConversation{Name: "random", Conversation: slack.Conversation{ID: "C222"}}},
+	})
+
+	id, err := client.GetChannelID("#random")
+	if err != nil {
+		t.Fatalf("GetChannelID() error = %v", err)
+	}
+	if id != "C222" {
+		t.Errorf("GetChannelID() = %s, want C222", id)
+	}
+
+	name, err := client.GetChannelName("C111")
+	if err != nil {
+		t.Fatalf("GetChannelName() error = %v", err)
+	}
+	if name != "general" {
+		t.Errorf("GetChannelName() = %s, want general", name)
+	}
+
+	nameMap, err := client.GetChannelNameMap()
+	if err != nil {
+		t.Fatalf("GetChannelNameMap() error = %v", err)
+	}
+	if len(nameMap) != 2 {
+		t.Errorf("GetChannelNameMap() returned %d entries, want 2", len(nameMap))
+	}
+}
+
+// TestClient_CachedScheduledMessages verifies a cached unfiltered list is
+// reused for per-channel listings
+func TestClient_CachedScheduledMessages(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointScheduled, "", []slack.ScheduledMessage{
+		{ID: "Q1", Channel: "C111"},
+		{ID: "Q2", Channel: "C222"},
+		{ID: "Q3", Channel: "C111"},
+	})
+
+	messages, err := client.ListScheduledMessages("C111")
+	if err != nil {
+		t.Fatalf("ListScheduledMessages() error = %v", err)
+	}
+	if len(messages) != 2 {
+		t.Errorf("expected 2 messages for C111, got %d", len(messages))
+	}
+
+	client.invalidateScheduled()
+	if _, ok := client.cachedScheduled("C111"); ok {
+		t.Error("invalidateScheduled() should clear cached lists")
+	}
+}
+
+func TestClient_GetUserNameMap_Cached(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointUsers, "", []slack.User{
+		{ID: "U1", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice A"}},
+		{ID: "U2", Name: "bob"},
+	})
+
+	nameMap, err := client.GetUserNameMap()
+	if err != nil {
+		t.Fatalf("GetUserNameMap() error = %v", err)
+	}
+	if nameMap["U1"] != "Alice A" {
+		t.Errorf("nameMap[U1] = %q, want display name", nameMap["U1"])
+	}
+	if nameMap["U2"] != "bob" {
+		t.Errorf("nameMap[U2] = %q, want username fallback", nameMap["U2"])
+	}
+}
+
+func TestClient_Prefetch_AllCached(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointConversations, "", []slack.Channel{})
+	client.cache.Set(EndpointUsers, "", []slack.User{})
+	client.cache.Set(EndpointScheduled, "", []slack.ScheduledMessage{})
+
+	// Everything is already cached, so no API calls are made
+	if err := client.Prefetch(); err != nil {
+		t.Errorf("Prefetch() error = %v", err)
+	}
+}
+
+func TestClient_GetCustomEmoji_Cached(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointEmoji, "", map[string]string{"shipit-parrot": "https://example.com/parrot.gif"})
+
+	emoji, err := client.GetCustomEmoji()
+	if err != nil {
+		t.Fatalf("GetCustomEmoji() error = %v", err)
+	}
+	if _, ok := emoji["shipit-parrot"]; !ok {
+		t.Errorf("GetCustomEmoji() = %v, want shipit-parrot", emoji)
+	}
+}
 
 // Benchmark for channel ID detection (since it's called frequently)
 func BenchmarkGetChannelID_AlreadyID(b *testing.B) {
@@ -87,3 +187,346 @@ func BenchmarkGetChannelID_AlreadyID(b *testing.B) {
 		client.GetChannelID("C1234567890")
 	}
 }
+
+func TestIsUserTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"@alice", true},
+		{"U1234567890", true},
+		{"W1234567890", true},
+		{"C1234567890", false},
+		{"D1234567890", false},
+		{"#general", false},
+		{"updates", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			if got := IsUserTarget(tt.target); got != tt.want {
+				t.Errorf("IsUserTarget(%q) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_GetUserID_Cached(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointUsers, "", []slack.User{
+		{ID: "U1", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice A"}},
+		{ID: "U2", Name: "bob", Profile: slack.UserProfile{Email: "bob@example.com"}},
+	})
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"username", "@bob", "U2", false},
+		{"email", "@Bob@example.com", "U2", false},
+		{"display name", "@Alice A", "U1", false},
+		{"user ID passthrough", "U999", "U999", false},
+		{"unknown", "@carol", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.GetUserID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetUserID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetUserID(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	// Channel lookups resolve user targets to their DM
+	client.cache.Set(EndpointConversations, "im-U2", "D2")
+	if got, err := client.GetChannelID("@bob"); err != nil || got != "D2" {
+		t.Errorf("GetChannelID(@bob) = %q, %v, want D2", got, err)
+	}
+}
+
+func TestClient_FindUser_Cached(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointUsers, "", []slack.User{
+		{ID: "U1", Name: "alice", Profile: slack.UserProfile{DisplayName: "Alice", Email: "alice@example.com"}},
+		{ID: "U2", Name: "alice.b", Profile: slack.UserProfile{DisplayName: "alice"}},
+		{ID: "U3", Name: "bob", Profile: slack.UserProfile{Email: "bob@example.com"}},
+		{ID: "U4", Name: "carol", Deleted: true},
+	})
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"username", "@bob", "U3", false},
+		{"email", "@Alice@Example.com", "U1", false},
+		{"ambiguous", "@alice", "", true},
+		{"deleted user", "@carol", "", true},
+		{"unknown", "@dave", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.FindUser(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindUser(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FindUser(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_FindUserGroup_Cached(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointUserGroups, "", []slack.UserGroup{
+		{ID: "S1", Handle: "engineering-team", Name: "Engineering"},
+		{ID: "S2", Handle: "oncall"},
+	})
+
+	if got, err := client.FindUserGroup("@Engineering-Team"); err != nil || got != "S1" {
+		t.Errorf("FindUserGroup(@Engineering-Team) = %q, %v, want S1", got, err)
+	}
+	if _, err := client.FindUserGroup("@design"); err == nil {
+		t.Error("FindUserGroup(@design) expected error")
+	}
+}
+
+func TestClient_GetUserTimezone_MockServer(t *testing.T) {
+	server := newMockSlackServer(t)
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	timings := client.EnableTimings()
+
+	for i := 0; i < 2; i++ {
+		loc, err := client.GetUserTimezone("U123")
+		if err != nil {
+			t.Fatalf("GetUserTimezone() error = %v", err)
+		}
+		if loc.String() != "America/New_York" {
+			t.Errorf("GetUserTimezone() = %s, want America/New_York", loc)
+		}
+	}
+
+	// The second lookup is served from the cache
+	if got := timings.Stats()["users.info"].Calls; got != 1 {
+		t.Errorf("recorded %d users.info calls, want 1", got)
+	}
+}
+
+func TestClient_OpenDM_MockServer(t *testing.T) {
+	server := newMockSlackServer(t)
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	timings := client.EnableTimings()
+
+	for _, target := range []string{"U123", "U123"} {
+		got, err := client.GetChannelID(target)
+		if err != nil {
+			t.Fatalf("GetChannelID(%s) error = %v", target, err)
+		}
+		if got != "D123" {
+			t.Errorf("GetChannelID(%s) = %s, want D123", target, got)
+		}
+	}
+
+	// The second lookup is served from the cache
+	if got := timings.Stats()["conversations.open"].Calls; got != 1 {
+		t.Errorf("recorded %d conversations.open calls, want 1", got)
+	}
+}
+
+func TestClient_GetUserTimezone_Unset(t *testing.T) {
+	client := NewClient("fake-token")
+	client.cache.Set(EndpointUsers, "tz-U1", "")
+
+	if _, err := client.GetUserTimezone("U1"); err == nil {
+		t.Error("GetUserTimezone() expected error for user without a time zone")
+	}
+}
+
+// auditEntries is an AuditRecorder that keeps entries in memory
+type auditEntries []types.AuditEntry
+
+func (a *auditEntries) Record(entry types.AuditEntry) error {
+	*a = append(*a, entry)
+	return nil
+}
+
+func TestClient_EnableAudit_MockServer(t *testing.T) {
+	server := newMockSlackServer(t)
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	// Nothing is recorded before auditing is enabled
+	postAt := time.Now().Add(time.Hour)
+	if _, err := client.ScheduleMessage("C123", "before", postAt); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries auditEntries
+	client.EnableAudit(&entries, "schedule")
+	id, err := client.ScheduleMessage("C123", "hello", postAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteScheduledMessage("C123", id); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2", len(entries))
+	}
+	scheduled, deleted := entries[0], entries[1]
+	if scheduled.Action != "schedule" || scheduled.Command != "schedule" || scheduled.SlackID != id || scheduled.Message != "hello" || !scheduled.PostAt.Equal(postAt) || scheduled.Time.IsZero() {
+		t.Errorf("schedule entry = %+v", scheduled)
+	}
+	if deleted.Action != "delete" || deleted.Channel != "C123" || deleted.SlackID != id {
+		t.Errorf("delete entry = %+v", deleted)
+	}
+}
+
+func TestClient_ScheduleMessage_ReturnsScheduledMessageID(t *testing.T) {
+	server := newMockSlackServer(t)
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	if id, err := client.ScheduleMessage("C123", "hello", time.Now().Add(time.Hour)); err != nil || id != "Q123" {
+		t.Errorf("ScheduleMessage() = %q, %v, want Slack's scheduled_message_id Q123", id, err)
+	}
+
+	// An ok response without an ID can't be deleted later, so it's an error
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"channel":"C123"}`))
+	}))
+	defer empty.Close()
+	client = NewClientWithAPIURL("fake-token", empty.URL+"/")
+	if _, err := client.ScheduleMessage("C123", "hello", time.Now().Add(time.Hour)); err == nil {
+		t.Error("ScheduleMessage() without a scheduled_message_id should fail")
+	}
+
+	// Slack's errors come back as slack-go's, so retries and exit codes see them
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer failing.Close()
+	client = NewClientWithAPIURL("fake-token", failing.URL+"/")
+	var resp slack.SlackErrorResponse
+	if _, err := client.ScheduleMessage("C123", "hello", time.Now().Add(time.Hour)); !errors.As(err, &resp) || resp.Err != "channel_not_found" {
+		t.Errorf("ScheduleMessage() error = %v, want a SlackErrorResponse", err)
+	}
+}
+
+func TestClient_ScheduleMessage_Persona(t *testing.T) {
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		forms = append(forms, r.Form)
+		w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+	}))
+	defer server.Close()
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	postAt := time.Now().Add(time.Hour)
+
+	if _, err := client.ScheduleMessage("C123", "plain", postAt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ScheduleMessage("C123", "ship it", postAt, WithPersona("Release Bot", "rocket")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(forms) != 2 {
+		t.Fatalf("got %d requests, want 2", len(forms))
+	}
+	if got := forms[0].Get("as_user"); got != "true" {
+		t.Errorf("plain message as_user = %q, want true", got)
+	}
+	persona := forms[1]
+	if persona.Get("as_user") != "" || persona.Get("username") != "Release Bot" || persona.Get("icon_emoji") != ":rocket:" {
+		t.Errorf("persona message as_user = %q, username = %q, icon_emoji = %q", persona.Get("as_user"), persona.Get("username"), persona.Get("icon_emoji"))
+	}
+}
+
+func TestClient_ListChannels_Paginated(t *testing.T) {
+	var cursors []string
+	limited := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		cursor := r.Form.Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "page2" && !limited {
+			limited = true
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch cursor {
+		case "":
+			w.Write([]byte(`{"ok":true,"channels":[{"id":"C1","name":"general"}],"response_metadata":{"next_cursor":"page2"}}`))
+		default:
+			w.Write([]byte(`{"ok":true,"channels":[{"id":"C2","name":"random"}],"response_metadata":{"next_cursor":""}}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	client.sleep = func(time.Duration) {}
+
+	id, err := client.GetChannelID("#random")
+	if err != nil {
+		t.Fatalf("GetChannelID() error = %v", err)
+	}
+	if id != "C2" {
+		t.Errorf("GetChannelID() = %q, want C2 from the second page", id)
+	}
+	if want := []string{"", "page2", "page2"}; strings.Join(cursors, ",") != strings.Join(want, ",") {
+		t.Errorf("requested cursors %q, want %q", cursors, want)
+	}
+}
+
+func TestClient_ListScheduledMessages_Paginated(t *testing.T) {
+	var requests []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		requests = append(requests, r.Form)
+		switch r.Form.Get("cursor") {
+		case "":
+			w.Write([]byte(`{"ok":true,"scheduled_messages":[{"id":"Q1","channel_id":"C1"},{"id":"Q2","channel_id":"C1"}],"response_metadata":{"next_cursor":"page2"}}`))
+		default:
+			w.Write([]byte(`{"ok":true,"scheduled_messages":[{"id":"Q3","channel_id":"C1"}],"response_metadata":{"next_cursor":""}}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	limited, err := client.ListScheduledMessagesLimit("", 2)
+	if err != nil {
+		t.Fatalf("ListScheduledMessagesLimit() error = %v", err)
+	}
+	if len(limited) != 2 || len(requests) != 1 {
+		t.Errorf("ListScheduledMessagesLimit(2) = %d messages in %d requests, want 2 in 1", len(limited), len(requests))
+	}
+
+	all, err := client.ListScheduledMessages("")
+	if err != nil {
+		t.Fatalf("ListScheduledMessages() error = %v", err)
+	}
+	if len(all) != 3 || all[2].ID != "Q3" {
+		t.Errorf("ListScheduledMessages() = %+v, want Q1-Q3 across both pages", all)
+	}
+	if len(requests) != 3 {
+		t.Errorf("made %d requests, want 3 (the limited list should not be cached)", len(requests))
+	}
+
+	if again, _ := client.ListScheduledMessagesLimit("", 1); len(again) != 1 || len(requests) != 3 {
+		t.Errorf("ListScheduledMessagesLimit(1) = %d messages, %d requests, want 1 from cache", len(again), len(requests))
+	}
+}
@@ -0,0 +1,50 @@
+package slack
+
+import (
+	"errors"
+
+	"github.com/slack-go/slack"
+)
+
+// ErrChannelNotFound matches (with errors.Is) the errors returned for a channel
+// that doesn't exist or that the token can't see
+var ErrChannelNotFound = errors.New("channel not found")
+
+// channelNotFoundError keeps its own wording while matching ErrChannelNotFound
+type channelNotFoundError struct {
+	msg string
+}
+
+func (e channelNotFoundError) Error() string { return e.msg }
+
+func (e channelNotFoundError) Is(target error) bool { return target == ErrChannelNotFound }
+
+// authErrors are the Slack errors meaning the token itself is the problem:
+// missing, invalid, revoked or without the scope the call needs
+var authErrors = map[string]bool{
+	"not_authed":             true,
+	"invalid_auth":           true,
+	"account_inactive":       true,
+	"token_revoked":          true,
+	"token_expired":          true,
+	"missing_scope":          true,
+	"not_allowed_token_type": true,
+	"no_permission":          true,
+	"ekm_access_denied":      true,
+}
+
+// IsAuthError reports whether err comes from Slack rejecting the token
+func IsAuthError(err error) bool {
+	var resp slack.SlackErrorResponse
+	return errors.As(err, &resp) && authErrors[resp.Err]
+}
+
+// IsChannelNotFound reports whether err is about a channel that doesn't exist or
+// isn't visible to the token, whether found by name or rejected by Slack
+func IsChannelNotFound(err error) bool {
+	if errors.Is(err, ErrChannelNotFound) {
+		return true
+	}
+	var resp slack.SlackErrorResponse
+	return errors.As(err, &resp) && resp.Err == "channel_not_found"
+}
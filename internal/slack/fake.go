@@ -0,0 +1,313 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// FakeAPI is an in-memory Slack workspace implementing API, so code built on
+// Client can be tested without a token or network. Scheduled messages are kept
+// in order of creation and can be listed and deleted like the real thing.
+type FakeAPI struct {
+	mu sync.Mutex
+
+	// Workspace contents returned by the list and lookup methods
+	Channels   []slack.Channel
+	Users      []slack.User
+	UserGroups []slack.UserGroup
+	Emoji      map[string]string
+
+	// User IDs in each channel, keyed by channel ID (conversations.members)
+	Members map[string][]string
+
+	// User the token belongs to (auth.test)
+	SelfID string
+
+	// Set once the token has been revoked (auth.revoke), after which every call
+	// fails with invalid_auth
+	Revoked bool
+
+	// Messages currently scheduled
+	Scheduled []slack.ScheduledMessage
+
+	// Messages sent with chat.postMessage
+	Posted []FakePost
+
+	// Errors to return, keyed by API method (e.g. "chat.scheduleMessage"); an
+	// entry applies to every call until removed
+	Errors map[string]error
+
+	// Number of calls made, keyed by API method
+	Calls map[string]int
+
+	nextID int
+}
+
+// FakePost is a message sent through a FakeAPI
+type FakePost struct {
+	Channel string
+	Text    string
+
+	// All form values the message was sent with (thread_ts, metadata, ...)
+	Values url.Values
+}
+
+// NewFakeAPI returns an empty fake workspace whose token belongs to U0SELF
+func NewFakeAPI() *FakeAPI {
+	return &FakeAPI{SelfID: "U0SELF"}
+}
+
+// NewFakeClient returns a client backed by api
+func NewFakeClient(api *FakeAPI) *Client {
+	return NewClientWithAPI(api)
+}
+
+// AddChannel adds a public channel the token is a member of and returns its ID
+func (f *FakeAPI) AddChannel(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	id := fmt.Sprintf("C%07d", f.nextID)
+	var ch slack.Channel
+	ch.ID = id
+	ch.Name = name
+	ch.IsChannel = true
+	ch.IsMember = true
+	f.Channels = append(f.Channels, ch)
+	return id
+}
+
+// AddUser adds a user and returns their ID
+func (f *FakeAPI) AddUser(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	id := fmt.Sprintf("U%07d", f.nextID)
+	f.Users = append(f.Users, slack.User{ID: id, Name: name, TZ: "UTC"})
+	return id
+}
+
+// begin counts a call to method and returns the error injected for it, if any
+func (f *FakeAPI) begin(method string) error {
+	if f.Calls == nil {
+		f.Calls = make(map[string]int)
+	}
+	f.Calls[method]++
+	if f.Revoked {
+		return slack.SlackErrorResponse{Err: "invalid_auth"}
+	}
+	return f.Errors[method]
+}
+
+func (f *FakeAPI) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("auth.test"); err != nil {
+		return nil, err
+	}
+	return &slack.AuthTestResponse{UserID: f.SelfID, User: "self", TeamID: "T0FAKE", Team: "fake"}, nil
+}
+
+func (f *FakeAPI) SendAuthRevokeContext(ctx context.Context, token string) (*slack.AuthRevokeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("auth.revoke"); err != nil {
+		return nil, err
+	}
+	f.Revoked = true
+	return &slack.AuthRevokeResponse{Revoked: true}, nil
+}
+
+func (f *FakeAPI) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("chat.postMessage"); err != nil {
+		return "", "", err
+	}
+	values, err := fakeValues(channelID, options)
+	if err != nil {
+		return "", "", err
+	}
+	f.Posted = append(f.Posted, FakePost{Channel: channelID, Text: values.Get("text"), Values: values})
+	return channelID, fmt.Sprintf("%d.%06d", time.Now().Unix(), len(f.Posted)), nil
+}
+
+func (f *FakeAPI) ScheduleMessageIDContext(ctx context.Context, channelID, postAt string, options ...slack.MsgOption) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("chat.scheduleMessage"); err != nil {
+		return "", "", err
+	}
+	at, err := strconv.Atoi(postAt)
+	if err != nil {
+		return "", "", slack.SlackErrorResponse{Err: "invalid_time"}
+	}
+	values, err := fakeValues(channelID, options)
+	if err != nil {
+		return "", "", err
+	}
+
+	f.nextID++
+	id := fmt.Sprintf("Q%07d", f.nextID)
+	f.Scheduled = append(f.Scheduled, slack.ScheduledMessage{
+		ID:          id,
+		Channel:     channelID,
+		PostAt:      at,
+		DateCreated: int(time.Now().Unix()),
+		Text:        values.Get("text"),
+	})
+	return channelID, id, nil
+}
+
+func (f *FakeAPI) GetScheduledMessagesContext(ctx context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("chat.scheduledMessages.list"); err != nil {
+		return nil, "", err
+	}
+
+	var matching []slack.ScheduledMessage
+	for _, msg := range f.Scheduled {
+		if params.Channel == "" || msg.Channel == params.Channel {
+			matching = append(matching, msg)
+		}
+	}
+	// The cursor is the offset of the next page
+	start, _ := strconv.Atoi(params.Cursor)
+	if start > len(matching) {
+		start = len(matching)
+	}
+	end := len(matching)
+	if params.Limit > 0 && start+params.Limit < end {
+		end = start + params.Limit
+	}
+	next := ""
+	if end < len(matching) {
+		next = strconv.Itoa(end)
+	}
+	return append([]slack.ScheduledMessage(nil), matching[start:end]...), next, nil
+}
+
+func (f *FakeAPI) DeleteScheduledMessageContext(ctx context.Context, params *slack.DeleteScheduledMessageParameters) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("chat.deleteScheduledMessage"); err != nil {
+		return false, err
+	}
+	for i, msg := range f.Scheduled {
+		if msg.ID == params.ScheduledMessageID && msg.Channel == params.Channel {
+			f.Scheduled = append(f.Scheduled[:i], f.Scheduled[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, slack.SlackErrorResponse{Err: "invalid_scheduled_message_id"}
+}
+
+func (f *FakeAPI) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("conversations.list"); err != nil {
+		return nil, "", err
+	}
+	return append([]slack.Channel(nil), f.Channels...), "", nil
+}
+
+func (f *FakeAPI) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("conversations.info"); err != nil {
+		return nil, err
+	}
+	for _, ch := range f.Channels {
+		if ch.ID == input.ChannelID {
+			return &ch, nil
+		}
+	}
+	return nil, slack.SlackErrorResponse{Err: "channel_not_found"}
+}
+
+func (f *FakeAPI) GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("conversations.members"); err != nil {
+		return nil, "", err
+	}
+	for _, ch := range f.Channels {
+		if ch.ID == params.ChannelID {
+			return append([]string(nil), f.Members[ch.ID]...), "", nil
+		}
+	}
+	return nil, "", slack.SlackErrorResponse{Err: "channel_not_found"}
+}
+
+func (f *FakeAPI) OpenConversationContext(ctx context.Context, params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("conversations.open"); err != nil {
+		return nil, false, false, err
+	}
+	if len(params.Users) != 1 {
+		return nil, false, false, slack.SlackErrorResponse{Err: "not_enough_users"}
+	}
+	var ch slack.Channel
+	ch.ID = "D" + params.Users[0][1:]
+	ch.IsIM = true
+	return &ch, false, false, nil
+}
+
+func (f *FakeAPI) GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("users.list"); err != nil {
+		return nil, err
+	}
+	return append([]slack.User(nil), f.Users...), nil
+}
+
+func (f *FakeAPI) GetUserInfoContext(ctx context.Context, user string) (*slack.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("users.info"); err != nil {
+		return nil, err
+	}
+	for _, u := range f.Users {
+		if u.ID == user {
+			return &u, nil
+		}
+	}
+	return nil, slack.SlackErrorResponse{Err: "user_not_found"}
+}
+
+func (f *FakeAPI) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("usergroups.list"); err != nil {
+		return nil, err
+	}
+	return append([]slack.UserGroup(nil), f.UserGroups...), nil
+}
+
+func (f *FakeAPI) GetEmojiContext(ctx context.Context) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("emoji.list"); err != nil {
+		return nil, err
+	}
+	emoji := make(map[string]string, len(f.Emoji))
+	for name, url := range f.Emoji {
+		emoji[name] = url
+	}
+	return emoji, nil
+}
+
+// fakeValues renders message options into the form values Slack would receive
+func fakeValues(channelID string, options []slack.MsgOption) (url.Values, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("fake-token", channelID, "https://slack.invalid/api/", options...)
+	return values, err
+}
@@ -0,0 +1,47 @@
+package slack
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestFakeAPI_ScheduledMessages(t *testing.T) {
+	api := NewFakeAPI()
+	channel := api.AddChannel("general")
+	client := NewFakeClient(api)
+	postAt := time.Now().Add(time.Hour)
+
+	var ids []string
+	for i := 0; i < 250; i++ {
+		id, err := client.ScheduleMessage(channel, fmt.Sprintf("message %d", i), postAt)
+		if err != nil {
+			t.Fatalf("ScheduleMessage() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+	messages, err := client.ListScheduledMessages(channel)
+	if err != nil {
+		t.Fatalf("ListScheduledMessages() error = %v", err)
+	}
+	if messages[0].ID != ids[0] || messages[249].ID != ids[249] {
+		t.Errorf("listed IDs %s..%s, want the IDs ScheduleMessage returned %s..%s", messages[0].ID, messages[249].ID, ids[0], ids[249])
+	}
+	if len(messages) != 250 || api.Calls["chat.scheduledMessages.list"] != 3 {
+		t.Errorf("listed %d messages in %d pages, want 250 in 3", len(messages), api.Calls["chat.scheduledMessages.list"])
+	}
+
+	if err := client.DeleteScheduledMessage(channel, messages[0].ID); err != nil {
+		t.Fatalf("DeleteScheduledMessage() error = %v", err)
+	}
+	if err := client.DeleteScheduledMessage(channel, messages[0].ID); err == nil {
+		t.Error("deleting the same message twice should fail")
+	}
+
+	api.Errors = map[string]error{"chat.scheduleMessage": slack.SlackErrorResponse{Err: "restricted_action"}}
+	if _, err := client.ScheduleMessage(channel, "blocked", postAt); err == nil {
+		t.Error("ScheduleMessage() should return the injected error")
+	}
+}
@@ -0,0 +1,104 @@
+package slack
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics counts API activity and the changes made in Slack, for monitoring a
+// long-running process
+type Metrics struct {
+	mu sync.Mutex
+
+	calls       map[string]*CallStats
+	errors      map[string]int
+	rateLimited map[string]int
+
+	// Changes made in Slack, by audit action (schedule, delete, send)
+	changes map[string]int
+}
+
+// MetricsSnapshot is a copy of the counters at one moment
+type MetricsSnapshot struct {
+	// Per API method: requests made (every attempt), failed calls (after
+	// retries) and responses that were rate limited
+	Calls       map[string]CallStats
+	Errors      map[string]int
+	RateLimited map[string]int
+
+	// Messages scheduled, deleted and sent
+	Scheduled, Deleted, Sent int
+}
+
+// NewMetrics creates an empty set of counters
+func NewMetrics() *Metrics {
+	return &Metrics{
+		calls:       make(map[string]*CallStats),
+		errors:      make(map[string]int),
+		rateLimited: make(map[string]int),
+		changes:     make(map[string]int),
+	}
+}
+
+// EnableMetrics starts counting API activity and returns the counters. Calling it
+// again returns the same counters.
+func (c *Client) EnableMetrics() *Metrics {
+	if c.metrics == nil {
+		c.metrics = NewMetrics()
+	}
+	return c.metrics
+}
+
+func (m *Metrics) attempt(method string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.calls[method]
+	if !ok {
+		s = &CallStats{}
+		m.calls[method] = s
+	}
+	s.Calls++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+	if _, limited := rateLimited(err); limited {
+		m.rateLimited[method]++
+	}
+}
+
+func (m *Metrics) failed(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[method]++
+}
+
+func (m *Metrics) changed(action string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changes[action]++
+}
+
+// Snapshot returns the current counts
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap := MetricsSnapshot{
+		Calls:       make(map[string]CallStats, len(m.calls)),
+		Errors:      make(map[string]int, len(m.errors)),
+		RateLimited: make(map[string]int, len(m.rateLimited)),
+		Scheduled:   m.changes["schedule"],
+		Deleted:     m.changes["delete"],
+		Sent:        m.changes["send"],
+	}
+	for method, s := range m.calls {
+		snap.Calls[method] = *s
+	}
+	for method, n := range m.errors {
+		snap.Errors[method] = n
+	}
+	for method, n := range m.rateLimited {
+		snap.RateLimited[method] = n
+	}
+	return snap
+}
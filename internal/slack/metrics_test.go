@@ -0,0 +1,50 @@
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestClient_Metrics(t *testing.T) {
+	api := NewFakeAPI()
+	channel := api.AddChannel("general")
+	client := NewFakeClient(api)
+	client.sleep = func(time.Duration) {}
+	metrics := client.EnableMetrics()
+	if client.EnableMetrics() != metrics {
+		t.Error("EnableMetrics() twice returned different counters")
+	}
+
+	if _, err := client.ScheduleMessage(channel, "hello", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("ScheduleMessage() error = %v", err)
+	}
+	if err := client.DeleteScheduledMessage(channel, api.Scheduled[0].ID); err != nil {
+		t.Fatalf("DeleteScheduledMessage() error = %v", err)
+	}
+	if _, err := client.SendMessage(channel, "now"); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	api.Errors = map[string]error{"chat.scheduleMessage": &slack.RateLimitedError{}}
+	if _, err := client.ScheduleMessage(channel, "limited", time.Now().Add(time.Hour)); err == nil {
+		t.Fatal("ScheduleMessage() expected a rate limit error")
+	}
+
+	snap := metrics.Snapshot()
+	if snap.Scheduled != 1 || snap.Deleted != 1 || snap.Sent != 1 {
+		t.Errorf("changes = %d scheduled, %d deleted, %d sent; want 1 each", snap.Scheduled, snap.Deleted, snap.Sent)
+	}
+	attempts := DefaultRetryPolicy.MaxRetries + 1
+	if got := snap.Calls["chat.scheduleMessage"].Calls; got != 1+attempts {
+		t.Errorf("chat.scheduleMessage requests = %d, want %d", got, 1+attempts)
+	}
+	if snap.RateLimited["chat.scheduleMessage"] != attempts || snap.Errors["chat.scheduleMessage"] != 1 {
+		t.Errorf("chat.scheduleMessage rate limited = %d, errors = %d; want %d and 1",
+			snap.RateLimited["chat.scheduleMessage"], snap.Errors["chat.scheduleMessage"], attempts)
+	}
+	if snap.Errors["chat.deleteScheduledMessage"] != 0 {
+		t.Errorf("chat.deleteScheduledMessage errors = %d, want 0", snap.Errors["chat.deleteScheduledMessage"])
+	}
+}
@@ -0,0 +1,229 @@
+package slack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// DefaultUserScopes are the user token scopes the scheduler uses: sending and
+// scheduling messages, resolving channels, users, user groups and emoji, and
+// opening DMs
+var DefaultUserScopes = []string{
+	"chat:write", "channels:read", "groups:read", "im:write",
+	"users:read", "users:read.email", "usergroups:read", "emoji:read",
+}
+
+// DefaultLoginPort is where Login listens for Slack's redirect unless told
+// otherwise; the app needs http://localhost:8976/callback as a redirect URL
+const DefaultLoginPort = 8976
+
+// slackAuthorizeURL is where users approve the app
+const slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+// OAuthApp is the Slack app a user logs in through, from its Basic Information page
+type OAuthApp struct {
+	ClientID     string
+	ClientSecret string
+
+	// User token scopes to request (DefaultUserScopes if empty)
+	Scopes []string
+}
+
+// LoginOptions configure Login
+type LoginOptions struct {
+	// Local port Slack redirects back to (DefaultLoginPort if zero)
+	Port int
+
+	// Shows the user the page to approve the app, e.g. by opening a browser or
+	// printing the URL
+	Open func(authorizeURL string) error
+
+	// Client for the token exchange (http.DefaultClient if nil)
+	HTTPClient *http.Client
+}
+
+// OAuthToken is the user token a login produced
+type OAuthToken struct {
+	Token  string
+	UserID string
+	Team   string
+	Scope  string
+}
+
+// AuthorizeURL is the page where the user approves app for the scopes, after
+// which Slack redirects to redirectURI with a code and the given state
+func AuthorizeURL(app OAuthApp, redirectURI, state string) string {
+	scopes := app.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultUserScopes
+	}
+	query := url.Values{
+		"client_id":    {app.ClientID},
+		"user_scope":   {strings.Join(scopes, ",")},
+		"redirect_uri": {redirectURI},
+		"state":        {state},
+	}
+	return slackAuthorizeURL + "?" + query.Encode()
+}
+
+// Login runs Slack's OAuth flow with a redirect to a local server: it shows the
+// user the approval page, waits for Slack to send them back with a code and
+// exchanges that for a user token. It gives up when ctx is done.
+func Login(ctx context.Context, app OAuthApp, opts LoginOptions) (*OAuthToken, error) {
+	if app.ClientID == "" || app.ClientSecret == "" {
+		return nil, fmt.Errorf("the app's client ID and client secret are both required")
+	}
+	port := opts.Port
+	if port == 0 {
+		port = DefaultLoginPort
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the login redirect: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://localhost:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	state, err := randomState()
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	codes := make(chan callbackResult, 1)
+	srv := &http.Server{Handler: callbackHandler(state, codes)}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	if opts.Open != nil {
+		if err := opts.Open(AuthorizeURL(app, redirectURI, state)); err != nil {
+			return nil, err
+		}
+	}
+
+	var result callbackResult
+	select {
+	case result = <-codes:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("login not completed: %w", ctx.Err())
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+	return ExchangeCode(ctx, app, result.code, redirectURI, opts.HTTPClient)
+}
+
+// ExchangeCode trades the code from Slack's redirect for the user's token
+func ExchangeCode(ctx context.Context, app OAuthApp, code, redirectURI string, client *http.Client) (*OAuthToken, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := slack.GetOAuthV2ResponseContext(ctx, client, app.ClientID, app.ClientSecret, code, redirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange the login code for a token: %w", err)
+	}
+	if resp.AuthedUser.AccessToken == "" {
+		return nil, fmt.Errorf("slack returned no user token (the app must request user token scopes, not bot scopes)")
+	}
+	return &OAuthToken{
+		Token:  resp.AuthedUser.AccessToken,
+		UserID: resp.AuthedUser.ID,
+		Team:   resp.Team.Name,
+		Scope:  resp.AuthedUser.Scope,
+	}, nil
+}
+
+// callbackResult is what Slack's redirect brought back
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// callbackHandler receives Slack's redirect, checks it belongs to this login and
+// passes on the code (or the reason there is none). Only the first redirect
+// counts.
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var result callbackResult
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "This login link has expired; run auth login again.", http.StatusBadRequest)
+			return
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("login was not approved: %s", query.Get("error"))
+		case query.Get("code") == "":
+			result.err = errors.New("slack redirected back without a code")
+		default:
+			result.code = query.Get("code")
+		}
+
+		message := "Logged in. You can close this tab and return to the terminal."
+		if result.err != nil {
+			message = "Login failed: " + result.err.Error()
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<!doctype html><title>slack-scheduler</title><p>%s</p>\n", html.EscapeString(message))
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	return mux
+}
+
+// randomState returns an unguessable value tying Slack's redirect to this login
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate login state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Identity is who a token belongs to (auth.test)
+type Identity struct {
+	UserID string
+	User   string
+	TeamID string
+	Team   string
+
+	// Set for bot tokens, whose scheduled messages don't show in Slack's UI
+	BotID string
+}
+
+// Identity returns who the client's token belongs to, e.g. for auth status
+func (c *Client) Identity() (Identity, error) {
+	var resp *slack.AuthTestResponse
+	err := c.call("auth.test", func() (err error) {
+		resp, err = c.api.AuthTestContext(c.context())
+		return err
+	})
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid credentials: %w", err)
+	}
+	return Identity{UserID: resp.UserID, User: resp.User, TeamID: resp.TeamID, Team: resp.Team, BotID: resp.BotID}, nil
+}
+
+// RevokeToken revokes the client's token, e.g. on logout; the client can't be
+// used afterwards
+func (c *Client) RevokeToken() error {
+	err := c.call("auth.revoke", func() error {
+		_, err := c.api.SendAuthRevokeContext(c.context(), "")
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// RetryPolicy controls how API calls that hit Slack's rate limits or fail
+// transiently are retried
+type RetryPolicy struct {
+	// MaxRetries is how many times one call is retried; zero disables retrying
+	MaxRetries int
+
+	// TransientRetries is how many of those retries may follow a transient failure
+	// (a timeout, 5xx or dropped connection) rather than a rate limit. Only
+	// scheduling, listing and deleting scheduled messages are retried this way,
+	// and scheduling only if the message doesn't turn out to be scheduled already.
+	TransientRetries int
+
+	// BaseDelay is the wait before the first retry, doubled for each one after it
+	// and never shorter than the Retry-After Slack asks for
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff (but not Retry-After)
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy rides out the per-minute limits hit when scheduling large batches
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:       5,
+	TransientRetries: 3,
+	BaseDelay:        time.Second,
+	MaxDelay:         30 * time.Second,
+}
+
+// transientRetryMethods are the API methods retried after transient failures
+var transientRetryMethods = map[string]bool{
+	"chat.scheduleMessage":        true,
+	"chat.scheduledMessages.list": true,
+	"chat.deleteScheduledMessage": true,
+}
+
+// transientErrors are Slack error codes for failures on Slack's side
+var transientErrors = map[string]bool{
+	"internal_error":      true,
+	"service_unavailable": true,
+	"request_timeout":     true,
+}
+
+// SetRetryPolicy replaces how failed calls are retried
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// call runs one API request for method, recording its latency and retrying it
+// while Slack answers that the workspace is rate limited or, for some methods,
+// after transient failures
+func (c *Client) call(method string, request func() error) error {
+	_, err := c.callAttempts(method, request)
+	return err
+}
+
+// callAttempts is call, also returning how many attempts the request took
+func (c *Client) callAttempts(method string, request func() error) (int, error) {
+	transient := 0
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		err := request()
+		c.track(method, start)
+		if c.metrics != nil {
+			c.metrics.attempt(method, time.Since(start), err)
+		}
+		if err == nil || attempt >= c.retry.MaxRetries || c.context().Err() != nil {
+			return attempt + 1, c.failed(method, err)
+		}
+
+		retryAfter, limited := rateLimited(err)
+		if !limited {
+			if !transientRetryMethods[method] || !isTransient(err) || transient >= c.retry.TransientRetries {
+				return attempt + 1, c.failed(method, err)
+			}
+			transient++
+		}
+
+		wait := c.retry.delay(attempt, retryAfter)
+		slog.Warn(fmt.Sprintf("%s failed (%v); retrying in %s (attempt %d of %d)",
+			method, err, wait.Round(time.Millisecond), attempt+2, c.retry.MaxRetries+1),
+			"method", method, "error", err, "wait", wait, "attempt", attempt+2, "rate_limited", limited)
+		if err := c.pause(wait); err != nil {
+			return attempt + 1, c.failed(method, err)
+		}
+	}
+}
+
+// failed counts a call that ended in err, if metrics are enabled, and returns err
+func (c *Client) failed(method string, err error) error {
+	if err != nil && c.metrics != nil {
+		c.metrics.failed(method)
+	}
+	return err
+}
+
+// pause waits before a retry, returning early with the context's error if the
+// client's context is cancelled
+func (c *Client) pause(d time.Duration) error {
+	ctx := c.context()
+	if c.sleep != nil {
+		c.sleep(d)
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// delay is the wait before retry number attempt (counting from zero): exponential
+// backoff, at least retryAfter, plus up to 50% jitter so concurrent callers
+// don't retry in lockstep
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	backoff := p.BaseDelay
+	for i := 0; i < attempt && backoff < p.MaxDelay; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	if retryAfter > backoff {
+		backoff = retryAfter
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+}
+
+// isTransient reports whether err is a failure worth retrying as is: a network
+// timeout or dropped connection, an HTTP 5xx, or a Slack-side error
+func isTransient(err error) bool {
+	var status slack.StatusCodeError
+	if errors.As(err, &status) {
+		return status.Code >= 500
+	}
+	var resp slack.SlackErrorResponse
+	if errors.As(err, &resp) {
+		return transientErrors[resp.Err]
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// rateLimited reports whether err is a rate limit response (an HTTP 429 or a
+// "ratelimited" error body) and how long Slack asked to wait, if it said
+func rateLimited(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+	var limited *slack.RateLimitedError
+	if errors.As(err, &limited) {
+		return limited.RetryAfter, true
+	}
+	var resp slack.SlackErrorResponse
+	if errors.As(err, &resp) && (resp.Err == "ratelimited" || resp.Err == "rate_limited") {
+		return 0, true
+	}
+	return 0, false
+}
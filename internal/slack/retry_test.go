@@ -0,0 +1,217 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+
+	tests := []struct {
+		attempt    int
+		retryAfter time.Duration
+		min        time.Duration
+	}{
+		{0, 0, time.Second},
+		{1, 0, 2 * time.Second},
+		{2, 0, 4 * time.Second},
+		{5, 0, 4 * time.Second},                 // capped at MaxDelay
+		{0, 10 * time.Second, 10 * time.Second}, // Retry-After wins over the backoff
+	}
+	for _, tt := range tests {
+		got := policy.delay(tt.attempt, tt.retryAfter)
+		if got < tt.min || got > tt.min+tt.min/2 {
+			t.Errorf("delay(%d, %v) = %v, want between %v and %v", tt.attempt, tt.retryAfter, got, tt.min, tt.min+tt.min/2)
+		}
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	if after, ok := rateLimited(&slack.RateLimitedError{RetryAfter: 3 * time.Second}); !ok || after != 3*time.Second {
+		t.Errorf("rateLimited(429) = %v, %v, want 3s, true", after, ok)
+	}
+	if _, ok := rateLimited(slack.SlackErrorResponse{Err: "ratelimited"}); !ok {
+		t.Error("rateLimited(ratelimited body) = false, want true")
+	}
+	if _, ok := rateLimited(errors.New("channel_not_found")); ok {
+		t.Error("rateLimited(channel_not_found) = true, want false")
+	}
+}
+
+func TestClient_ScheduleMessage_RetriesRateLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	var waits []time.Duration
+	client.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	if _, err := client.ScheduleMessage("C123", "hello", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("ScheduleMessage() error = %v", err)
+	}
+	if requests != 3 || len(waits) != 2 {
+		t.Fatalf("made %d requests with %d waits, want 3 and 2", requests, len(waits))
+	}
+	for _, wait := range waits {
+		if wait < 7*time.Second {
+			t.Errorf("waited %v, want at least the 7s Retry-After", wait)
+		}
+	}
+
+	// Giving up returns the rate limit error
+	requests = 0
+	client.SetRetryPolicy(RetryPolicy{MaxRetries: 1})
+	if _, err := client.ScheduleMessage("C123", "hello", time.Now().Add(time.Hour)); err == nil {
+		t.Error("ScheduleMessage() should fail once retries run out")
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want 2 with MaxRetries 1", requests)
+	}
+}
+
+func TestClient_WithContext_CancelsCalls(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chat.postMessage" {
+			<-release // hang until the test ends
+		}
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	defer close(release)
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+
+	// A hung request returns once the deadline passes
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.WithContext(ctx).SendMessage("C123", "hi"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendMessage() error = %v, want the deadline", err)
+	}
+
+	// So does a retry wait
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	if err := client.WithContext(ctx).DeleteScheduledMessage("C123", "Q1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("DeleteScheduledMessage() error = %v, want cancellation", err)
+	}
+	if waited := time.Since(start); waited > 10*time.Second {
+		t.Errorf("waited %v despite cancellation", waited)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{slack.StatusCodeError{Code: 503, Status: "503 Service Unavailable"}, true},
+		{slack.StatusCodeError{Code: 404, Status: "404 Not Found"}, false},
+		{slack.SlackErrorResponse{Err: "internal_error"}, true},
+		{slack.SlackErrorResponse{Err: "invalid_time"}, false},
+		{fmt.Errorf("post: %w", syscall.ECONNRESET), true},
+		{errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	failures := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures[r.URL.Path] < 2 {
+			failures[r.URL.Path]++
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q1"}`))
+	}))
+	defer server.Close()
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	client.sleep = func(time.Duration) {}
+
+	if _, err := client.ScheduleMessage("C123", "hello", time.Now().Add(time.Hour)); err != nil {
+		t.Errorf("ScheduleMessage() error = %v, want success on the third attempt", err)
+	}
+
+	// Posting isn't retried after transient failures
+	if _, err := client.SendMessage("C123", "hello"); err == nil {
+		t.Error("SendMessage() should not retry a 502")
+	}
+
+	// Nor is anything once the transient retries run out
+	failures = map[string]int{}
+	client.SetRetryPolicy(RetryPolicy{MaxRetries: 5, TransientRetries: 1})
+	if err := client.DeleteScheduledMessage("C123", "Q1"); err == nil {
+		t.Error("DeleteScheduledMessage() should give up after one transient retry")
+	}
+}
+
+// lostResponseAPI schedules messages but, like a gateway timing out after
+// Slack acted, reports the first lost calls as failed
+type lostResponseAPI struct {
+	*FakeAPI
+	lost int
+}
+
+func (a *lostResponseAPI) ScheduleMessageIDContext(ctx context.Context, channelID, postAt string, options ...slack.MsgOption) (string, string, error) {
+	channel, id, err := a.FakeAPI.ScheduleMessageIDContext(ctx, channelID, postAt, options...)
+	if err == nil && a.lost > 0 {
+		a.lost--
+		return "", "", slack.StatusCodeError{Code: http.StatusGatewayTimeout, Status: "504 Gateway Timeout"}
+	}
+	return channel, id, err
+}
+
+func TestClient_ScheduleMessage_NoDuplicateAfterLostResponse(t *testing.T) {
+	fake := NewFakeAPI()
+	channelID := fake.AddChannel("general")
+	client := NewClientWithAPI(&lostResponseAPI{FakeAPI: fake, lost: 1})
+	client.sleep = func(time.Duration) {}
+
+	id, err := client.ScheduleMessage(channelID, "hello", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ScheduleMessage() error = %v", err)
+	}
+	if len(fake.Scheduled) != 1 || fake.Scheduled[0].ID != id {
+		t.Errorf("scheduled = %+v, want only %s", fake.Scheduled, id)
+	}
+	if fake.Calls["chat.scheduleMessage"] != 1 {
+		t.Errorf("chat.scheduleMessage called %d times, want 1", fake.Calls["chat.scheduleMessage"])
+	}
+
+	// If the check itself fails, the message isn't risked twice
+	client = NewClientWithAPI(&lostResponseAPI{FakeAPI: fake, lost: 1})
+	client.sleep = func(time.Duration) {}
+	fake.Errors = map[string]error{"chat.scheduledMessages.list": slack.SlackErrorResponse{Err: "missing_scope"}}
+	if _, err := client.ScheduleMessage(channelID, "again", time.Now().Add(time.Hour)); err == nil {
+		t.Error("ScheduleMessage() should fail when it can't check for the earlier attempt")
+	}
+	if len(fake.Scheduled) != 2 {
+		t.Errorf("scheduled %d message(s), want 2", len(fake.Scheduled))
+	}
+}
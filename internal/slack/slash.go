@@ -0,0 +1,78 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// SlashCommand is an invocation of one of the app's slash commands
+type SlashCommand struct {
+	Command     string
+	Text        string
+	ChannelID   string
+	UserID      string
+	ResponseURL string
+}
+
+// ParseSlashCommand reads a slash command request Slack sent to the app, after
+// checking it was signed with the app's signing secret
+func ParseSlashCommand(r *http.Request, signingSecret string) (SlashCommand, error) {
+	verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+	if err != nil {
+		return SlashCommand{}, fmt.Errorf("unsigned request: %w", err)
+	}
+	body, err := io.ReadAll(io.TeeReader(r.Body, &verifier))
+	if err != nil {
+		return SlashCommand{}, err
+	}
+	if err := verifier.Ensure(); err != nil {
+		return SlashCommand{}, fmt.Errorf("invalid request signature: %w", err)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	cmd, err := slack.SlashCommandParse(r)
+	if err != nil {
+		return SlashCommand{}, err
+	}
+	return SlashCommand{
+		Command:     cmd.Command,
+		Text:        cmd.Text,
+		ChannelID:   cmd.ChannelID,
+		UserID:      cmd.UserID,
+		ResponseURL: cmd.ResponseURL,
+	}, nil
+}
+
+// EphemeralResponse is a slash command reply only the user who ran it sees
+func EphemeralResponse(text string) []byte {
+	body, _ := json.Marshal(slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: text})
+	return body
+}
+
+// Respond sends a later reply to a slash command through its response URL,
+// which Slack accepts for 30 minutes after the command
+func Respond(ctx context.Context, responseURL, text string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(EphemeralResponse(text)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to respond to slash command: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to respond to slash command: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package slack
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+var (
+	threadTSPattern  = regexp.MustCompile(`^\d{10}\.\d{6}$`)
+	permalinkPattern = regexp.MustCompile(`^/archives/([A-Z0-9]+)/p(\d{10})(\d{6})$`)
+)
+
+// ParseThread resolves a --thread value to the parent message timestamp. It
+// accepts a timestamp ("1736931600.000100") or a message permalink
+// ("https://team.slack.com/archives/C123/p1736931600000100"), for which the
+// channel is returned too. A permalink to a reply resolves to its thread's parent.
+func ParseThread(thread string) (channel, ts string, err error) {
+	thread = strings.TrimSpace(thread)
+	if threadTSPattern.MatchString(thread) {
+		return "", thread, nil
+	}
+
+	u, err := url.Parse(thread)
+	if err == nil && u.Host != "" {
+		if m := permalinkPattern.FindStringSubmatch(u.Path); m != nil {
+			ts := m[2] + "." + m[3]
+			if parent := u.Query().Get("thread_ts"); threadTSPattern.MatchString(parent) {
+				ts = parent
+			}
+			return m[1], ts, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid thread: %s (use a message timestamp like 1736931600.000100 or a permalink)", thread)
+}
+
+// InThread posts a message as a reply to the thread with parent timestamp ts,
+// optionally also showing it in the channel
+func InThread(ts string, alsoToChannel bool) MessageOption {
+	if alsoToChannel {
+		return MessageOption{apply: slack.MsgOptionCompose(slack.MsgOptionTS(ts), slack.MsgOptionBroadcast())}
+	}
+	return MessageOption{apply: slack.MsgOptionTS(ts)}
+}
@@ -0,0 +1,32 @@
+package slack
+
+import "testing"
+
+func TestParseThread(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantChannel string
+		wantTS      string
+		wantErr     bool
+	}{
+		{name: "timestamp", input: "1736931600.000100", wantTS: "1736931600.000100"},
+		{name: "permalink", input: "https://acme.slack.com/archives/C123ABC/p1736931600000100", wantChannel: "C123ABC", wantTS: "1736931600.000100"},
+		{name: "reply permalink uses parent", input: "https://acme.slack.com/archives/C123ABC/p1736931700000200?thread_ts=1736931600.000100&cid=C123ABC", wantChannel: "C123ABC", wantTS: "1736931600.000100"},
+		{name: "surrounding space", input: "  1736931600.000100 ", wantTS: "1736931600.000100"},
+		{name: "short timestamp", input: "1736931600.1", wantErr: true},
+		{name: "not a permalink", input: "https://example.com/archives/general", wantErr: true},
+		{name: "garbage", input: "thread", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, ts, err := ParseThread(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThread() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if channel != tt.wantChannel || ts != tt.wantTS {
+				t.Errorf("ParseThread() = %q, %q; want %q, %q", channel, ts, tt.wantChannel, tt.wantTS)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package slack
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CallStats aggregates latency for one category of API call
+type CallStats struct {
+	Calls int
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Average returns the mean latency per call
+func (s CallStats) Average() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Calls)
+}
+
+// Timings records time spent per Slack API method
+type Timings struct {
+	mu    sync.Mutex
+	stats map[string]*CallStats
+}
+
+// NewTimings creates an empty timings recorder
+func NewTimings() *Timings {
+	return &Timings{stats: make(map[string]*CallStats)}
+}
+
+// Record adds a single call's duration to the given method's stats
+func (t *Timings) Record(method string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stats[method]
+	if !ok {
+		s = &CallStats{}
+		t.stats[method] = s
+	}
+	s.Calls++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+}
+
+// Stats returns a copy of the stats for each recorded method
+func (t *Timings) Stats() map[string]CallStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]CallStats, len(t.stats))
+	for method, s := range t.stats {
+		out[method] = *s
+	}
+	return out
+}
+
+// Report writes a per-method summary table sorted by total time spent
+func (t *Timings) Report(w io.Writer) {
+	stats := t.Stats()
+	methods := make([]string, 0, len(stats))
+	for method := range stats {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return stats[methods[i]].Total > stats[methods[j]].Total
+	})
+
+	fmt.Fprintf(w, "%-28s %6s %10s %10s %10s\n", "API CALL", "CALLS", "TOTAL", "AVG", "MAX")
+	for _, method := range methods {
+		s := stats[method]
+		fmt.Fprintf(w, "%-28s %6d %10s %10s %10s\n", method, s.Calls,
+			s.Total.Round(time.Millisecond), s.Average().Round(time.Millisecond), s.Max.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,104 @@
+package slack
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimings_Record(t *testing.T) {
+	timings := NewTimings()
+	timings.Record("chat.scheduleMessage", 10*time.Millisecond)
+	timings.Record("chat.scheduleMessage", 30*time.Millisecond)
+	timings.Record("conversations.list", 5*time.Millisecond)
+
+	stats := timings.Stats()
+	s := stats["chat.scheduleMessage"]
+	if s.Calls != 2 {
+		t.Errorf("Calls = %d, want 2", s.Calls)
+	}
+	if s.Total != 40*time.Millisecond {
+		t.Errorf("Total = %v, want 40ms", s.Total)
+	}
+	if s.Max != 30*time.Millisecond {
+		t.Errorf("Max = %v, want 30ms", s.Max)
+	}
+	if s.Average() != 20*time.Millisecond {
+		t.Errorf("Average() = %v, want 20ms", s.Average())
+	}
+	if (CallStats{}).Average() != 0 {
+		t.Error("Average() of empty stats should be 0")
+	}
+}
+
+func TestTimings_Report(t *testing.T) {
+	timings := NewTimings()
+	timings.Record("conversations.list", 5*time.Millisecond)
+	timings.Record("chat.scheduleMessage", 50*time.Millisecond)
+
+	var buf bytes.Buffer
+	timings.Report(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d lines:\n%s", len(lines), buf.String())
+	}
+	// Sorted by total time, slowest first
+	if !strings.HasPrefix(lines[1], "chat.scheduleMessage") {
+		t.Errorf("first row = %q, want chat.scheduleMessage", lines[1])
+	}
+}
+
+// newMockSlackServer serves canned successful responses for the API methods the client uses
+func newMockSlackServer(tb testing.TB) *httptest.Server {
+	tb.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chat.scheduleMessage":
+			w.Write([]byte(`{"ok":true,"channel":"C123","scheduled_message_id":"Q123","post_at":"1736931600"}`))
+		case "/chat.scheduledMessages.list":
+			w.Write([]byte(`{"ok":true,"scheduled_messages":[]}`))
+		case "/conversations.open":
+			w.Write([]byte(`{"ok":true,"channel":{"id":"D123"}}`))
+		case "/users.info":
+			w.Write([]byte(`{"ok":true,"user":{"id":"U123","name":"alice","tz":"America/New_York"}}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	tb.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_EnableTimings_MockServer(t *testing.T) {
+	server := newMockSlackServer(t)
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	timings := client.EnableTimings()
+
+	if _, err := client.ListScheduledMessages(""); err != nil {
+		t.Fatalf("ListScheduledMessages() error = %v", err)
+	}
+
+	if got := timings.Stats()["chat.scheduledMessages.list"].Calls; got != 1 {
+		t.Errorf("recorded %d list calls, want 1", got)
+	}
+}
+
+// BenchmarkScheduleMessage_MockServer measures client-side scheduling throughput
+// against a local mock of the Slack API
+func BenchmarkScheduleMessage_MockServer(b *testing.B) {
+	server := newMockSlackServer(b)
+	client := NewClientWithAPIURL("fake-token", server.URL+"/")
+	postAt := time.Now().Add(time.Hour)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ScheduleMessage("C123", "benchmark", postAt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// secretParams are request parameters never written to the trace
+var secretParams = map[string]bool{
+	"token": true, "client_secret": true, "code": true, "refresh_token": true,
+}
+
+// maxTraceValue caps how much of a single parameter (usually message text) the
+// trace shows
+const maxTraceValue = 200
+
+// TraceTransport is an http.RoundTripper that logs every Slack API request it
+// carries at debug level: the API method, its parameters with secrets redacted,
+// the HTTP status, Slack's ok/error/warning fields and the latency. It's meant
+// for diagnosing calls that "succeed" without the expected result.
+type TraceTransport struct {
+	// Base carries the requests (http.DefaultTransport if nil)
+	Base http.RoundTripper
+	Log  *slog.Logger
+}
+
+// NewTracingClient creates a Slack client like NewClient that traces each API
+// request to log
+func NewTracingClient(token string, log *slog.Logger) *Client {
+	return newClient(token, "", &http.Client{Transport: &TraceTransport{Log: log}})
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	params := traceParams(req)
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	latency := time.Since(start)
+
+	attrs := []slog.Attr{
+		slog.String("method", path.Base(req.URL.Path)),
+		slog.String("params", params),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()), slog.Duration("latency", latency))
+		t.Log.LogAttrs(req.Context(), slog.LevelDebug, "Slack API call failed", attrs...)
+		return resp, err
+	}
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	attrs = append(attrs, traceResult(resp)...)
+	attrs = append(attrs, slog.Duration("latency", latency))
+	t.Log.LogAttrs(req.Context(), slog.LevelDebug, "Slack API call", attrs...)
+	return resp, nil
+}
+
+// traceParams returns a request's parameters, from its query string and form or
+// JSON body, encoded as a query string with secrets redacted. The body is put
+// back for sending.
+func traceParams(req *http.Request) string {
+	values := url.Values{}
+	for k, vs := range req.URL.Query() {
+		values[k] = vs
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			addBodyParams(values, req.Header.Get("Content-Type"), body)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range values[k] {
+			if secretParams[k] {
+				v = "REDACTED"
+			} else if len(v) > maxTraceValue {
+				v = v[:maxTraceValue] + "..."
+			}
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// addBodyParams adds the parameters of a form or JSON request body to values
+func addBodyParams(values url.Values, contentType string, body []byte) {
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return
+		}
+		for k, vs := range form {
+			values[k] = append(values[k], vs...)
+		}
+	case strings.HasPrefix(contentType, "application/json"):
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return
+		}
+		for k, raw := range fields {
+			var s string
+			if json.Unmarshal(raw, &s) != nil {
+				s = string(raw)
+			}
+			values.Add(k, s)
+		}
+	}
+}
+
+// traceResult reads Slack's verdict from a response body, putting the body back
+// for the caller
+func traceResult(resp *http.Response) []slog.Attr {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return []slog.Attr{slog.String("read_error", err.Error())}
+	}
+
+	var result struct {
+		OK       *bool  `json:"ok"`
+		Error    string `json:"error"`
+		Warning  string `json:"warning"`
+		Metadata struct {
+			Warnings []string `json:"warnings"`
+		} `json:"response_metadata"`
+	}
+	if json.Unmarshal(body, &result) != nil || result.OK == nil {
+		return nil
+	}
+	attrs := []slog.Attr{slog.Bool("ok", *result.OK)}
+	if result.Error != "" {
+		attrs = append(attrs, slog.String("slack_error", result.Error))
+	}
+	warnings := result.Metadata.Warnings
+	if result.Warning != "" && len(warnings) == 0 {
+		warnings = strings.Split(result.Warning, ",")
+	}
+	if len(warnings) > 0 {
+		attrs = append(attrs, slog.String("warnings", strings.Join(warnings, ",")))
+	}
+	return attrs
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// AuditFileName is the audit log inside StateDir
+const AuditFileName = "audit.jsonl"
+
+// AuditLog is an append-only record of changes made in Slack, one JSON entry per
+// line
+type AuditLog struct {
+	path string
+}
+
+// AuditFilter selects audit entries; zero fields match everything
+type AuditFilter struct {
+	Since   time.Time
+	Until   time.Time
+	Command string
+}
+
+// DefaultAuditPath returns audit.jsonl in StateDir
+func DefaultAuditPath() (string, error) {
+	return defaultPath(StateDir, AuditFileName)
+}
+
+// OpenAudit returns the audit log at path; the file is created on first write
+func OpenAudit(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record appends an entry, filling in the local user if not set
+func (a *AuditLog) Record(entry types.AuditEntry) error {
+	if entry.User == "" {
+		if u, err := user.Current(); err == nil {
+			entry.User = u.Username
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Entries returns the entries matching filter, oldest first. A missing log has
+// no entries.
+func (a *AuditLog) Entries(filter AuditFilter) ([]types.AuditEntry, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []types.AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry types.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log %s:%d: %w", a.path, line, err)
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+func (f AuditFilter) matches(entry types.AuditEntry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Time.Before(f.Until) {
+		return false
+	}
+	return f.Command == "" || entry.Command == f.Command
+}
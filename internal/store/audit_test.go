@@ -0,0 +1,74 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestAuditLog_RecordAndEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DirName, AuditFileName)
+	log := OpenAudit(path)
+
+	if entries, err := log.Entries(AuditFilter{}); err != nil || len(entries) != 0 {
+		t.Fatalf("Entries() on missing log = %v, %v", entries, err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 9, 0, 0, 0, time.UTC) }
+	records := []types.AuditEntry{
+		{Time: day(1), Command: "schedule", Action: "schedule", Channel: "C1", SlackID: "Q1"},
+		{Time: day(2), Command: "delete", Action: "delete", Channel: "C1", SlackID: "Q1", User: "alex"},
+		{Time: day(3), Command: "schedule", Action: "schedule", Channel: "C2", SlackID: "Q2"},
+	}
+	for _, e := range records {
+		if err := log.Record(e); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filter  AuditFilter
+		wantIDs []string
+	}{
+		{"all", AuditFilter{}, []string{"Q1", "Q1", "Q2"}},
+		{"by command", AuditFilter{Command: "delete"}, []string{"Q1"}},
+		{"since", AuditFilter{Since: day(2)}, []string{"Q1", "Q2"}},
+		{"until", AuditFilter{Until: day(2)}, []string{"Q1"}},
+		{"command and range", AuditFilter{Command: "schedule", Since: day(2)}, []string{"Q2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.Entries(tt.filter)
+			if err != nil {
+				t.Fatalf("Entries() error = %v", err)
+			}
+			if len(entries) != len(tt.wantIDs) {
+				t.Fatalf("Entries() returned %d, want %d", len(entries), len(tt.wantIDs))
+			}
+			for i, e := range entries {
+				if e.SlackID != tt.wantIDs[i] {
+					t.Errorf("entry %d = %s, want %s", i, e.SlackID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+
+	entries, _ := log.Entries(AuditFilter{Command: "delete"})
+	if entries[0].User != "alex" {
+		t.Errorf("explicit user = %q, want alex", entries[0].User)
+	}
+}
+
+func TestAuditLog_InvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), AuditFileName)
+	if err := os.WriteFile(path, []byte("{\"action\":\"send\"}\nnot json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenAudit(path).Entries(AuditFilter{}); err == nil {
+		t.Error("Entries() expected error for invalid line")
+	}
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// MaxHistory is how many deleted batches the history keeps
+const MaxHistory = 20
+
+// History records deleted scheduled messages so deletes can be undone. Slack
+// deletion is irreversible, so the only way back is re-creating the messages from
+// the details saved here.
+type History struct {
+	path    string
+	batches []types.DeletedBatch
+}
+
+type historyFile struct {
+	Batches []types.DeletedBatch `json:"batches"`
+}
+
+// DefaultHistoryPath returns history.json in StateDir
+func DefaultHistoryPath() (string, error) {
+	return defaultPath(StateDir, HistoryFileName)
+}
+
+// OpenHistory loads the history at path. A missing file is an empty history.
+func OpenHistory(path string) (*History, error) {
+	h := &History{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var file historyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	h.batches = file.Batches
+	return h, nil
+}
+
+// Save writes the history back to disk, replacing the file atomically
+func (h *History) Save() error {
+	data, err := json.MarshalIndent(historyFile{Batches: h.batches}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(h.path, data, "history")
+}
+
+// Record adds a deleted batch, dropping the oldest beyond MaxHistory. Empty
+// batches are ignored.
+func (h *History) Record(batch types.DeletedBatch) {
+	if len(batch.Messages) == 0 {
+		return
+	}
+	h.batches = append(h.batches, batch)
+	if len(h.batches) > MaxHistory {
+		h.batches = h.batches[len(h.batches)-MaxHistory:]
+	}
+}
+
+// Last returns the most recently deleted batch
+func (h *History) Last() (types.DeletedBatch, bool) {
+	if len(h.batches) == 0 {
+		return types.DeletedBatch{}, false
+	}
+	return h.batches[len(h.batches)-1], true
+}
+
+// Pop removes and returns the most recently deleted batch
+func (h *History) Pop() (types.DeletedBatch, bool) {
+	batch, ok := h.Last()
+	if ok {
+		h.batches = h.batches[:len(h.batches)-1]
+	}
+	return batch, ok
+}
+
+// Len returns the number of batches recorded
+func (h *History) Len() int {
+	return len(h.batches)
+}
+
+// TrashedMessage is a deleted message that can still be restored, identified by
+// the Slack ID it had before deletion
+type TrashedMessage struct {
+	ID        string
+	DeletedAt time.Time
+	types.Occurrence
+
+	// Options the message was sent with
+	Options types.SendOptions
+}
+
+// Trash lists every message in the history, most recently deleted first
+func (h *History) Trash() []TrashedMessage {
+	var trash []TrashedMessage
+	for i := len(h.batches) - 1; i >= 0; i-- {
+		batch := h.batches[i]
+		for _, msg := range batch.Messages {
+			trash = append(trash, TrashedMessage{ID: msg.ScheduledID, DeletedAt: batch.DeletedAt, Occurrence: msg, Options: batch.Options[msg.ScheduledID]})
+		}
+	}
+	return trash
+}
+
+// Find returns the most recently deleted message with the given ID
+func (h *History) Find(id string) (TrashedMessage, bool) {
+	for _, msg := range h.Trash() {
+		if msg.ID == id {
+			return msg, true
+		}
+	}
+	return TrashedMessage{}, false
+}
+
+// Remove drops the most recently deleted message with the given ID, and its batch
+// if that leaves it empty, reporting whether it was found
+func (h *History) Remove(id string) bool {
+	for i := len(h.batches) - 1; i >= 0; i-- {
+		messages := h.batches[i].Messages
+		for j := range messages {
+			if messages[j].ScheduledID != id {
+				continue
+			}
+			h.batches[i].Messages = append(messages[:j:j], messages[j+1:]...)
+			delete(h.batches[i].Options, id)
+			if len(h.batches[i].Messages) == 0 {
+				h.batches = append(h.batches[:i:i], h.batches[i+1:]...)
+			}
+			return true
+		}
+	}
+	return false
+}
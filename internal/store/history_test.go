@@ -0,0 +1,89 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestHistory_RecordSaveAndPop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DirName, HistoryFileName)
+	h, err := OpenHistory(path)
+	if err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	if _, ok := h.Last(); ok {
+		t.Fatal("Last() on empty history returned a batch")
+	}
+
+	postAt := time.Date(2025, 1, 17, 14, 0, 0, 0, time.UTC)
+	h.Record(types.DeletedBatch{DeletedAt: postAt, Messages: []types.Occurrence{{Channel: "C1", Message: "first", PostAt: postAt}}})
+	h.Record(types.DeletedBatch{DeletedAt: postAt})
+	h.Record(types.DeletedBatch{DeletedAt: postAt, Messages: []types.Occurrence{{Channel: "C1", Message: "second", PostAt: postAt}}})
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened, err := OpenHistory(path)
+	if err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 (empty batch ignored)", reopened.Len())
+	}
+	batch, ok := reopened.Pop()
+	if !ok || batch.Messages[0].Message != "second" || !batch.Messages[0].PostAt.Equal(postAt) {
+		t.Errorf("Pop() = %+v, want the second batch", batch)
+	}
+	if batch, _ := reopened.Last(); batch.Messages[0].Message != "first" {
+		t.Errorf("Last() after Pop = %+v, want the first batch", batch)
+	}
+}
+
+func TestHistory_KeepsMostRecent(t *testing.T) {
+	h, _ := OpenHistory(filepath.Join(t.TempDir(), HistoryFileName))
+	for i := 0; i < MaxHistory+5; i++ {
+		h.Record(types.DeletedBatch{Messages: []types.Occurrence{{ScheduledID: string(rune('A' + i))}}})
+	}
+	if h.Len() != MaxHistory {
+		t.Errorf("Len() = %d, want %d", h.Len(), MaxHistory)
+	}
+	if batch, _ := h.Last(); batch.Messages[0].ScheduledID != string(rune('A'+MaxHistory+4)) {
+		t.Errorf("Last() = %+v, want the newest batch", batch)
+	}
+}
+
+func TestHistory_TrashAndRemove(t *testing.T) {
+	h, _ := OpenHistory(filepath.Join(t.TempDir(), HistoryFileName))
+	first := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	h.Record(types.DeletedBatch{DeletedAt: first, Messages: []types.Occurrence{{ScheduledID: "Q1"}, {ScheduledID: "Q2"}}})
+	h.Record(types.DeletedBatch{DeletedAt: second, Messages: []types.Occurrence{{ScheduledID: "Q3"}}})
+
+	trash := h.Trash()
+	var ids []string
+	for _, m := range trash {
+		ids = append(ids, m.ID)
+	}
+	if len(ids) != 3 || ids[0] != "Q3" || ids[1] != "Q1" || ids[2] != "Q2" {
+		t.Errorf("Trash() IDs = %v, want [Q3 Q1 Q2]", ids)
+	}
+	if msg, ok := h.Find("Q2"); !ok || !msg.DeletedAt.Equal(first) {
+		t.Errorf("Find(Q2) = %+v, %v", msg, ok)
+	}
+
+	if !h.Remove("Q3") {
+		t.Fatal("Remove(Q3) = false")
+	}
+	if h.Len() != 1 {
+		t.Errorf("Len() = %d after emptying a batch, want 1", h.Len())
+	}
+	if !h.Remove("Q1") || h.Remove("Q1") {
+		t.Error("Remove(Q1) should succeed once")
+	}
+	if trash := h.Trash(); len(trash) != 1 || trash[0].ID != "Q2" {
+		t.Errorf("Trash() = %+v, want only Q2", trash)
+	}
+}
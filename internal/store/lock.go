@@ -0,0 +1,54 @@
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockTimeout is how long Lock waits for another process to finish with the store
+var lockTimeout = 30 * time.Second
+
+const (
+	// staleLockAge is how old a lock file must be before it's taken to be left
+	// behind by a process that died holding it
+	staleLockAge = 10 * time.Minute
+
+	lockPollInterval = 50 * time.Millisecond
+)
+
+// Lock takes the lock file beside the store at path, waiting up to lockTimeout
+// while another process holds it, and returns the function that releases it.
+// Processes changing the store (the daemon, serve mode, the CLI) hold it from
+// Open to Save, so none overwrites changes another made in between.
+func Lock(path string) (func(), error) {
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock series store: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			slog.Warn(fmt.Sprintf("Warning: removing stale lock %s", lockPath), "path", lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("series store is locked by another process (remove %s if none is running)", lockPath)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+// DefaultResumePath returns resume.json in StateDir
+func DefaultResumePath() (string, error) {
+	return defaultPath(StateDir, ResumeFileName)
+}
+
+// SaveResume writes the state of a partly failed schedule to path, replacing any
+// earlier one
+func SaveResume(path string, state types.ResumeState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data, "resume file")
+}
+
+// LoadResume reads the resume file at path, reporting false if there is none
+func LoadResume(path string) (types.ResumeState, bool, error) {
+	var state types.ResumeState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, false, nil
+		}
+		return state, false, fmt.Errorf("failed to read resume file: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false, fmt.Errorf("failed to parse resume file %s: %w", path, err)
+	}
+	return state, true, nil
+}
+
+// ClearResume removes the resume file once there is nothing left to retry
+func ClearResume(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove resume file: %w", err)
+	}
+	return nil
+}
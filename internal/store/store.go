@@ -0,0 +1,198 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+const (
+	// DirName is the directory in the user's home everything was kept in before
+	// the move to the XDG base directories; files still there are moved on first use
+	DirName = ".slack-scheduler"
+
+	// FileName is the series database inside StateDir
+	FileName = "series.json"
+
+	// HistoryFileName is the deletion history inside StateDir
+	HistoryFileName = "history.json"
+
+	// CacheDirName is the Slack API response cache inside DirName; it now lives
+	// directly in CacheDir
+	CacheDirName = "cache"
+
+	// ResumeFileName records the last schedule that failed partway, inside StateDir
+	ResumeFileName = "resume.json"
+)
+
+// Store is a local record of every series created, keyed by series name.
+// It is a single JSON file, loaded in full by Open and written back by Save.
+type Store struct {
+	path   string
+	series map[string]types.Series
+}
+
+type storeFile struct {
+	Series []types.Series `json:"series"`
+}
+
+// DefaultPath returns series.json in StateDir
+func DefaultPath() (string, error) {
+	return defaultPath(StateDir, FileName)
+}
+
+// DefaultCacheDir returns CacheDir, after moving the cache there from
+// ~/.slack-scheduler/cache
+func DefaultCacheDir() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return Relocate(legacyPath(CacheDirName), dir), nil
+}
+
+// Open loads the store at path. A missing file is an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, series: make(map[string]types.Series)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read series store: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse series store %s: %w", path, err)
+	}
+	for _, series := range file.Series {
+		s.series[series.Name] = series
+	}
+	return s, nil
+}
+
+// Path returns the file the store is saved to
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Save writes the store back to disk, replacing the file atomically
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(storeFile{Series: s.List()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(s.path, data, "series store")
+}
+
+// writeAtomic writes data to path via a temporary file and rename, creating the
+// state directory if needed. what names the file in errors.
+func writeAtomic(path string, data []byte, what string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	return nil
+}
+
+// Put adds a series, replacing any existing series with the same name
+func (s *Store) Put(series types.Series) error {
+	if series.Name == "" {
+		return fmt.Errorf("series name is required")
+	}
+	s.series[series.Name] = series
+	return nil
+}
+
+// Get returns the series with the given name
+func (s *Store) Get(name string) (types.Series, bool) {
+	series, ok := s.series[name]
+	return series, ok
+}
+
+// Delete removes a series, reporting whether it existed
+func (s *Store) Delete(name string) bool {
+	if _, ok := s.series[name]; !ok {
+		return false
+	}
+	delete(s.series, name)
+	return true
+}
+
+// List returns all series sorted by name
+func (s *Store) List() []types.Series {
+	list := make([]types.Series, 0, len(s.series))
+	for _, series := range s.series {
+		list = append(list, series)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// FindByScheduledID returns the series owning a Slack scheduled message ID
+func (s *Store) FindByScheduledID(id string) (types.Series, bool) {
+	for _, series := range s.series {
+		for _, occ := range series.Occurrences {
+			if occ.ScheduledID == id {
+				return series, true
+			}
+		}
+	}
+	return types.Series{}, false
+}
+
+// UniqueName returns name, or name with a numeric suffix if it is already taken
+func (s *Store) UniqueName(name string) string {
+	if _, taken := s.series[name]; !taken {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if _, taken := s.series[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+var nonSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// DefaultName derives a readable series name from a schedule, e.g.
+// "general-weekly-2025-01-17"
+func DefaultName(config *types.ScheduleConfig) string {
+	parts := []string{config.Channel}
+	if config.Interval != "" && config.Interval != types.IntervalNone {
+		parts = append(parts, string(config.Interval))
+	}
+	if config.StartDate != "" {
+		parts = append(parts, config.StartDate)
+	}
+
+	name := Slug(strings.Join(parts, "-"))
+	if name == "" {
+		return "series"
+	}
+	return name
+}
+
+// Slug lowercases s and joins its letters and digits with dashes, e.g. "#Eng Team" -> "eng-team"
+func Slug(s string) string {
+	return strings.Trim(nonSlugPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
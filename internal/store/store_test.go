@@ -0,0 +1,179 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/daggerpov/slack-recurring-messages-scheduler/internal/types"
+)
+
+func TestOpen_MissingFile(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "missing", FileName))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := len(s.List()); got != 0 {
+		t.Errorf("List() returned %d series, want 0", got)
+	}
+}
+
+func TestOpen_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil {
+		t.Error("Open() expected error for invalid JSON")
+	}
+}
+
+func TestStore_SaveAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DirName, FileName)
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	postAt := time.Date(2025, 1, 17, 14, 0, 0, 0, time.UTC)
+	series := types.Series{
+		Name:      "general-weekly-2025-01-17",
+		CreatedAt: postAt.Add(-24 * time.Hour),
+		Config:    types.ScheduleConfig{Message: "Timesheets!", Channel: "general", Interval: types.IntervalWeekly},
+		Occurrences: []types.Occurrence{
+			{Channel: "C123", Message: "Timesheets!", PostAt: postAt, ScheduledID: "Q1"},
+		},
+	}
+	if err := s.Put(series); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("store file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("store file permissions = %o, want 600", perm)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, ok := reopened.Get(series.Name)
+	if !ok {
+		t.Fatalf("Get(%q) not found after reopening", series.Name)
+	}
+	if got.Config.Message != "Timesheets!" || len(got.Occurrences) != 1 || !got.Occurrences[0].PostAt.Equal(postAt) {
+		t.Errorf("reopened series = %+v, want %+v", got, series)
+	}
+}
+
+func TestStore_PutRequiresName(t *testing.T) {
+	s, _ := Open(filepath.Join(t.TempDir(), FileName))
+	if err := s.Put(types.Series{}); err == nil {
+		t.Error("Put() expected error for unnamed series")
+	}
+}
+
+func TestStore_DeleteAndList(t *testing.T) {
+	s, _ := Open(filepath.Join(t.TempDir(), FileName))
+	for _, name := range []string{"b", "a", "c"} {
+		s.Put(types.Series{Name: name})
+	}
+
+	if !s.Delete("b") {
+		t.Error("Delete(b) = false, want true")
+	}
+	if s.Delete("b") {
+		t.Error("Delete(b) twice = true, want false")
+	}
+
+	list := s.List()
+	if len(list) != 2 || list[0].Name != "a" || list[1].Name != "c" {
+		t.Errorf("List() = %v, want [a c]", list)
+	}
+}
+
+func TestStore_FindByScheduledID(t *testing.T) {
+	s, _ := Open(filepath.Join(t.TempDir(), FileName))
+	s.Put(types.Series{Name: "standup", Occurrences: []types.Occurrence{{ScheduledID: "Q1"}, {ScheduledID: "Q2"}}})
+	s.Put(types.Series{Name: "retro", Occurrences: []types.Occurrence{{ScheduledID: "Q3"}}})
+
+	if series, ok := s.FindByScheduledID("Q2"); !ok || series.Name != "standup" {
+		t.Errorf("FindByScheduledID(Q2) = %q, %v, want standup", series.Name, ok)
+	}
+	if _, ok := s.FindByScheduledID("Q9"); ok {
+		t.Error("FindByScheduledID(Q9) found a series, want none")
+	}
+}
+
+func TestStore_UniqueName(t *testing.T) {
+	s, _ := Open(filepath.Join(t.TempDir(), FileName))
+	if got := s.UniqueName("standup"); got != "standup" {
+		t.Errorf("UniqueName() = %q, want standup", got)
+	}
+
+	s.Put(types.Series{Name: "standup"})
+	s.Put(types.Series{Name: "standup-2"})
+	if got := s.UniqueName("standup"); got != "standup-3" {
+		t.Errorf("UniqueName() = %q, want standup-3", got)
+	}
+}
+
+func TestDefaultName(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *types.ScheduleConfig
+		want   string
+	}{
+		{"weekly", &types.ScheduleConfig{Channel: "#general", Interval: types.IntervalWeekly, StartDate: "2025-01-17"}, "general-weekly-2025-01-17"},
+		{"one-off", &types.ScheduleConfig{Channel: "Dev Team", Interval: types.IntervalNone, StartDate: "2025-01-17"}, "dev-team-2025-01-17"},
+		{"user target", &types.ScheduleConfig{Channel: "@alice", Interval: types.IntervalDaily}, "alice-daily"},
+		{"nothing to go on", &types.ScheduleConfig{}, "series"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultName(tt.config); got != tt.want {
+				t.Errorf("DefaultName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResumeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ResumeFileName)
+	if _, ok, err := LoadResume(path); ok || err != nil {
+		t.Fatalf("LoadResume() of a missing file = %v, %v; want false, nil", ok, err)
+	}
+
+	postAt := time.Date(2025, 1, 8, 9, 0, 0, 0, time.UTC)
+	state := types.ResumeState{
+		Series:  types.Series{Name: "standup"},
+		Pending: []types.PendingOccurrence{{PostAt: postAt, Index: 3, Total: 4}},
+		Error:   "invalid_time",
+	}
+	if err := SaveResume(path, state); err != nil {
+		t.Fatalf("SaveResume() error = %v", err)
+	}
+	loaded, ok, err := LoadResume(path)
+	if err != nil || !ok {
+		t.Fatalf("LoadResume() = %v, %v", ok, err)
+	}
+	if loaded.Series.Name != "standup" || len(loaded.Pending) != 1 || !loaded.Pending[0].PostAt.Equal(postAt) {
+		t.Errorf("LoadResume() = %+v", loaded)
+	}
+
+	if err := ClearResume(path); err != nil {
+		t.Fatalf("ClearResume() error = %v", err)
+	}
+	if _, ok, _ := LoadResume(path); ok {
+		t.Error("resume file still present after ClearResume()")
+	}
+}
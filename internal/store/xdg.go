@@ -0,0 +1,125 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// AppDirName is the scheduler's directory under each XDG base directory
+const AppDirName = "slack-scheduler"
+
+// ConfigDir returns $XDG_CONFIG_HOME/slack-scheduler (~/.config/slack-scheduler
+// by default), where credentials and config.yaml are kept
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// StateDir returns $XDG_STATE_HOME/slack-scheduler
+// (~/.local/state/slack-scheduler by default), where the series store, history,
+// resume state and audit log are kept
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// CacheDir returns $XDG_CACHE_HOME/slack-scheduler (~/.cache/slack-scheduler by
+// default), where Slack API responses are cached
+func CacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// xdgDir returns the scheduler's directory under the base directory named by
+// env, or under fallback in the home directory when env is unset. The spec says
+// relative values are invalid, so they are ignored too.
+func xdgDir(env, fallback string) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, AppDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, fallback, AppDirName), nil
+}
+
+// legacyPath returns name inside the legacy ~/.slack-scheduler directory, or ""
+// if there is no home directory to look in
+func legacyPath(name string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, DirName, name)
+}
+
+// defaultPath joins name onto the base directory dir returns, after moving the
+// file of the same name there from ~/.slack-scheduler
+func defaultPath(dir func() (string, error), name string) (string, error) {
+	base, err := dir()
+	if err != nil {
+		return "", err
+	}
+	return Relocate(legacyPath(name), filepath.Join(base, name)), nil
+}
+
+// Relocate moves the file or directory at legacy to path, if only legacy exists,
+// and returns path. If the move fails, a warning is logged and legacy is returned
+// so it keeps being used. The legacy directory is removed once it's empty.
+func Relocate(legacy, path string) string {
+	if legacy == "" {
+		return path
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return path
+	}
+	info, err := os.Lstat(legacy)
+	if err != nil {
+		return path
+	}
+
+	if err := move(legacy, path, info); err != nil {
+		slog.Warn(fmt.Sprintf("Warning: could not move %s to %s: %v", legacy, path, err), "from", legacy, "to", path, "error", err)
+		return legacy
+	}
+	slog.Info(fmt.Sprintf("Moved %s to %s", legacy, path), "from", legacy, "to", path)
+
+	// Only succeeds once everything has moved out
+	if filepath.Base(filepath.Dir(legacy)) == DirName {
+		os.Remove(filepath.Dir(legacy))
+	}
+	return path
+}
+
+// move renames from to to, copying files across file systems where renaming
+// can't. Directories (the cache) are only renamed.
+func move(from, to string, info os.FileInfo) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+		return err
+	}
+	err := os.Rename(from, to)
+	if err == nil || info.IsDir() {
+		return err
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
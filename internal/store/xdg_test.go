@@ -0,0 +1,98 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestXDGDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "relative/state")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	tests := []struct {
+		name string
+		dir  func() (string, error)
+		want string
+	}{
+		{"config from the environment", ConfigDir, filepath.Join("/xdg/config", AppDirName)},
+		{"relative state ignored", StateDir, filepath.Join(home, ".local", "state", AppDirName)},
+		{"cache default", CacheDir, filepath.Join(home, ".cache", AppDirName)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dir()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("dir = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultPaths_MigrateLegacyDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+
+	legacy := filepath.Join(home, DirName)
+	if err := os.MkdirAll(filepath.Join(legacy, CacheDirName), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{FileName, filepath.Join(CacheDirName, "channels.json")} {
+		if err := os.WriteFile(filepath.Join(legacy, name), []byte(`{}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "state", AppDirName, FileName); path != want {
+		t.Errorf("DefaultPath() = %s, want %s", path, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("series store not moved: %v", err)
+	}
+
+	cache, err := DefaultCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "channels.json")); err != nil {
+		t.Errorf("cache not moved: %v", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("empty legacy directory left behind")
+	}
+
+	// Nothing left to move: the new path is used as is
+	if again, err := DefaultPath(); err != nil || again != path {
+		t.Errorf("DefaultPath() again = %s, %v", again, err)
+	}
+}
+
+func TestRelocate_KeepsExisting(t *testing.T) {
+	dir := t.TempDir()
+	legacy, path := filepath.Join(dir, "old.json"), filepath.Join(dir, "new", "new.json")
+	os.WriteFile(legacy, []byte("old"), 0600)
+	os.MkdirAll(filepath.Dir(path), 0700)
+	os.WriteFile(path, []byte("new"), 0600)
+
+	if got := Relocate(legacy, path); got != path {
+		t.Errorf("Relocate() = %s, want %s", got, path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("existing file overwritten with %q", data)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Error("legacy file removed although it wasn't moved")
+	}
+}
@@ -1,8 +1,13 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Interval represents the repeat interval type
@@ -10,13 +15,14 @@ type Interval string
 
 const (
 	IntervalNone    Interval = "none"
+	IntervalHourly  Interval = "hourly"
 	IntervalDaily   Interval = "daily"
 	IntervalWeekly  Interval = "weekly"
 	IntervalMonthly Interval = "monthly"
 )
 
 // ValidIntervals for validation
-var ValidIntervals = []Interval{IntervalNone, IntervalDaily, IntervalWeekly, IntervalMonthly}
+var ValidIntervals = []Interval{IntervalNone, IntervalHourly, IntervalDaily, IntervalWeekly, IntervalMonthly}
 
 func (i Interval) IsValid() bool {
 	for _, v := range ValidIntervals {
@@ -27,6 +33,31 @@ func (i Interval) IsValid() bool {
 	return false
 }
 
+// WeekendPolicy controls what happens to occurrences that land on a Saturday or Sunday
+type WeekendPolicy string
+
+const (
+	WeekendKeep     WeekendPolicy = ""         // post on weekends as usual
+	WeekendSkip     WeekendPolicy = "skip"     // drop weekend occurrences
+	WeekendPrevious WeekendPolicy = "previous" // move to the preceding Friday
+	WeekendNext     WeekendPolicy = "next"     // move to the following Monday
+)
+
+// ValidWeekendPolicies for validation
+var ValidWeekendPolicies = []WeekendPolicy{WeekendSkip, WeekendPrevious, WeekendNext}
+
+func (p WeekendPolicy) IsValid() bool {
+	if p == WeekendKeep {
+		return true
+	}
+	for _, v := range ValidWeekendPolicies {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
+
 // DayOfWeek represents days of the week
 type DayOfWeek string
 
@@ -72,6 +103,12 @@ func ParseDayOfWeek(s string) (DayOfWeek, error) {
 	return "", fmt.Errorf("invalid day of week: %s (use: mon,tue,wed,thu,fri,sat,sun)", s)
 }
 
+// IsValid reports whether d is one of the full day names
+func (d DayOfWeek) IsValid() bool {
+	_, ok := DayFullNames[string(d)]
+	return ok
+}
+
 func ParseDaysOfWeek(s string) ([]DayOfWeek, error) {
 	if s == "" {
 		return nil, nil
@@ -88,14 +125,152 @@ func ParseDaysOfWeek(s string) ([]DayOfWeek, error) {
 	return days, nil
 }
 
+// MonthDayLast selects the last day of each month for monthly schedules
+const MonthDayLast = "last"
+
+// ParseMonthDay parses a month day option, returning -1 for "last" and 0 when unset
+func ParseMonthDay(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if strings.EqualFold(s, MonthDayLast) {
+		return -1, nil
+	}
+	day, err := strconv.Atoi(s)
+	if err != nil || day < 1 || day > 31 {
+		return 0, fmt.Errorf("invalid month day: %s (use 1-31 or last)", s)
+	}
+	return day, nil
+}
+
+// ParseDayTimes parses a per-weekday time map such as "mon=09:00,fri=16:00"
+func ParseDayTimes(s string) (map[DayOfWeek]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	dayTimes := make(map[DayOfWeek]string)
+	for _, p := range strings.Split(s, ",") {
+		dayStr, timeStr, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid day time: %s (use day=HH:MM, e.g. mon=09:00)", p)
+		}
+		d, err := ParseDayOfWeek(strings.TrimSpace(dayStr))
+		if err != nil {
+			return nil, err
+		}
+		timeStr = strings.TrimSpace(timeStr)
+		if _, err := time.Parse("15:04", timeStr); err != nil {
+			return nil, fmt.Errorf("invalid time for %s: %s (use HH:MM, 24-hour)", dayStr, timeStr)
+		}
+		if _, dup := dayTimes[d]; dup {
+			return nil, fmt.Errorf("duplicate day in times: %s", dayStr)
+		}
+		dayTimes[d] = timeStr
+	}
+	return dayTimes, nil
+}
+
+// ParseQuietHours parses a daily window such as "22:00-07:00" (which wraps past
+// midnight) into its start and end as offsets from midnight
+func ParseQuietHours(s string) (start, end time.Duration, err error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid quiet hours: %s (use HH:MM-HH:MM, e.g. 22:00-07:00)", s)
+	}
+	var offsets [2]time.Duration
+	for i, part := range []string{from, to} {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid quiet hours: %s (use HH:MM-HH:MM, e.g. 22:00-07:00)", s)
+		}
+		offsets[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if offsets[0] == offsets[1] {
+		return 0, 0, fmt.Errorf("invalid quiet hours: %s (start and end are the same)", s)
+	}
+	return offsets[0], offsets[1], nil
+}
+
+// ParseDateList parses a comma-separated list of YYYY-MM-DD dates
+func ParseDateList(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	dates := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if _, err := time.Parse("2006-01-02", p); err != nil {
+			return nil, fmt.Errorf("invalid date: %s (use YYYY-MM-DD)", p)
+		}
+		dates = append(dates, p)
+	}
+	return dates, nil
+}
+
+// ParseUserList parses a comma-separated list of users (@name, name or user ID)
+func ParseUserList(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	users := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" || p == "@" {
+			return nil, fmt.Errorf("invalid user list: %q (empty name)", s)
+		}
+		users = append(users, p)
+	}
+	return users, nil
+}
+
+// ParseChannelList parses a comma-separated list of channels (names, #names, IDs
+// or @users), dropping duplicates
+func ParseChannelList(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	var channels []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" || p == "#" || p == "@" {
+			return nil, fmt.Errorf("invalid channel list: %q (empty channel)", s)
+		}
+		if !seen[p] {
+			seen[p] = true
+			channels = append(channels, p)
+		}
+	}
+	return channels, nil
+}
+
 // ScheduleConfig holds all scheduling configuration
 type ScheduleConfig struct {
 	// Message content (supports Slack formatting, @mentions, etc.)
 	Message string `json:"message"`
 
+	// File to read the message from, relative to the schedule file (schedule files
+	// only; replaced by its contents when the file is loaded)
+	MessageFile string `json:"message_file,omitempty"`
+
+	// Render Message as a Go text/template for each occurrence
+	Template bool `json:"template,omitempty"`
+
+	// Values available to the message template as {{.key}}
+	Data map[string]interface{} `json:"data,omitempty"`
+
 	// Channel ID or name to send to
 	Channel string `json:"channel"`
 
+	// Stable name for the series in the local store (optional; generated if empty)
+	Name string `json:"name,omitempty"`
+
+	// Group listings show the series under instead of its name, so a schedule
+	// fanned out to several channels lists as one (optional)
+	Group string `json:"group,omitempty"`
+
 	// Start date in YYYY-MM-DD format
 	StartDate string `json:"start_date"`
 
@@ -105,6 +280,17 @@ type ScheduleConfig struct {
 	// Repeat interval
 	Interval Interval `json:"interval"`
 
+	// Interval multiplier, e.g. 2 with weekly = every other week (0 or 1 = every interval)
+	Every int `json:"every,omitempty"`
+
+	// Relative delay for a one-off message, e.g. "2h30m" (optional). Takes precedence
+	// over the date, time and interval settings.
+	In string `json:"in,omitempty"`
+
+	// Anchor date in YYYY-MM-DD format (optional). Weekly schedules are aligned to
+	// whole (multiplied) weeks from this date instead of from StartDate, e.g. a sprint start.
+	Anchor string `json:"anchor,omitempty"`
+
 	// Number of times to repeat (0 = once/no repeat, -1 = infinite)
 	// If EndDate is also set, will stop at whichever comes first
 	RepeatCount int `json:"repeat_count"`
@@ -115,6 +301,465 @@ type ScheduleConfig struct {
 
 	// Specific days of week (for weekly interval)
 	Days []DayOfWeek `json:"days,omitempty"`
+
+	// Per-weekday send times (HH:MM) for weekly schedules, overriding SendTime on those
+	// days. If Days is empty, the days in this map are used.
+	DayTimes map[DayOfWeek]string `json:"day_times,omitempty"`
+
+	// Day of month for monthly interval: "1"-"31" or "last" (default: the start date's day).
+	// Days past the end of a short month are clamped to its last day.
+	MonthDay string `json:"month_day,omitempty"`
+
+	// Only post on business days (Mon-Fri). Weekend occurrences are handled
+	// according to WeekendPolicy, which defaults to skip.
+	WeekdaysOnly bool `json:"weekdays_only,omitempty"`
+
+	// What to do with weekend occurrences: skip, previous (Friday) or next (Monday)
+	WeekendPolicy WeekendPolicy `json:"weekend_policy,omitempty"`
+
+	// Dates (YYYY-MM-DD) on which no message is sent, e.g. holidays.
+	// Excluded occurrences still count towards RepeatCount.
+	ExcludeDates []string `json:"exclude_dates,omitempty"`
+
+	// Daily window in which nothing is posted, as HH:MM-HH:MM (e.g. "22:00-07:00").
+	// Occurrences falling inside it are postponed to its end.
+	QuietHours string `json:"quiet_hours,omitempty"`
+
+	// Cron expression (e.g. "0 9 * * MON-FRI"). When set, it replaces
+	// Interval, Days and SendTime; StartDate/EndDate/RepeatCount still bound it.
+	Cron string `json:"cron,omitempty"`
+
+	// RFC 5545 recurrence rule (e.g. "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"), starting at
+	// StartDate/SendTime. When set, it replaces Interval, Every and Days.
+	RRule string `json:"rrule,omitempty"`
+
+	// Messages used in turn, one per occurrence, in place of Message (e.g.
+	// alternating icebreaker questions). Extra occurrences use the first.
+	RotateMessages []string `json:"rotate_messages,omitempty"`
+
+	// People who take turns, one per occurrence, mentioned wherever the message says
+	// {{assignee}} (e.g. who runs standup this week). Names are resolved to user IDs.
+	RotateUsers []string `json:"rotate_users,omitempty"`
+
+	// Convert plain-text @name, @email and @user-group mentions into Slack mentions
+	// that notify, failing if a name doesn't match exactly one user or group
+	ResolveMentions bool `json:"resolve_mentions,omitempty"`
+
+	// Let @here, @channel and @everyone notify the channel. Without it they are
+	// escaped to plain text, so a recurring message can't page everyone by accident.
+	AllowBroadcast bool `json:"allow_broadcast,omitempty"`
+
+	// Schedule messages even when the channel already has one with the same text at
+	// the same time. Without it they are skipped, so re-running a command is safe.
+	AllowDuplicates bool `json:"allow_duplicates,omitempty"`
+
+	// Split messages over Slack's length limit into parts instead of failing.
+	// Scheduled parts follow a minute apart; sent parts reply in a thread.
+	Split bool `json:"split,omitempty"`
+
+	// Post the message exactly as typed: no mrkdwn formatting (e.g. literal
+	// asterisks) and no link or mention expansion
+	Raw bool `json:"raw,omitempty"`
+
+	// Name and emoji icon (e.g. ":rocket:") to post under instead of the token's
+	// own, giving a series its own persona (bot tokens with chat:write.customize)
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+
+	// Links to append to the message, each as "Title|https://url" or a bare URL
+	Links []string `json:"links,omitempty"`
+
+	// Slack message metadata attached to every occurrence (optional)
+	Metadata *MessageMetadata `json:"metadata,omitempty"`
+
+	// Thread to reply in: a parent message timestamp (e.g. "1736931600.000100") or
+	// its permalink (optional)
+	Thread string `json:"thread,omitempty"`
+
+	// Also post thread replies to the channel
+	AlsoToChannel bool `json:"also_to_channel,omitempty"`
+
+	// Interpret dates and send times in the DM recipient's Slack time zone instead of
+	// the local one. Channel must be a user (@name or user ID).
+	RecipientLocal bool `json:"recipient_local,omitempty"`
+}
+
+// MessageMetadata is Slack message metadata (https://api.slack.com/metadata)
+type MessageMetadata struct {
+	EventType    string                 `json:"event_type"`
+	EventPayload map[string]interface{} `json:"event_payload"`
+}
+
+// ParseMetadata builds message metadata from an event type and a JSON object payload
+func ParseMetadata(eventType, payload string) (*MessageMetadata, error) {
+	if eventType == "" {
+		if payload != "" {
+			return nil, fmt.Errorf("metadata payload requires an event type")
+		}
+		return nil, nil
+	}
+	if strings.ContainsAny(eventType, " \t\n") {
+		return nil, fmt.Errorf("invalid metadata event type: %q (must not contain whitespace)", eventType)
+	}
+
+	meta := &MessageMetadata{EventType: eventType, EventPayload: map[string]interface{}{}}
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &meta.EventPayload); err != nil {
+			return nil, fmt.Errorf("invalid metadata payload (must be a JSON object): %w", err)
+		}
+	}
+	return meta, nil
+}
+
+// OccurrenceEventType is the metadata event type of scheduled occurrences that
+// don't carry metadata of their own
+const OccurrenceEventType = "slack_scheduler_occurrence"
+
+// Metadata payload keys identifying the series and occurrence a message belongs to
+const (
+	MetadataSeries     = "scheduler_series"
+	MetadataOccurrence = "scheduler_occurrence"
+	MetadataConfigHash = "scheduler_config_hash"
+)
+
+// ConfigHash is a short fingerprint of a schedule's configuration, used to tell
+// which version of a series a message was scheduled from
+func ConfigHash(config ScheduleConfig) string {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// OccurrenceMetadata adds the series name, occurrence index (0 when unknown) and
+// config hash to base, the schedule's own metadata (which may be nil). base is not
+// modified.
+func OccurrenceMetadata(base *MessageMetadata, series string, index int, configHash string) *MessageMetadata {
+	meta := &MessageMetadata{EventType: OccurrenceEventType, EventPayload: map[string]interface{}{}}
+	if base != nil {
+		meta.EventType = base.EventType
+		for k, v := range base.EventPayload {
+			meta.EventPayload[k] = v
+		}
+	}
+	meta.EventPayload[MetadataSeries] = series
+	meta.EventPayload[MetadataConfigHash] = configHash
+	if index > 0 {
+		meta.EventPayload[MetadataOccurrence] = index
+	}
+	return meta
+}
+
+// ParseOccurrenceMetadata reads back what OccurrenceMetadata added, reporting
+// false for messages that weren't scheduled with it. Numbers decoded from JSON
+// are accepted as float64.
+func ParseOccurrenceMetadata(meta *MessageMetadata) (series string, index int, configHash string, ok bool) {
+	if meta == nil {
+		return "", 0, "", false
+	}
+	series, ok = meta.EventPayload[MetadataSeries].(string)
+	if !ok {
+		return "", 0, "", false
+	}
+	configHash, _ = meta.EventPayload[MetadataConfigHash].(string)
+	switch n := meta.EventPayload[MetadataOccurrence].(type) {
+	case int:
+		index = n
+	case float64:
+		index = int(n)
+	}
+	return series, index, configHash, true
+}
+
+// Occurrence is a single concrete scheduled post of a message
+type Occurrence struct {
+	// Channel ID the message is (or will be) posted to
+	Channel string `json:"channel"`
+
+	// Message text
+	Message string `json:"message"`
+
+	// Time the message is posted
+	PostAt time.Time `json:"post_at"`
+
+	// Slack scheduled message ID (empty if not scheduled yet)
+	ScheduledID string `json:"scheduled_id,omitempty"`
+
+	// Deliberately cancelled; kept so the series isn't topped up with it again
+	Skipped bool `json:"skipped,omitempty"`
+
+	// One-off addition to a series, outside its recurrence
+	Extra bool `json:"extra,omitempty"`
+
+	// Later part of a message split for length, posted after the part before it
+	Continuation bool `json:"continuation,omitempty"`
+}
+
+// Series is a schedule created by the tool, as recorded in the local store
+type Series struct {
+	// Stable, unique name used to refer to the series
+	Name string `json:"name"`
+
+	// When the series was created
+	CreatedAt time.Time `json:"created_at"`
+
+	// Configuration the series was created from
+	Config ScheduleConfig `json:"config"`
+
+	// Occurrences scheduled in Slack, with their scheduled message IDs
+	Occurrences []Occurrence `json:"occurrences"`
+
+	// Occurrences of the recurrence dropped by cleanup once posted, which still
+	// count when numbering later ones
+	Pruned int `json:"pruned,omitempty"`
+
+	// Kept alive by the daemon, which schedules upcoming occurrences as they enter
+	// Slack's scheduling window
+	Managed bool `json:"managed,omitempty"`
+
+	// Upcoming occurrences have been removed from Slack but are kept here to resume
+	Paused bool `json:"paused,omitempty"`
+
+	// Schedule file the series was applied from (empty if created directly)
+	Source string `json:"source,omitempty"`
+}
+
+// Backup is an export of scheduled messages, used to back up and restore them or to
+// migrate them to another workspace
+type Backup struct {
+	// When the export was taken
+	ExportedAt time.Time `json:"exported_at"`
+
+	Messages []BackupMessage `json:"messages"`
+}
+
+// BackupMessage is one exported scheduled message
+type BackupMessage struct {
+	Occurrence
+
+	// Channel name, used to find the channel again when IDs differ (another workspace)
+	ChannelName string `json:"channel_name,omitempty"`
+
+	// Series the message belongs to in the local store (empty if untracked)
+	Group string `json:"group,omitempty"`
+}
+
+// DeletedBatch is a set of scheduled messages deleted together, kept so the delete
+// can be undone by scheduling them again
+type DeletedBatch struct {
+	DeletedAt time.Time    `json:"deleted_at"`
+	Messages  []Occurrence `json:"messages"`
+
+	// Options the messages were sent with, keyed by their scheduled ID, for
+	// messages that had any
+	Options map[string]SendOptions `json:"options,omitempty"`
+}
+
+// SendOptions are the Slack options a message is scheduled with besides its
+// channel, text and time. chat.scheduledMessages.list doesn't return them, so
+// they're kept alongside anything that may need to re-create the message.
+type SendOptions struct {
+	// Timestamp of the thread the message replies in
+	ThreadTS      string `json:"thread_ts,omitempty"`
+	AlsoToChannel bool   `json:"also_to_channel,omitempty"`
+
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+	Raw       bool   `json:"raw,omitempty"`
+
+	Metadata *MessageMetadata `json:"metadata,omitempty"`
+}
+
+// PendingOccurrence is an occurrence a failed Schedule call did not schedule
+type PendingOccurrence struct {
+	PostAt time.Time `json:"post_at"`
+
+	// 1-based position in the schedule and the schedule's length, for templates
+	Index int `json:"index"`
+	Total int `json:"total"`
+}
+
+// ResumeState records a Schedule call that failed partway, so the occurrences it
+// didn't get to can be retried without duplicating the ones it did
+type ResumeState struct {
+	// Series as created so far, with the occurrences that were scheduled
+	Series Series `json:"series"`
+
+	Pending []PendingOccurrence `json:"pending"`
+
+	// Why scheduling stopped
+	Error string `json:"error"`
+}
+
+// AuditEntry is one change made in Slack, as recorded in the audit log
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+
+	// Local user who ran the command
+	User string `json:"user"`
+
+	// Command that made the change (e.g. "schedule", "delete", "edit")
+	Command string `json:"command"`
+
+	// API action: "schedule", "delete" or "send"
+	Action string `json:"action"`
+
+	Channel string    `json:"channel"`
+	SlackID string    `json:"slack_id,omitempty"`
+	PostAt  time.Time `json:"post_at"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Snapshot is a cached copy of workspace state used for offline planning
+type Snapshot struct {
+	// When the snapshot was taken
+	TakenAt time.Time `json:"taken_at"`
+
+	// Channel ID to name map
+	Channels map[string]string `json:"channels"`
+
+	// Messages scheduled in Slack at the time of the snapshot
+	Scheduled []Occurrence `json:"scheduled"`
+}
+
+// ChannelID resolves a channel name (with or without #) or ID using the snapshot
+func (s *Snapshot) ChannelID(channel string) (string, error) {
+	if _, ok := s.Channels[channel]; ok {
+		return channel, nil
+	}
+	name := strings.TrimPrefix(channel, "#")
+	for id, n := range s.Channels {
+		if n == name {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("channel not found in snapshot: %s", channel)
+}
+
+// NotifyConfig says where to report scheduling outcomes, so automation failures
+// aren't silent
+type NotifyConfig struct {
+	// URLs to POST each event to as JSON. Slack incoming webhook URLs work as is.
+	Webhooks []string `json:"webhooks,omitempty"`
+
+	// User to DM about each event (@name, email or user ID)
+	DM string `json:"dm,omitempty"`
+
+	// Event types to report: scheduled, schedule_failed, extended, extend_failed
+	// (all if empty)
+	Events []string `json:"events,omitempty"`
+}
+
+// Settings are the user's defaults for every command, from the config file.
+// Flags always win over them.
+type Settings struct {
+	Defaults
+
+	// Profile used when none is selected on the command line
+	Profile string `json:"profile,omitempty"`
+
+	// Named sets of defaults, e.g. one per workspace, applied over the top-level ones
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+
+	// Overrides for messages to particular channels, keyed by name (with or
+	// without #) or ID, applied over the profile
+	Channels map[string]Defaults `json:"channels,omitempty"`
+}
+
+// Defaults are the settings a command falls back on when flags don't give them
+type Defaults struct {
+	// Channel to send to when none is given
+	Channel string `json:"channel,omitempty"`
+
+	// IANA time zone dates and times are read in, e.g. "America/New_York"
+	// (the system's if empty)
+	Timezone string `json:"timezone,omitempty"`
+
+	// Output format for listings: table, wide or json
+	Output string `json:"output,omitempty"`
+
+	// Daily window in which nothing is posted, as HH:MM-HH:MM
+	QuietHours string `json:"quiet_hours,omitempty"`
+}
+
+// Profile is a named set of defaults, with its own credentials if it's for
+// another workspace
+type Profile struct {
+	Defaults
+
+	// Credentials file holding this profile's token (the usual one if empty)
+	Credentials string `json:"credentials,omitempty"`
+}
+
+// Resolve returns the defaults in effect for a command using profile (the
+// file's own Profile if empty) and sending to channel (if known yet): the
+// top-level defaults, overridden by the profile's, then the channel's
+func (s *Settings) Resolve(profile, channel string) (Profile, error) {
+	resolved := Profile{Defaults: s.Defaults}
+	if profile == "" {
+		profile = s.Profile
+	}
+	if profile != "" {
+		p, ok := s.Profiles[profile]
+		if !ok {
+			return resolved, fmt.Errorf("unknown profile: %s", profile)
+		}
+		resolved.Defaults = resolved.Defaults.Merge(p.Defaults)
+		resolved.Credentials = p.Credentials
+	}
+
+	if channel == "" {
+		channel = resolved.Channel
+	}
+	if channel != "" {
+		if override, ok := s.Channels[strings.TrimPrefix(channel, "#")]; ok {
+			resolved.Defaults = resolved.Defaults.Merge(override)
+		} else if override, ok := s.Channels["#"+strings.TrimPrefix(channel, "#")]; ok {
+			resolved.Defaults = resolved.Defaults.Merge(override)
+		}
+	}
+	return resolved, nil
+}
+
+// Merge returns d with every field set in override replacing its own
+func (d Defaults) Merge(override Defaults) Defaults {
+	if override.Channel != "" {
+		d.Channel = override.Channel
+	}
+	if override.Timezone != "" {
+		d.Timezone = override.Timezone
+	}
+	if override.Output != "" {
+		d.Output = override.Output
+	}
+	if override.QuietHours != "" {
+		d.QuietHours = override.QuietHours
+	}
+	return d
+}
+
+// ApplyTo fills the fields of a schedule its flags left empty
+func (d Defaults) ApplyTo(config *ScheduleConfig) {
+	if config.Channel == "" {
+		config.Channel = d.Channel
+	}
+	if config.QuietHours == "" {
+		config.QuietHours = d.QuietHours
+	}
+}
+
+// Location returns the time zone named by Timezone, or nil to keep the system's
+func (d Defaults) Location() (*time.Location, error) {
+	if d.Timezone == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone: %s", d.Timezone)
+	}
+	return loc, nil
 }
 
 // Credentials holds Slack API credentials
@@ -1,7 +1,10 @@
 package types
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestInterval_IsValid(t *testing.T) {
@@ -11,6 +14,7 @@ func TestInterval_IsValid(t *testing.T) {
 		want     bool
 	}{
 		{"none is valid", IntervalNone, true},
+		{"hourly is valid", IntervalHourly, true},
 		{"daily is valid", IntervalDaily, true},
 		{"weekly is valid", IntervalWeekly, true},
 		{"monthly is valid", IntervalMonthly, true},
@@ -28,6 +32,28 @@ func TestInterval_IsValid(t *testing.T) {
 	}
 }
 
+func TestWeekendPolicy_IsValid(t *testing.T) {
+	tests := []struct {
+		policy WeekendPolicy
+		want   bool
+	}{
+		{WeekendKeep, true},
+		{WeekendSkip, true},
+		{WeekendPrevious, true},
+		{WeekendNext, true},
+		{WeekendPolicy("monday"), false},
+		{WeekendPolicy("SKIP"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			if got := tt.policy.IsValid(); got != tt.want {
+				t.Errorf("WeekendPolicy(%q).IsValid() = %v, want %v", tt.policy, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseDayOfWeek(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -119,3 +145,339 @@ func TestParseDaysOfWeek(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshot_ChannelID(t *testing.T) {
+	snap := &Snapshot{Channels: map[string]string{"C123": "general", "C456": "random"}}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"by name", "general", "C123", false},
+		{"by name with hash", "#random", "C456", false},
+		{"by ID", "C123", "C123", false},
+		{"unknown", "missing", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := snap.ChannelID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChannelID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ChannelID() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		wantNil   bool
+		wantErr   bool
+	}{
+		{"no metadata", "", "", true, false},
+		{"event only", "reminder", "", false, false},
+		{"event with payload", "reminder", `{"series":"standup"}`, false, false},
+		{"payload without event", "", `{"series":"standup"}`, true, true},
+		{"payload not an object", "reminder", `["standup"]`, true, true},
+		{"invalid json", "reminder", `{series}`, true, true},
+		{"whitespace in event type", "daily reminder", "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMetadata(tt.eventType, tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("ParseMetadata() = %+v, wantNil %v", got, tt.wantNil)
+			}
+			if got != nil && got.EventType != tt.eventType {
+				t.Errorf("EventType = %s, want %s", got.EventType, tt.eventType)
+			}
+		})
+	}
+
+	meta, _ := ParseMetadata("reminder", `{"series":"standup"}`)
+	if meta.EventPayload["series"] != "standup" {
+		t.Errorf("EventPayload[series] = %v, want standup", meta.EventPayload["series"])
+	}
+}
+
+func TestParseMonthDay(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"last", -1, false},
+		{"LAST", -1, false},
+		{"1", 1, false},
+		{"31", 31, false},
+		{"0", 0, true},
+		{"32", 0, true},
+		{"first", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMonthDay(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMonthDay(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMonthDay(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		input      string
+		start, end time.Duration
+		wantErr    bool
+	}{
+		{"22:00-07:00", 22 * time.Hour, 7 * time.Hour, false},
+		{" 12:30 - 13:15 ", 12*time.Hour + 30*time.Minute, 13*time.Hour + 15*time.Minute, false},
+		{"22:00", 0, 0, true},
+		{"10pm-7am", 0, 0, true},
+		{"09:00-09:00", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			start, end, err := ParseQuietHours(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuietHours(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if start != tt.start || end != tt.end {
+				t.Errorf("ParseQuietHours(%q) = %v, %v, want %v, %v", tt.input, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestSettings_Resolve(t *testing.T) {
+	settings := &Settings{
+		Defaults: Defaults{Channel: "general", Timezone: "UTC", Output: "table"},
+		Profile:  "work",
+		Profiles: map[string]Profile{
+			"work":     {Defaults: Defaults{Timezone: "America/New_York", QuietHours: "22:00-07:00"}},
+			"personal": {Defaults: Defaults{Channel: "family"}, Credentials: "/home/me/personal.json"},
+		},
+		Channels: map[string]Defaults{
+			"#alerts": {QuietHours: "00:00-06:00"},
+			"family":  {Timezone: "Europe/London"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		profile, channel string
+		want             Profile
+	}{
+		{"file profile", "", "", Profile{Defaults: Defaults{Channel: "general", Timezone: "America/New_York", Output: "table", QuietHours: "22:00-07:00"}}},
+		{"channel override", "", "alerts", Profile{Defaults: Defaults{Channel: "general", Timezone: "America/New_York", Output: "table", QuietHours: "00:00-06:00"}}},
+		{"profile's default channel", "personal", "", Profile{Defaults: Defaults{Channel: "family", Timezone: "Europe/London", Output: "table"}, Credentials: "/home/me/personal.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := settings.Resolve(tt.profile, tt.channel)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := settings.Resolve("missing", ""); err == nil {
+		t.Error("Resolve() expected an error for an unknown profile")
+	}
+
+	config := &ScheduleConfig{Channel: "random"}
+	Defaults{Channel: "general", QuietHours: "22:00-07:00"}.ApplyTo(config)
+	if config.Channel != "random" || config.QuietHours != "22:00-07:00" {
+		t.Errorf("ApplyTo() = %+v, want the flag's channel kept", config)
+	}
+
+	if loc, err := (Defaults{}).Location(); loc != nil || err != nil {
+		t.Errorf("Location() with no timezone = %v, %v, want nil", loc, err)
+	}
+	if _, err := (Defaults{Timezone: "Mars/Olympus"}).Location(); err == nil {
+		t.Error("Location() expected an error for an unknown timezone")
+	}
+}
+
+func TestParseDateList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"single", "2025-03-14", []string{"2025-03-14"}, false},
+		{"multiple with spaces", "2025-03-14, 2025-03-21", []string{"2025-03-14", "2025-03-21"}, false},
+		{"invalid format", "03/14/2025", nil, true},
+		{"invalid date", "2025-02-30", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateList(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDateList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseDateList() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseDateList()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseDayTimes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[DayOfWeek]string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"single", "mon=09:00", map[DayOfWeek]string{Monday: "09:00"}, false},
+		{"multiple", "mon=09:00, Friday=16:30", map[DayOfWeek]string{Monday: "09:00", Friday: "16:30"}, false},
+		{"missing equals", "mon 09:00", nil, true},
+		{"bad day", "funday=09:00", nil, true},
+		{"bad time", "mon=9am", nil, true},
+		{"duplicate day", "mon=09:00,monday=10:00", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDayTimes(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDayTimes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseDayTimes() = %v, want %v", got, tt.want)
+			}
+			for d, tm := range tt.want {
+				if got[d] != tm {
+					t.Errorf("ParseDayTimes()[%s] = %s, want %s", d, got[d], tm)
+				}
+			}
+		})
+	}
+}
+
+func TestDayOfWeek_IsValid(t *testing.T) {
+	tests := []struct {
+		day  DayOfWeek
+		want bool
+	}{
+		{Monday, true},
+		{Sunday, true},
+		{DayOfWeek("mon"), false},
+		{DayOfWeek(""), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.day.IsValid(); got != tt.want {
+			t.Errorf("DayOfWeek(%q).IsValid() = %v, want %v", tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestParseUserList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"names and IDs", "alice, @bob,U123", []string{"alice", "@bob", "U123"}, false},
+		{"empty entry", "alice,,bob", nil, true},
+		{"bare @", "alice,@", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUserList(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUserList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("ParseUserList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChannelList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"names, IDs and users", "general, #eng,C123,@alice", []string{"general", "#eng", "C123", "@alice"}, false},
+		{"duplicates dropped", "general,eng,general", []string{"general", "eng"}, false},
+		{"empty entry", "general,,eng", nil, true},
+		{"bare #", "general,#", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChannelList(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChannelList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("ParseChannelList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	a := ScheduleConfig{Message: "standup", Channel: "#general"}
+	b := a
+	if ConfigHash(a) != ConfigHash(b) || len(ConfigHash(a)) != 12 {
+		t.Errorf("ConfigHash() = %q and %q, want equal 12-character hashes", ConfigHash(a), ConfigHash(b))
+	}
+	b.SendTime = "09:30"
+	if ConfigHash(a) == ConfigHash(b) {
+		t.Error("ConfigHash() should change with the config")
+	}
+}
+
+func TestParseOccurrenceMetadata_FromJSON(t *testing.T) {
+	var meta MessageMetadata
+	if err := json.Unmarshal([]byte(`{"event_type":"x","event_payload":{"scheduler_series":"standup","scheduler_occurrence":4}}`), &meta); err != nil {
+		t.Fatal(err)
+	}
+	if series, index, _, ok := ParseOccurrenceMetadata(&meta); !ok || series != "standup" || index != 4 {
+		t.Errorf("ParseOccurrenceMetadata() = %q, %d, %v", series, index, ok)
+	}
+	if _, _, _, ok := ParseOccurrenceMetadata(&MessageMetadata{EventType: "x"}); ok {
+		t.Error("ParseOccurrenceMetadata() accepted metadata without a series")
+	}
+}